@@ -4,44 +4,234 @@ package config
 
 import (
 	"fmt"
+	"net/netip"
 	"os"
 	"strconv"
 	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"ticketd/internal/validator"
 )
 
 // Config holds all configuration values for TicketD.
 // Values are loaded from environment variables with sensible defaults where appropriate.
 type Config struct {
-	Port          string // Server port (default: 8080)
-	DBPath        string // SQLite database file path (default: ticketd.db)
-	AdminUser     string // Admin dashboard username (required unless DisableAuth is true)
-	AdminPass     string // Admin dashboard password (required unless DisableAuth is true)
-	PublicBaseURL string // Public base URL for embed scripts (optional, auto-detected if not set)
-	CustomCSSPath string // Path to custom CSS file for forms (optional)
-	DisableAuth   bool   // Disable built-in authentication (for use with external auth proxies like oauth2-proxy)
+	Port          string        // Server port (default: 8080)
+	DBPath        string        // SQLite database file path (default: ticketd.db)
+	DBBusyTimeout time.Duration // How long to wait for a locked database (default: 5s)
+	AdminUser     string        // Admin dashboard username (required unless DisableAuth is true)
+	AdminPass     string        // Admin dashboard password (required unless DisableAuth or AdminPassHash is set)
+	AdminPassHash string        // Bcrypt hash of the admin password (alternative to AdminPass)
+	PublicBaseURL string        // Public base URL for embed scripts (optional, auto-detected if not set)
+	CustomCSSPath string        // Path to custom CSS file for forms (optional)
+	DisableAuth   bool          // Disable built-in authentication (for use with external auth proxies like oauth2-proxy)
+	TLSCert       string        // Path to TLS certificate file (optional, enables HTTPS together with TLSKey)
+	TLSKey        string        // Path to TLS private key file (optional, enables HTTPS together with TLSCert)
+	Timezone      string        // IANA timezone for time-of-day statistics (default: UTC)
+
+	DBConnectAttempts int           // How often to try opening the database at startup (default: 5)
+	DBConnectDelay    time.Duration // Wait before the first retry, doubled after each failure (default: 1s)
+
+	// TrustedProxies are the networks of reverse proxies whose X-Forwarded-* and X-Real-IP
+	// headers are honored. Requests from other peers have those headers ignored (default: none).
+	TrustedProxies []netip.Prefix
+
+	SessionSecret string        // Key used to sign admin session cookies (optional, random per process if not set)
+	SessionTTL    time.Duration // Lifetime of an admin session (default: 12h)
+
+	ReadHeaderTimeout time.Duration // Max time to read request headers (default: 5s)
+	ReadTimeout       time.Duration // Max time to read the whole request, including the body (default: 15s)
+	WriteTimeout      time.Duration // Max time to write the response (default: 30s)
+	IdleTimeout       time.Duration // Max time to keep an idle keep-alive connection open (default: 60s)
+	ShutdownTimeout   time.Duration // How long to wait for in-flight requests on shutdown (default: 15s)
+
+	SignEmbeds    bool          // Require a signed, expiring token on embed script URLs (default: false)
+	EmbedTokenTTL time.Duration // Lifetime of a signed embed URL (default: 8760h, one year)
+	EmbedCacheTTL time.Duration // How long browsers may cache embed scripts before revalidating (default: 5m, 0 to always revalidate)
+
+	// EmbedClassPrefix starts the CSS class names of the embed widget and hosted pages, such as
+	// "ticketd-form", to avoid collisions with host page styles (default: DefaultEmbedClassPrefix).
+	// The default stylesheet and the CustomCSSPath file are rewritten to use it.
+	EmbedClassPrefix string
+
+	// DevAllowPrivateOrigins accepts submissions from any loopback or private-network origin,
+	// whatever the client's allowed domain. For local development only (default: false).
+	DevAllowPrivateOrigins bool
+
+	// CORSMaxAge is how long browsers may cache an allowed submission preflight, so they don't
+	// send one before every submission. 0 omits the header (default: 10m).
+	CORSMaxAge time.Duration
+
+	// RequireHTTPSOrigins rejects submissions from http origins, except localhost and
+	// loopback addresses such as 127.0.0.1 and ::1 for development (default: false).
+	RequireHTTPSOrigins bool
+
+	SpamBlocklistPath string // File of spam phrases, one per line (optional, re-read when it changes)
+	SpamAction        string // What to do with matching submissions: SpamActionReject (default) or SpamActionFlag
+
+	MaxBodyBytes  int64         // Largest accepted submission request body in bytes (default: 1 MiB)
+	MinSubmitTime time.Duration // Drop submissions sent sooner than this after the form was shown, 0 to skip the check (default: 0)
+	DedupWindow   time.Duration // Drop identical submissions (same form, email, and message) within this window, 0 to allow them (default: 0)
+
+	DisposableDomainsPath string // File of disposable email domains to reject, one per line (optional, read at startup)
+
+	PageSize int // Items per page in admin lists and the JSON API (default: 20, at most MaxPageSize)
+
+	PhoneRegion string // ISO 3166-1 alpha-2 region for normalizing national phone numbers (optional)
+
+	MaskIPs     bool // Show only the subnet of submitter IPs in the admin UI (default: false)
+	AnonymizeIP bool // Store only the network part of submitter IPs (default: false)
+
+	FormCreateLimit  int           // Forms one client may create per FormCreateWindow, 0 for no limit (default: 50)
+	FormCreateWindow time.Duration // Window for FormCreateLimit (default: 1h)
+
+	RetentionDays     int           // Delete submissions older than this many days, 0 to keep them forever (default: 0)
+	RetentionInterval time.Duration // How often the retention job runs (default: 1h)
+
+	SubmissionLimits validator.Limits // Length limits for submission fields (default: validator.DefaultLimits)
+
+	ReferencePrefix string // Start of submission reference numbers, letters and digits (default: TKD)
+	ReferenceDigits int    // Zero-padded width of the number in references (default: 6)
+
+	// loadErrors collects parse errors from Load so Validate can report them.
+	loadErrors []error
 }
 
+// Page size bounds for TICKETD_PAGE_SIZE and the per_page query parameter.
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 200
+)
+
+// Bounds for TICKETD_MAX_BODY_BYTES. The minimum stays well above a submission with
+// default-length fields, even when every character is escaped in JSON.
+const (
+	DefaultMaxBodyBytes = 1 << 20
+	MinMaxBodyBytes     = 64 << 10
+)
+
+// DefaultEmbedClassPrefix is the class name prefix used unless TICKETD_EMBED_CLASS_PREFIX is set,
+// and the one the default stylesheet is written with.
+const DefaultEmbedClassPrefix = "ticketd-"
+
+// Spam actions accepted by TICKETD_SPAM_ACTION.
+const (
+	SpamActionReject = "reject" // Refuse the submission with a generic 400
+	SpamActionFlag   = "flag"   // Store the submission with the SPAM status for review
+)
+
 // Load reads configuration from environment variables.
 //
 // Required environment variables (unless TICKETD_DISABLE_AUTH=true):
-//   - TICKETD_ADMIN_USER: Username for admin dashboard
-//   - TICKETD_ADMIN_PASS: Password for admin dashboard
+//   - TICKETD_ADMIN_USER: Username of the initial admin user (seeded on first run)
+//   - TICKETD_ADMIN_PASS or TICKETD_ADMIN_PASS_HASH: Password (plaintext or bcrypt hash) of the initial admin user
 //
 // Optional environment variables:
 //   - TICKETD_PORT: Server port (default: 8080)
 //   - TICKETD_DB_PATH: Database file path (default: ticketd.db)
+//   - TICKETD_DB_BUSY_TIMEOUT: How long to wait for a locked database as a Go duration (default: 5s)
+//   - TICKETD_DB_CONNECT_ATTEMPTS: How often to try opening the database at startup (default: 5)
+//   - TICKETD_DB_CONNECT_DELAY: Wait before the first retry as a Go duration, doubled after each failure (default: 1s)
 //   - TICKETD_PUBLIC_BASE_URL: Public URL for production deployments
 //   - TICKETD_CUSTOM_CSS: Path to custom CSS file for embedded forms
 //   - TICKETD_DISABLE_AUTH: Set to "true" to disable built-in authentication (use with external auth proxies)
+//   - TICKETD_TLS_CERT, TICKETD_TLS_KEY: Certificate and key files; when both are set TicketD serves HTTPS
+//   - TICKETD_TRUSTED_PROXIES: Comma-separated CIDRs or IPs of reverse proxies allowed to set forwarded headers
+//   - TICKETD_TIMEZONE: IANA timezone such as "Europe/Berlin" for statistics by weekday (default: UTC)
+//   - TICKETD_SESSION_SECRET: Key for signing admin session cookies (at least 32 characters)
+//   - TICKETD_SESSION_TTL: Admin session lifetime as a Go duration, e.g. "8h" (default: 12h)
+//   - TICKETD_READ_HEADER_TIMEOUT, TICKETD_READ_TIMEOUT, TICKETD_WRITE_TIMEOUT, TICKETD_IDLE_TIMEOUT:
+//     HTTP server timeouts as Go durations (defaults: 5s, 15s, 30s, 60s)
+//   - TICKETD_SHUTDOWN_TIMEOUT: How long to drain in-flight requests on SIGINT/SIGTERM (default: 15s)
+//   - TICKETD_SIGN_EMBEDS: Set to "true" to require signed embed script URLs (needs TICKETD_SESSION_SECRET)
+//   - TICKETD_EMBED_TOKEN_TTL: How long a signed embed URL stays valid (default: 8760h)
+//   - TICKETD_EMBED_CACHE_TTL: How long browsers may cache embed scripts, e.g. "1h" (default: 5m, 0 to always revalidate)
+//   - TICKETD_DEV_ALLOW_PRIVATE_ORIGINS: Set to "true" to accept submissions from loopback/LAN origins (development only)
+//   - TICKETD_REQUIRE_HTTPS_ORIGINS: Set to "true" to reject submissions from http origins other than localhost and loopback addresses
+//   - TICKETD_SPAM_BLOCKLIST: File of spam phrases, one per line, matched case-insensitively
+//   - TICKETD_SPAM_ACTION: "reject" (default) or "flag" submissions matching the blocklist
+//   - TICKETD_MAX_BODY_BYTES: Largest accepted submission request body in bytes (default: 1048576, min: 65536)
+//   - TICKETD_MIN_SUBMIT_TIME: Drop submissions sent sooner than this Go duration after the form was shown, e.g. "2s" (default: 0, off; needs TICKETD_SESSION_SECRET)
+//   - TICKETD_DEDUP_WINDOW: Drop identical submissions repeated within this Go duration, e.g. "1m" (default: 0, off)
+//   - TICKETD_DISPOSABLE_DOMAINS: File of disposable email domains, one per line, whose submissions are rejected
+//   - TICKETD_PAGE_SIZE: Items per page in admin lists and the JSON API (default: 20, max: 200)
+//   - TICKETD_PHONE_REGION: Region such as "US" or "DE" whose national phone numbers are normalized to E.164
+//   - TICKETD_MASK_IPS: Set to "true" to show only the subnet of submitter IPs in the admin UI
+//   - TICKETD_ANONYMIZE_IP: Set to "true" to zero the last IPv4 octet (last 80 IPv6 bits) of submitter IPs before storing them
+//   - TICKETD_FORM_CREATE_LIMIT: Forms one client may create per window, 0 to disable (default: 50)
+//   - TICKETD_FORM_CREATE_WINDOW: Window for TICKETD_FORM_CREATE_LIMIT as a Go duration (default: 1h)
+//   - TICKETD_RETENTION_DAYS: Delete submissions older than this many days, 0 to keep them forever (default: 0)
+//   - TICKETD_RETENTION_INTERVAL: How often old submissions are deleted as a Go duration (default: 1h)
+//   - TICKETD_MIN_NAME_LENGTH, TICKETD_MAX_NAME_LENGTH, TICKETD_MIN_SUBJECT_LENGTH, TICKETD_MAX_SUBJECT_LENGTH,
+//     TICKETD_MIN_MESSAGE_LENGTH, TICKETD_MAX_MESSAGE_LENGTH, TICKETD_MAX_PRIORITY_LENGTH:
+//     Length limits for submission fields in bytes (defaults: 1, 255, 1, 500, 1, 10000, 50)
+//   - TICKETD_REFERENCE_PREFIX: Letters or digits starting submission references such as TKD-2024-000123 (default: TKD)
+//   - TICKETD_REFERENCE_DIGITS: Zero-padded width of the number in submission references (default: 6)
 func Load() Config {
 	cfg := Config{
 		Port:          envOrDefault("TICKETD_PORT", "8080"),
 		DBPath:        envOrDefault("TICKETD_DB_PATH", "ticketd.db"),
 		AdminUser:     strings.TrimSpace(os.Getenv("TICKETD_ADMIN_USER")),
 		AdminPass:     os.Getenv("TICKETD_ADMIN_PASS"), // Don't trim password (whitespace might be intentional)
+		AdminPassHash: strings.TrimSpace(os.Getenv("TICKETD_ADMIN_PASS_HASH")),
 		PublicBaseURL: strings.TrimSpace(os.Getenv("TICKETD_PUBLIC_BASE_URL")),
 		CustomCSSPath: strings.TrimSpace(os.Getenv("TICKETD_CUSTOM_CSS")),
 		DisableAuth:   strings.ToLower(strings.TrimSpace(os.Getenv("TICKETD_DISABLE_AUTH"))) == "true",
+		TLSCert:       strings.TrimSpace(os.Getenv("TICKETD_TLS_CERT")),
+		TLSKey:        strings.TrimSpace(os.Getenv("TICKETD_TLS_KEY")),
+		Timezone:      envOrDefault("TICKETD_TIMEZONE", "UTC"),
+		SessionSecret: os.Getenv("TICKETD_SESSION_SECRET"), // Don't trim secrets
+		SignEmbeds:    strings.ToLower(strings.TrimSpace(os.Getenv("TICKETD_SIGN_EMBEDS"))) == "true",
+
+		DevAllowPrivateOrigins: strings.ToLower(strings.TrimSpace(os.Getenv("TICKETD_DEV_ALLOW_PRIVATE_ORIGINS"))) == "true",
+		RequireHTTPSOrigins:    strings.ToLower(strings.TrimSpace(os.Getenv("TICKETD_REQUIRE_HTTPS_ORIGINS"))) == "true",
+
+		SpamBlocklistPath: strings.TrimSpace(os.Getenv("TICKETD_SPAM_BLOCKLIST")),
+		SpamAction:        strings.ToLower(envOrDefault("TICKETD_SPAM_ACTION", SpamActionReject)),
+
+		DisposableDomainsPath: strings.TrimSpace(os.Getenv("TICKETD_DISPOSABLE_DOMAINS")),
+
+		PhoneRegion: strings.ToUpper(strings.TrimSpace(os.Getenv("TICKETD_PHONE_REGION"))),
+		MaskIPs:     strings.ToLower(strings.TrimSpace(os.Getenv("TICKETD_MASK_IPS"))) == "true",
+		AnonymizeIP: strings.ToLower(strings.TrimSpace(os.Getenv("TICKETD_ANONYMIZE_IP"))) == "true",
+
+		ReferencePrefix: strings.ToUpper(envOrDefault("TICKETD_REFERENCE_PREFIX", "TKD")),
+
+		EmbedClassPrefix: envOrDefault("TICKETD_EMBED_CLASS_PREFIX", DefaultEmbedClassPrefix),
+	}
+	cfg.TrustedProxies = cfg.envPrefixes("TICKETD_TRUSTED_PROXIES")
+	cfg.DBBusyTimeout = cfg.envDuration("TICKETD_DB_BUSY_TIMEOUT", 5*time.Second)
+	cfg.DBConnectAttempts = cfg.envInt("TICKETD_DB_CONNECT_ATTEMPTS", 5)
+	cfg.DBConnectDelay = cfg.envDuration("TICKETD_DB_CONNECT_DELAY", time.Second)
+	cfg.SessionTTL = cfg.envDuration("TICKETD_SESSION_TTL", 12*time.Hour)
+	cfg.EmbedTokenTTL = cfg.envDuration("TICKETD_EMBED_TOKEN_TTL", 365*24*time.Hour)
+	cfg.EmbedCacheTTL = cfg.envDuration("TICKETD_EMBED_CACHE_TTL", 5*time.Minute)
+	cfg.CORSMaxAge = cfg.envDuration("TICKETD_CORS_MAX_AGE", 10*time.Minute)
+	cfg.ReadHeaderTimeout = cfg.envDuration("TICKETD_READ_HEADER_TIMEOUT", 5*time.Second)
+	cfg.ReadTimeout = cfg.envDuration("TICKETD_READ_TIMEOUT", 15*time.Second)
+	cfg.WriteTimeout = cfg.envDuration("TICKETD_WRITE_TIMEOUT", 30*time.Second)
+	cfg.IdleTimeout = cfg.envDuration("TICKETD_IDLE_TIMEOUT", 60*time.Second)
+	cfg.ShutdownTimeout = cfg.envDuration("TICKETD_SHUTDOWN_TIMEOUT", 15*time.Second)
+	cfg.PageSize = cfg.envInt("TICKETD_PAGE_SIZE", DefaultPageSize)
+	cfg.FormCreateLimit = cfg.envInt("TICKETD_FORM_CREATE_LIMIT", 50)
+	cfg.FormCreateWindow = cfg.envDuration("TICKETD_FORM_CREATE_WINDOW", time.Hour)
+	cfg.RetentionDays = cfg.envInt("TICKETD_RETENTION_DAYS", 0)
+	cfg.RetentionInterval = cfg.envDuration("TICKETD_RETENTION_INTERVAL", time.Hour)
+	cfg.DedupWindow = cfg.envDuration("TICKETD_DEDUP_WINDOW", 0)
+	cfg.MinSubmitTime = cfg.envDuration("TICKETD_MIN_SUBMIT_TIME", 0)
+	cfg.MaxBodyBytes = int64(cfg.envInt("TICKETD_MAX_BODY_BYTES", DefaultMaxBodyBytes))
+	cfg.ReferenceDigits = cfg.envInt("TICKETD_REFERENCE_DIGITS", 6)
+	limits := validator.DefaultLimits()
+	cfg.SubmissionLimits = validator.Limits{
+		MinName:     cfg.envInt("TICKETD_MIN_NAME_LENGTH", limits.MinName),
+		MaxName:     cfg.envInt("TICKETD_MAX_NAME_LENGTH", limits.MaxName),
+		MinSubject:  cfg.envInt("TICKETD_MIN_SUBJECT_LENGTH", limits.MinSubject),
+		MaxSubject:  cfg.envInt("TICKETD_MAX_SUBJECT_LENGTH", limits.MaxSubject),
+		MinMessage:  cfg.envInt("TICKETD_MIN_MESSAGE_LENGTH", limits.MinMessage),
+		MaxMessage:  cfg.envInt("TICKETD_MAX_MESSAGE_LENGTH", limits.MaxMessage),
+		MaxPriority: cfg.envInt("TICKETD_MAX_PRIORITY_LENGTH", limits.MaxPriority),
 	}
 	return cfg
 }
@@ -49,13 +239,26 @@ func Load() Config {
 // Validate checks that all required configuration is present and valid.
 // Returns a descriptive error if any validation fails.
 func (c Config) Validate() error {
+	// Report values that could not be parsed during Load
+	if len(c.loadErrors) > 0 {
+		return c.loadErrors[0]
+	}
+
 	// Check required fields (unless auth is disabled)
 	if !c.DisableAuth {
 		if c.AdminUser == "" {
 			return fmt.Errorf("TICKETD_ADMIN_USER is required (or set TICKETD_DISABLE_AUTH=true to use external authentication)")
 		}
-		if c.AdminPass == "" {
-			return fmt.Errorf("TICKETD_ADMIN_PASS is required (or set TICKETD_DISABLE_AUTH=true to use external authentication)")
+		if c.AdminPass != "" && c.AdminPassHash != "" {
+			return fmt.Errorf("set only one of TICKETD_ADMIN_PASS and TICKETD_ADMIN_PASS_HASH")
+		}
+		if c.AdminPass == "" && c.AdminPassHash == "" {
+			return fmt.Errorf("TICKETD_ADMIN_PASS or TICKETD_ADMIN_PASS_HASH is required (or set TICKETD_DISABLE_AUTH=true to use external authentication)")
+		}
+		if c.AdminPassHash != "" {
+			if _, err := bcrypt.Cost([]byte(c.AdminPassHash)); err != nil {
+				return fmt.Errorf("invalid TICKETD_ADMIN_PASS_HASH: must be a bcrypt hash: %w", err)
+			}
 		}
 	}
 
@@ -72,6 +275,15 @@ func (c Config) Validate() error {
 	if c.DBPath == "" {
 		return fmt.Errorf("TICKETD_DB_PATH cannot be empty")
 	}
+	if c.DBBusyTimeout <= 0 {
+		return fmt.Errorf("invalid TICKETD_DB_BUSY_TIMEOUT %s: must be positive", c.DBBusyTimeout)
+	}
+	if c.DBConnectAttempts < 1 {
+		return fmt.Errorf("invalid TICKETD_DB_CONNECT_ATTEMPTS %d: must be at least 1", c.DBConnectAttempts)
+	}
+	if c.DBConnectDelay <= 0 {
+		return fmt.Errorf("invalid TICKETD_DB_CONNECT_DELAY %s: must be positive", c.DBConnectDelay)
+	}
 
 	// Validate custom CSS path exists if specified
 	if c.CustomCSSPath != "" {
@@ -80,9 +292,162 @@ func (c Config) Validate() error {
 		}
 	}
 
+	// Validate TLS files: both or neither must be set
+	if (c.TLSCert == "") != (c.TLSKey == "") {
+		return fmt.Errorf("TICKETD_TLS_CERT and TICKETD_TLS_KEY must be set together")
+	}
+	if c.TLSEnabled() {
+		if _, err := os.Stat(c.TLSCert); err != nil {
+			return fmt.Errorf("TICKETD_TLS_CERT file %q not found or not accessible: %w", c.TLSCert, err)
+		}
+		if _, err := os.Stat(c.TLSKey); err != nil {
+			return fmt.Errorf("TICKETD_TLS_KEY file %q not found or not accessible: %w", c.TLSKey, err)
+		}
+	}
+
+	// Validate timezone
+	if _, err := time.LoadLocation(c.Timezone); err != nil {
+		return fmt.Errorf("invalid TICKETD_TIMEZONE %q: must be an IANA timezone like \"Europe/Berlin\": %w", c.Timezone, err)
+	}
+
+	// Validate session settings
+	if c.SessionSecret != "" && len(c.SessionSecret) < 32 {
+		return fmt.Errorf("TICKETD_SESSION_SECRET must be at least 32 characters")
+	}
+	if c.SessionTTL <= 0 {
+		return fmt.Errorf("invalid TICKETD_SESSION_TTL %s: must be positive", c.SessionTTL)
+	}
+
+	// Validate server timeouts; a zero timeout would mean "no limit" to net/http
+	timeouts := []struct {
+		key   string
+		value time.Duration
+	}{
+		{"TICKETD_READ_HEADER_TIMEOUT", c.ReadHeaderTimeout},
+		{"TICKETD_READ_TIMEOUT", c.ReadTimeout},
+		{"TICKETD_WRITE_TIMEOUT", c.WriteTimeout},
+		{"TICKETD_IDLE_TIMEOUT", c.IdleTimeout},
+		{"TICKETD_SHUTDOWN_TIMEOUT", c.ShutdownTimeout},
+	}
+	for _, t := range timeouts {
+		if t.value <= 0 {
+			return fmt.Errorf("invalid %s %s: must be positive", t.key, t.value)
+		}
+	}
+	if c.ReadHeaderTimeout > c.ReadTimeout {
+		return fmt.Errorf("TICKETD_READ_HEADER_TIMEOUT (%s) cannot exceed TICKETD_READ_TIMEOUT (%s)", c.ReadHeaderTimeout, c.ReadTimeout)
+	}
+
+	// Signed embed URLs must stay valid across restarts, so they need a fixed key
+	if c.SignEmbeds && c.SessionSecret == "" {
+		return fmt.Errorf("TICKETD_SESSION_SECRET is required when TICKETD_SIGN_EMBEDS=true")
+	}
+	if c.EmbedTokenTTL <= 0 {
+		return fmt.Errorf("invalid TICKETD_EMBED_TOKEN_TTL %s: must be positive", c.EmbedTokenTTL)
+	}
+	if c.EmbedCacheTTL < 0 {
+		return fmt.Errorf("invalid TICKETD_EMBED_CACHE_TTL %s: must not be negative", c.EmbedCacheTTL)
+	}
+	if !validClassPrefix(c.EmbedClassPrefix) {
+		return fmt.Errorf("invalid TICKETD_EMBED_CLASS_PREFIX %q: must start with a letter and have at most 32 letters, digits, hyphens, or underscores", c.EmbedClassPrefix)
+	}
+	if c.CORSMaxAge < 0 {
+		return fmt.Errorf("invalid TICKETD_CORS_MAX_AGE %s: must not be negative", c.CORSMaxAge)
+	}
+
+	// Validate spam settings; the blocklist must exist at startup even though it is re-read later
+	if c.SpamBlocklistPath != "" {
+		if _, err := os.Stat(c.SpamBlocklistPath); err != nil {
+			return fmt.Errorf("TICKETD_SPAM_BLOCKLIST file %q not found or not accessible: %w", c.SpamBlocklistPath, err)
+		}
+	}
+	if c.SpamAction != SpamActionReject && c.SpamAction != SpamActionFlag {
+		return fmt.Errorf("invalid TICKETD_SPAM_ACTION %q: must be %q or %q", c.SpamAction, SpamActionReject, SpamActionFlag)
+	}
+	if c.DisposableDomainsPath != "" {
+		if _, err := os.Stat(c.DisposableDomainsPath); err != nil {
+			return fmt.Errorf("TICKETD_DISPOSABLE_DOMAINS file %q not found or not accessible: %w", c.DisposableDomainsPath, err)
+		}
+	}
+
+	// Validate phone region
+	if err := validator.ValidatePhoneRegion(c.PhoneRegion); err != nil {
+		return fmt.Errorf("invalid TICKETD_PHONE_REGION %q: must be a supported ISO 3166-1 alpha-2 code such as US or DE", c.PhoneRegion)
+	}
+
+	// Validate page size
+	if c.PageSize < 1 || c.PageSize > MaxPageSize {
+		return fmt.Errorf("invalid TICKETD_PAGE_SIZE %d: must be between 1 and %d", c.PageSize, MaxPageSize)
+	}
+
+	// Validate form creation limit
+	if c.FormCreateLimit < 0 {
+		return fmt.Errorf("invalid TICKETD_FORM_CREATE_LIMIT %d: must be 0 (no limit) or more", c.FormCreateLimit)
+	}
+	if c.FormCreateWindow <= 0 {
+		return fmt.Errorf("invalid TICKETD_FORM_CREATE_WINDOW %s: must be positive", c.FormCreateWindow)
+	}
+
+	// Validate retention settings
+	if c.RetentionDays < 0 {
+		return fmt.Errorf("invalid TICKETD_RETENTION_DAYS %d: must be 0 (keep forever) or more", c.RetentionDays)
+	}
+	if c.RetentionInterval <= 0 {
+		return fmt.Errorf("invalid TICKETD_RETENTION_INTERVAL %s: must be positive", c.RetentionInterval)
+	}
+
+	if c.DedupWindow < 0 {
+		return fmt.Errorf("invalid TICKETD_DEDUP_WINDOW %s: must be 0 (off) or positive", c.DedupWindow)
+	}
+
+	if c.MaxBodyBytes < MinMaxBodyBytes {
+		return fmt.Errorf("invalid TICKETD_MAX_BODY_BYTES %d: must be at least %d", c.MaxBodyBytes, MinMaxBodyBytes)
+	}
+	if c.MinSubmitTime < 0 {
+		return fmt.Errorf("invalid TICKETD_MIN_SUBMIT_TIME %s: must be 0 (off) or positive", c.MinSubmitTime)
+	}
+	if c.MinSubmitTime >= time.Hour {
+		return fmt.Errorf("invalid TICKETD_MIN_SUBMIT_TIME %s: must be less than 1h", c.MinSubmitTime)
+	}
+	// Start tokens are issued before and checked after a restart, so they need a fixed key too
+	if c.MinSubmitTime > 0 && c.SessionSecret == "" {
+		return fmt.Errorf("TICKETD_SESSION_SECRET is required when TICKETD_MIN_SUBMIT_TIME is set")
+	}
+
+	// Validate reference format
+	if !validReferencePrefix(c.ReferencePrefix) {
+		return fmt.Errorf("invalid TICKETD_REFERENCE_PREFIX %q: must be 1 to 10 letters or digits", c.ReferencePrefix)
+	}
+	if c.ReferenceDigits < 1 || c.ReferenceDigits > 12 {
+		return fmt.Errorf("invalid TICKETD_REFERENCE_DIGITS %d: must be between 1 and 12", c.ReferenceDigits)
+	}
+
+	// Validate submission length limits
+	limits := c.SubmissionLimits
+	for _, field := range []struct {
+		name     string
+		min, max int
+	}{
+		{"NAME", limits.MinName, limits.MaxName},
+		{"SUBJECT", limits.MinSubject, limits.MaxSubject},
+		{"MESSAGE", limits.MinMessage, limits.MaxMessage},
+	} {
+		if field.min < 1 || field.min >= field.max {
+			return fmt.Errorf("invalid TICKETD_MIN_%[1]s_LENGTH %[2]d and TICKETD_MAX_%[1]s_LENGTH %[3]d: need 1 <= min < max", field.name, field.min, field.max)
+		}
+	}
+	if limits.MaxPriority < 1 {
+		return fmt.Errorf("invalid TICKETD_MAX_PRIORITY_LENGTH %d: must be at least 1", limits.MaxPriority)
+	}
+
 	return nil
 }
 
+// TLSEnabled reports whether TicketD should serve HTTPS itself.
+func (c Config) TLSEnabled() bool {
+	return c.TLSCert != "" && c.TLSKey != ""
+}
+
 // String returns a string representation of the config with sensitive values redacted.
 // Useful for logging configuration at startup.
 func (c Config) String() string {
@@ -90,8 +455,8 @@ func (c Config) String() string {
 	if c.DisableAuth {
 		authStatus = "disabled (using external auth)"
 	}
-	return fmt.Sprintf("Config{Port: %s, DBPath: %s, Auth: %s, PublicBaseURL: %s, CustomCSSPath: %s}",
-		c.Port, c.DBPath, authStatus, c.PublicBaseURL, c.CustomCSSPath)
+	return fmt.Sprintf("Config{Port: %s, DBPath: %s, Auth: %s, TLS: %t, SessionTTL: %s, SignEmbeds: %t, PublicBaseURL: %s, CustomCSSPath: %s, SpamBlocklist: %s}",
+		c.Port, c.DBPath, authStatus, c.TLSEnabled(), c.SessionTTL, c.SignEmbeds, c.PublicBaseURL, c.CustomCSSPath, c.SpamBlocklistPath)
 }
 
 // envOrDefault returns the value of an environment variable or a fallback default.
@@ -101,3 +466,94 @@ func envOrDefault(key, fallback string) string {
 	}
 	return fallback
 }
+
+// envDuration parses an environment variable as a Go duration (e.g. "30s", "12h").
+// Returns the fallback if the variable is unset. Parse errors are recorded
+// on the config and reported by Validate.
+func (c *Config) envDuration(key string, fallback time.Duration) time.Duration {
+	value := strings.TrimSpace(os.Getenv(key))
+	if value == "" {
+		return fallback
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		c.loadErrors = append(c.loadErrors, fmt.Errorf("invalid %s %q: must be a duration like \"30s\" or \"12h\"", key, value))
+		return fallback
+	}
+	return parsed
+}
+
+// envPrefixes parses an environment variable as a comma-separated list of CIDRs such as
+// "10.0.0.0/8, 2001:db8::/32". A bare IP address stands for that single address.
+// Returns nil if the variable is unset. Parse errors are recorded on the config
+// and reported by Validate.
+func (c *Config) envPrefixes(key string) []netip.Prefix {
+	var prefixes []netip.Prefix
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		if !strings.Contains(value, "/") {
+			addr, err := netip.ParseAddr(value)
+			if err != nil {
+				c.loadErrors = append(c.loadErrors, fmt.Errorf("invalid %s entry %q: must be an IP address or CIDR like \"10.0.0.0/8\"", key, value))
+				continue
+			}
+			prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(value)
+		if err != nil {
+			c.loadErrors = append(c.loadErrors, fmt.Errorf("invalid %s entry %q: must be an IP address or CIDR like \"10.0.0.0/8\"", key, value))
+			continue
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes
+}
+
+// envInt parses an environment variable as a base-10 integer.
+// Returns the fallback if the variable is unset. Parse errors are recorded
+// on the config and reported by Validate.
+func (c *Config) envInt(key string, fallback int) int {
+	value := strings.TrimSpace(os.Getenv(key))
+	if value == "" {
+		return fallback
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		c.loadErrors = append(c.loadErrors, fmt.Errorf("invalid %s %q: must be a whole number", key, value))
+		return fallback
+	}
+	return parsed
+}
+
+// validClassPrefix reports whether prefix can start CSS class names without escaping:
+// an ASCII letter followed by up to 31 ASCII letters, digits, hyphens, or underscores.
+func validClassPrefix(prefix string) bool {
+	if prefix == "" || len(prefix) > 32 {
+		return false
+	}
+	for i, r := range prefix {
+		letter := r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z'
+		if !letter && (i == 0 || !(r >= '0' && r <= '9' || r == '-' || r == '_')) {
+			return false
+		}
+	}
+	return true
+}
+
+// validReferencePrefix reports whether prefix is 1 to 10 ASCII letters or digits,
+// so references stay easy to read out and type.
+func validReferencePrefix(prefix string) bool {
+	if prefix == "" || len(prefix) > 10 {
+		return false
+	}
+	for _, r := range prefix {
+		if !(r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z' || r >= '0' && r <= '9') {
+			return false
+		}
+	}
+	return true
+}
@@ -27,6 +27,14 @@ var (
 	// This typically maps to HTTP 403 status code.
 	ErrForbidden = errors.New("forbidden")
 
+	// ErrConflict indicates that the request conflicts with existing data (e.g. a duplicate).
+	// This typically maps to HTTP 409 status code.
+	ErrConflict = errors.New("conflict")
+
+	// ErrRateLimited indicates that too many similar requests were made in a short time.
+	// This typically maps to HTTP 429 status code.
+	ErrRateLimited = errors.New("rate limited")
+
 	// ErrInternal indicates an unexpected internal server error.
 	// This typically maps to HTTP 500 status code.
 	ErrInternal = errors.New("internal server error")
@@ -42,6 +50,16 @@ func InvalidInputError(field, reason string) error {
 	return fmt.Errorf("invalid %s: %s: %w", field, reason, ErrInvalidInput)
 }
 
+// ConflictError creates a new conflict error with a descriptive message.
+func ConflictError(resource, reason string) error {
+	return fmt.Errorf("%s %s: %w", resource, reason, ErrConflict)
+}
+
+// RateLimitedError creates a new rate limited error with a descriptive message.
+func RateLimitedError(resource, reason string) error {
+	return fmt.Errorf("%s %s: %w", resource, reason, ErrRateLimited)
+}
+
 // IsNotFound checks if an error is or wraps ErrNotFound.
 func IsNotFound(err error) bool {
 	return errors.Is(err, ErrNotFound)
@@ -62,6 +80,16 @@ func IsForbidden(err error) bool {
 	return errors.Is(err, ErrForbidden)
 }
 
+// IsConflict checks if an error is or wraps ErrConflict.
+func IsConflict(err error) bool {
+	return errors.Is(err, ErrConflict)
+}
+
+// IsRateLimited checks if an error is or wraps ErrRateLimited.
+func IsRateLimited(err error) bool {
+	return errors.Is(err, ErrRateLimited)
+}
+
 // IsInternal checks if an error is or wraps ErrInternal.
 func IsInternal(err error) bool {
 	return errors.Is(err, ErrInternal)
@@ -3,12 +3,19 @@
 package sqlite
 
 import (
+	"context"
+	"crypto/rand"
 	"database/sql"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"log/slog"
+	"net"
+	"strconv"
 	"strings"
 	"time"
 
-	_ "github.com/mattn/go-sqlite3"
+	"github.com/mattn/go-sqlite3"
 
 	apperrors "ticketd/internal/errors"
 	"ticketd/internal/store"
@@ -17,20 +24,126 @@ import (
 
 // Store implements the store.Store interface using SQLite.
 type Store struct {
-	db *sql.DB
+	db          *sql.DB
+	pageSize    int    // Limit used when a list method is called without one
+	phoneRegion string // Region national phone numbers are normalized for, empty to skip them
+	anonymizeIP bool   // Store only the network part of submitter IPs
+	limits      validator.Limits
+
+	formCreateLimit  int           // Forms a client may create per formCreateWindow, 0 for no limit
+	formCreateWindow time.Duration // Window formCreateLimit applies to
+
+	referencePrefix string // Start of new submission references, e.g. "TKD"
+	referenceDigits int    // Zero-padded width of the number in references
 }
 
 // New creates a new SQLite store at the specified path.
 // It opens the database connection and verifies connectivity.
-func New(path string) (*Store, error) {
-	db, err := sql.Open("sqlite3", path)
+//
+// Every connection uses WAL journaling, so readers don't block the writer, and waits up
+// to busyTimeout for locks held by other processes (backups, the sqlite3 shell) instead
+// of failing with "database is locked". A busyTimeout of 0 uses defaultBusyTimeout.
+// Foreign keys are switched on as well: SQLite ignores FOREIGN KEY clauses, including
+// ON DELETE CASCADE on notes and status history, unless each connection enables them.
+//
+// SQLite allows one writer at a time, so the pool holds a single connection. Store
+// methods must therefore never query while rows are open or use s.db inside a transaction.
+func New(path string, busyTimeout time.Duration) (*Store, error) {
+	if busyTimeout <= 0 {
+		busyTimeout = defaultBusyTimeout
+	}
+	db, err := sql.Open("sqlite3", dataSourceName(path, busyTimeout))
 	if err != nil {
 		return nil, apperrors.Wrap(err, "failed to open database")
 	}
+	db.SetMaxOpenConns(1)
 	if err := db.Ping(); err != nil {
+		db.Close()
 		return nil, apperrors.Wrap(err, "failed to connect to database")
 	}
-	return &Store{db: db}, nil
+	return &Store{
+		db:               db,
+		pageSize:         defaultPageSize,
+		limits:           validator.DefaultLimits(),
+		formCreateLimit:  defaultFormCreateLimit,
+		formCreateWindow: defaultFormCreateWindow,
+		referencePrefix:  defaultReferencePrefix,
+		referenceDigits:  defaultReferenceDigits,
+	}, nil
+}
+
+// defaultBusyTimeout is how long New waits for database locks when given no timeout.
+const defaultBusyTimeout = 5 * time.Second
+
+// dataSourceName adds the connection pragmas to path as go-sqlite3 DSN parameters.
+// The driver applies them to every connection it opens, unlike a one-off PRAGMA
+// statement, which only affects the connection that happened to run it.
+func dataSourceName(path string, busyTimeout time.Duration) string {
+	separator := "?"
+	if strings.Contains(path, "?") {
+		separator = "&"
+	}
+	return fmt.Sprintf("%s%s_journal_mode=WAL&_busy_timeout=%d&_foreign_keys=on", path, separator, busyTimeout.Milliseconds())
+}
+
+// SetPhoneRegion sets the ISO 3166-1 alpha-2 region used to normalize national phone numbers
+// of new submissions to E.164. International numbers are normalized regardless.
+func (s *Store) SetPhoneRegion(region string) {
+	s.phoneRegion = region
+}
+
+// SetAnonymizeIP makes CreateSubmission store only the network part of submitter IPs,
+// see validator.AnonymizeIP. Existing submissions are not changed.
+func (s *Store) SetAnonymizeIP(enabled bool) {
+	s.anonymizeIP = enabled
+}
+
+// SetSubmissionLimits sets the length limits CreateSubmission checks submission fields against.
+// Until it is called, validator.DefaultLimits apply.
+func (s *Store) SetSubmissionLimits(limits validator.Limits) {
+	s.limits = limits
+}
+
+// Default reference format, giving references such as TKD-2024-000123.
+const (
+	defaultReferencePrefix = "TKD"
+	defaultReferenceDigits = 6
+)
+
+// SetReferenceFormat sets the prefix and zero-padded width of the number in new submission
+// references. Existing references are not changed. An empty prefix or a width below 1 is ignored.
+func (s *Store) SetReferenceFormat(prefix string, digits int) {
+	if prefix != "" {
+		s.referencePrefix = strings.ToUpper(prefix)
+	}
+	if digits > 0 {
+		s.referenceDigits = digits
+	}
+}
+
+// formatReference builds the reference of the submission with the given ID, created in year.
+// The number is the submission ID, so references are unique without a separate counter.
+func (s *Store) formatReference(year int, id int64) string {
+	return fmt.Sprintf("%s-%d-%0*d", s.referencePrefix, year, s.referenceDigits, id)
+}
+
+// SetPageSize changes the limit used by list methods called with a zero or negative limit.
+// Sizes below 1 are ignored.
+func (s *Store) SetPageSize(size int) {
+	if size > 0 {
+		s.pageSize = size
+	}
+}
+
+// SetFormCreateLimit limits how many forms a single client may create within window.
+// A limit of 0 disables the check; non-positive windows are ignored.
+func (s *Store) SetFormCreateLimit(limit int, window time.Duration) {
+	if limit >= 0 {
+		s.formCreateLimit = limit
+	}
+	if window > 0 {
+		s.formCreateWindow = window
+	}
 }
 
 // Close closes the database connection.
@@ -41,6 +154,15 @@ func (s *Store) Close() error {
 	return nil
 }
 
+// Ping checks the database connection. With a single pooled connection this also
+// fails when the connection stays busy until ctx is done.
+func (s *Store) Ping(ctx context.Context) error {
+	if err := s.db.PingContext(ctx); err != nil {
+		return apperrors.Wrap(err, "failed to ping database")
+	}
+	return nil
+}
+
 // Migrate runs database migrations to create or update the schema.
 // It creates the necessary tables if they don't exist.
 func (s *Store) Migrate() error {
@@ -92,484 +214,2501 @@ CREATE TABLE IF NOT EXISTS submissions (
 		return apperrors.Wrap(err, "failed to add status column")
 	}
 
-	return nil
-}
-
-// CreateClient creates a new client after validating the input.
-func (s *Store) CreateClient(name, allowedDomain string) (store.Client, error) {
-	// Validate and trim input
-	name, allowedDomain, err := validator.TrimAndValidateClient(name, allowedDomain)
-	if err != nil {
-		return store.Client{}, err
+	_, err = s.db.Exec(`ALTER TABLE forms ADD COLUMN unique_email INTEGER NOT NULL DEFAULT 0`)
+	if err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return apperrors.Wrap(err, "failed to add unique_email column")
 	}
 
-	result, err := s.db.Exec(`INSERT INTO clients (name, allowed_domain) VALUES (?, ?)`, name, allowedDomain)
-	if err != nil {
-		return store.Client{}, apperrors.Wrap(err, "failed to create client")
+	_, err = s.db.Exec(`ALTER TABLE forms ADD COLUMN priority_field INTEGER NOT NULL DEFAULT 0`)
+	if err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return apperrors.Wrap(err, "failed to add priority_field column")
 	}
 
-	id, err := result.LastInsertId()
-	if err != nil {
-		return store.Client{}, apperrors.Wrap(err, "failed to get client ID")
+	_, err = s.db.Exec(`ALTER TABLE forms ADD COLUMN language TEXT NOT NULL DEFAULT 'en'`)
+	if err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return apperrors.Wrap(err, "failed to add language column")
 	}
 
-	return s.GetClient(id)
-}
-
-// ListClients returns a paginated list of clients ordered by creation date (newest first).
-func (s *Store) ListClients(offset, limit int) ([]store.Client, int, error) {
-	// Apply default pagination limits
-	limit = formatLimit(limit)
-	offset = formatOffset(offset)
-
-	var total int
-	if err := s.db.QueryRow(`SELECT COUNT(*) FROM clients`).Scan(&total); err != nil {
-		return nil, 0, apperrors.Wrap(err, "failed to count clients")
+	_, err = s.db.Exec(`ALTER TABLE forms ADD COLUMN success_url TEXT NOT NULL DEFAULT ''`)
+	if err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return apperrors.Wrap(err, "failed to add success_url column")
 	}
 
-	rows, err := s.db.Query(`SELECT id, name, allowed_domain, created_at FROM clients ORDER BY created_at DESC LIMIT ? OFFSET ?`, limit, offset)
-	if err != nil {
-		return nil, 0, apperrors.Wrap(err, "failed to list clients")
+	_, err = s.db.Exec(`ALTER TABLE forms ADD COLUMN require_consent INTEGER NOT NULL DEFAULT 0`)
+	if err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return apperrors.Wrap(err, "failed to add require_consent column")
 	}
-	defer rows.Close()
 
-	clients := []store.Client{}
-	for rows.Next() {
-		var client store.Client
-		var created string
-		if err := rows.Scan(&client.ID, &client.Name, &client.AllowedDomain, &created); err != nil {
-			return nil, 0, apperrors.Wrap(err, "failed to scan client row")
+	for _, column := range []string{"consent_label", "privacy_url"} {
+		_, err = s.db.Exec(`ALTER TABLE forms ADD COLUMN ` + column + ` TEXT NOT NULL DEFAULT ''`)
+		if err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+			return apperrors.Wrap(err, "failed to add "+column+" column")
 		}
-		client.CreatedAt = parseTime(created)
-		clients = append(clients, client)
 	}
 
-	if err := rows.Err(); err != nil {
-		return nil, 0, apperrors.Wrap(err, "error iterating client rows")
+	// Forms from before the active flag keep accepting submissions
+	_, err = s.db.Exec(`ALTER TABLE forms ADD COLUMN active INTEGER NOT NULL DEFAULT 1`)
+	if err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return apperrors.Wrap(err, "failed to add active column")
 	}
 
-	return clients, total, nil
-}
+	// Newline-separated list, see joinCategories
+	_, err = s.db.Exec(`ALTER TABLE forms ADD COLUMN categories TEXT NOT NULL DEFAULT ''`)
+	if err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return apperrors.Wrap(err, "failed to add categories column")
+	}
 
-// GetClient retrieves a client by ID.
-func (s *Store) GetClient(id int64) (store.Client, error) {
-	var client store.Client
-	var created string
-	row := s.db.QueryRow(`SELECT id, name, allowed_domain, created_at FROM clients WHERE id = ?`, id)
-	if err := row.Scan(&client.ID, &client.Name, &client.AllowedDomain, &created); err != nil {
-		if err == sql.ErrNoRows {
-			return store.Client{}, apperrors.NotFoundError("client", id)
+	for _, column := range []string{"theme_primary", "theme_radius", "theme_font"} {
+		_, err = s.db.Exec(`ALTER TABLE forms ADD COLUMN ` + column + ` TEXT NOT NULL DEFAULT ''`)
+		if err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+			return apperrors.Wrapf(err, "failed to add %s column", column)
 		}
-		return store.Client{}, apperrors.Wrapf(err, "failed to get client %d", id)
 	}
-	client.CreatedAt = parseTime(created)
-	return client, nil
-}
 
-// UpdateClient updates an existing client's name and allowed domain.
-func (s *Store) UpdateClient(id int64, name, allowedDomain string) error {
-	// Validate and trim input
-	name, allowedDomain, err := validator.TrimAndValidateClient(name, allowedDomain)
-	if err != nil {
-		return err
+	_, err = s.db.Exec(`ALTER TABLE clients ADD COLUMN custom_css TEXT NOT NULL DEFAULT ''`)
+	if err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return apperrors.Wrap(err, "failed to add custom_css column")
 	}
 
-	result, err := s.db.Exec(`UPDATE clients SET name = ?, allowed_domain = ? WHERE id = ?`, name, allowedDomain, id)
+	// Supports the duplicate lookup for forms that accept one submission per email
+	_, err = s.db.Exec(`CREATE INDEX IF NOT EXISTS idx_submissions_form_email ON submissions(form_id, LOWER(email))`)
 	if err != nil {
-		return apperrors.Wrapf(err, "failed to update client %d", id)
+		return apperrors.Wrap(err, "failed to create submissions email index")
 	}
 
-	// Check if any rows were affected
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return apperrors.Wrap(err, "failed to check rows affected")
+	_, err = s.db.Exec(`ALTER TABLE submissions ADD COLUMN email_valid INTEGER NOT NULL DEFAULT 1`)
+	if err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return apperrors.Wrap(err, "failed to add email_valid column")
 	}
-	if rowsAffected == 0 {
-		return apperrors.NotFoundError("client", id)
+	if err == nil {
+		// Column was just added: flag existing submissions with the same rules as new ones
+		if err := s.backfillEmailValid(); err != nil {
+			return err
+		}
 	}
 
-	return nil
-}
+	_, err = s.db.Exec(`ALTER TABLE submissions ADD COLUMN phone TEXT NOT NULL DEFAULT ''`)
+	if err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return apperrors.Wrap(err, "failed to add phone column")
+	}
 
-// DeleteClient permanently deletes a client and all associated forms and submissions.
-func (s *Store) DeleteClient(id int64) error {
-	// Check if client exists
-	if _, err := s.GetClient(id); err != nil {
-		return err
+	_, err = s.db.Exec(`ALTER TABLE submissions ADD COLUMN phone_e164 TEXT NOT NULL DEFAULT ''`)
+	if err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return apperrors.Wrap(err, "failed to add phone_e164 column")
 	}
 
-	// Delete all submissions for all forms of this client first
-	if _, err := s.db.Exec(`DELETE FROM submissions WHERE client_id = ?`, id); err != nil {
-		return apperrors.Wrapf(err, "failed to delete submissions for client %d", id)
+	_, err = s.db.Exec(`ALTER TABLE submissions ADD COLUMN category TEXT NOT NULL DEFAULT ''`)
+	if err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return apperrors.Wrap(err, "failed to add category column")
 	}
 
-	// Delete all forms for this client
-	if _, err := s.db.Exec(`DELETE FROM forms WHERE client_id = ?`, id); err != nil {
-		return apperrors.Wrapf(err, "failed to delete forms for client %d", id)
+	_, err = s.db.Exec(`ALTER TABLE submissions ADD COLUMN source TEXT NOT NULL DEFAULT ''`)
+	if err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return apperrors.Wrap(err, "failed to add source column")
 	}
 
-	// Delete the client
-	if _, err := s.db.Exec(`DELETE FROM clients WHERE id = ?`, id); err != nil {
-		return apperrors.Wrapf(err, "failed to delete client %d", id)
+	_, err = s.db.Exec(`ALTER TABLE submissions ADD COLUMN assignee TEXT NOT NULL DEFAULT ''`)
+	if err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return apperrors.Wrap(err, "failed to add assignee column")
 	}
 
-	return nil
-}
+	_, err = s.db.Exec(`ALTER TABLE submissions ADD COLUMN updated_by TEXT NOT NULL DEFAULT ''`)
+	if err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return apperrors.Wrap(err, "failed to add updated_by column")
+	}
 
-// CreateForm creates a new form after validating the input.
-func (s *Store) CreateForm(clientID int64, name string, formType store.FormType) (store.Form, error) {
-	// Validate input
-	name = strings.TrimSpace(name)
-	if err := validator.ValidateForm(name, formType); err != nil {
-		return store.Form{}, err
+	_, err = s.db.Exec(`CREATE INDEX IF NOT EXISTS idx_submissions_updated_by ON submissions(updated_by)`)
+	if err != nil {
+		return apperrors.Wrap(err, "failed to create submissions updated_by index")
 	}
 
-	// Verify client exists
-	if _, err := s.GetClient(clientID); err != nil {
-		return store.Form{}, apperrors.Wrapf(err, "client %d not found", clientID)
+	// Submissions from before status tokens keep an empty one and have no status page
+	_, err = s.db.Exec(`ALTER TABLE submissions ADD COLUMN status_token TEXT NOT NULL DEFAULT ''`)
+	if err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return apperrors.Wrap(err, "failed to add status_token column")
 	}
 
-	result, err := s.db.Exec(`INSERT INTO forms (client_id, name, type) VALUES (?, ?, ?)`, clientID, name, string(formType))
+	_, err = s.db.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_submissions_status_token ON submissions(status_token) WHERE status_token != ''`)
 	if err != nil {
-		return store.Form{}, apperrors.Wrap(err, "failed to create form")
+		return apperrors.Wrap(err, "failed to create submissions status_token index")
 	}
 
-	id, err := result.LastInsertId()
-	if err != nil {
-		return store.Form{}, apperrors.Wrap(err, "failed to get form ID")
+	_, err = s.db.Exec(`ALTER TABLE submissions ADD COLUMN consent TEXT NOT NULL DEFAULT ''`)
+	if err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return apperrors.Wrap(err, "failed to add consent column")
 	}
 
-	return s.GetForm(id)
-}
+	_, err = s.db.Exec(`ALTER TABLE submissions ADD COLUMN reference TEXT NOT NULL DEFAULT ''`)
+	if err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return apperrors.Wrap(err, "failed to add reference column")
+	}
 
-// ListForms returns all forms for a client ordered by creation date (newest first).
-func (s *Store) ListForms(clientID int64) ([]store.Form, error) {
-	rows, err := s.db.Query(`SELECT id, client_id, name, type, created_at FROM forms WHERE client_id = ? ORDER BY created_at DESC`, clientID)
+	// Submissions from before references get one in the current format, from their creation year
+	_, err = s.db.Exec(`UPDATE submissions SET reference = printf('%s-%s-%0*d', ?, strftime('%Y', created_at), ?, id) WHERE reference = ''`, s.referencePrefix, s.referenceDigits)
 	if err != nil {
-		return nil, apperrors.Wrapf(err, "failed to list forms for client %d", clientID)
+		return apperrors.Wrap(err, "failed to backfill submission references")
 	}
-	defer rows.Close()
 
-	forms := []store.Form{}
-	for rows.Next() {
-		var form store.Form
-		var created string
-		if err := rows.Scan(&form.ID, &form.ClientID, &form.Name, &form.Type, &created); err != nil {
-			return nil, apperrors.Wrap(err, "failed to scan form row")
-		}
-		form.CreatedAt = parseTime(created)
-		forms = append(forms, form)
+	_, err = s.db.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_submissions_reference ON submissions(reference)`)
+	if err != nil {
+		return apperrors.Wrap(err, "failed to create submissions reference index")
 	}
 
-	if err := rows.Err(); err != nil {
-		return nil, apperrors.Wrap(err, "error iterating form rows")
+	// Early versions of the admin UI stored "IN PROGRESS" with a space
+	_, err = s.db.Exec(`UPDATE submissions SET status = ? WHERE UPPER(TRIM(status)) = 'IN PROGRESS'`, validator.StatusInProgress)
+	if err != nil {
+		return apperrors.Wrap(err, "failed to normalize legacy IN PROGRESS statuses")
 	}
 
-	return forms, nil
-}
-
-// GetForm retrieves a form by ID.
-func (s *Store) GetForm(id int64) (store.Form, error) {
-	var form store.Form
-	var created string
-	row := s.db.QueryRow(`SELECT id, client_id, name, type, created_at FROM forms WHERE id = ?`, id)
-	if err := row.Scan(&form.ID, &form.ClientID, &form.Name, &form.Type, &created); err != nil {
-		if err == sql.ErrNoRows {
-			return store.Form{}, apperrors.NotFoundError("form", id)
-		}
-		return store.Form{}, apperrors.Wrapf(err, "failed to get form %d", id)
+	// Indexes for the admin list: newest-first ordering and the status, client, and form filters
+	_, err = s.db.Exec(`
+CREATE INDEX IF NOT EXISTS idx_submissions_created_at ON submissions(created_at);
+CREATE INDEX IF NOT EXISTS idx_submissions_client_id ON submissions(client_id);
+CREATE INDEX IF NOT EXISTS idx_submissions_form_id ON submissions(form_id);
+CREATE INDEX IF NOT EXISTS idx_submissions_status ON submissions(status);
+`)
+	if err != nil {
+		return apperrors.Wrap(err, "failed to create submissions indexes")
 	}
-	form.CreatedAt = parseTime(created)
-	return form, nil
-}
 
-// UpdateForm updates an existing form's name and type.
-func (s *Store) UpdateForm(id int64, name string, formType store.FormType) error {
-	// Validate input
-	name = strings.TrimSpace(name)
-	if err := validator.ValidateForm(name, formType); err != nil {
-		return err
+	// NULL deleted_at means the submission is not archived
+	_, err = s.db.Exec(`ALTER TABLE submissions ADD COLUMN deleted_at TIMESTAMP`)
+	if err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		return apperrors.Wrap(err, "failed to add deleted_at column")
 	}
 
-	result, err := s.db.Exec(`UPDATE forms SET name = ?, type = ? WHERE id = ?`, name, string(formType), id)
+	_, err = s.db.Exec(`
+CREATE TABLE IF NOT EXISTS admin_users (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	username TEXT NOT NULL UNIQUE,
+	password_hash TEXT NOT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+`)
 	if err != nil {
-		return apperrors.Wrapf(err, "failed to update form %d", id)
+		return apperrors.Wrap(err, "failed to create admin_users table")
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	_, err = s.db.Exec(`
+CREATE TABLE IF NOT EXISTS submission_notes (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	submission_id INTEGER NOT NULL,
+	author TEXT NOT NULL,
+	body TEXT NOT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY(submission_id) REFERENCES submissions(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_submission_notes_submission_id ON submission_notes(submission_id);
+`)
 	if err != nil {
-		return apperrors.Wrap(err, "failed to check rows affected")
-	}
-	if rowsAffected == 0 {
-		return apperrors.NotFoundError("form", id)
+		return apperrors.Wrap(err, "failed to create submission_notes table")
 	}
 
-	return nil
-}
+	// No foreign keys: entries must outlive the records they describe
+	_, err = s.db.Exec(`
+CREATE TABLE IF NOT EXISTS audit_log (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	actor TEXT NOT NULL,
+	action TEXT NOT NULL,
+	target_type TEXT NOT NULL,
+	target_id INTEGER NOT NULL DEFAULT 0,
+	detail TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
 
-// DeleteForm permanently deletes a form and all associated submissions.
-func (s *Store) DeleteForm(id int64) error {
-	// Check if form exists
-	if _, err := s.GetForm(id); err != nil {
-		return err
+CREATE INDEX IF NOT EXISTS idx_audit_log_created_at ON audit_log(created_at);
+`)
+	if err != nil {
+		return apperrors.Wrap(err, "failed to create audit_log table")
 	}
 
-	// Delete all submissions for this form first (foreign key constraint)
-	if _, err := s.db.Exec(`DELETE FROM submissions WHERE form_id = ?`, id); err != nil {
-		return apperrors.Wrapf(err, "failed to delete submissions for form %d", id)
+	_, err = s.db.Exec(`
+CREATE TABLE IF NOT EXISTS submission_status_history (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	submission_id INTEGER NOT NULL,
+	from_status TEXT NOT NULL,
+	to_status TEXT NOT NULL,
+	actor TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY(submission_id) REFERENCES submissions(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_submission_status_history_submission_id ON submission_status_history(submission_id);
+`)
+	if err != nil {
+		return apperrors.Wrap(err, "failed to create submission_status_history table")
 	}
 
-	// Delete the form
-	if _, err := s.db.Exec(`DELETE FROM forms WHERE id = ?`, id); err != nil {
-		return apperrors.Wrapf(err, "failed to delete form %d", id)
+	// Existing domains are normalized like new ones before they must be unique. If clients
+	// already share a domain, the index is skipped so startup still works; CreateClient and
+	// UpdateClient reject duplicates either way.
+	if err := s.normalizeClientDomains(); err != nil {
+		return err
+	}
+	_, err = s.db.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_clients_allowed_domain ON clients(allowed_domain)`)
+	if isUniqueViolation(err) {
+		slog.Warn("Several clients share an allowed domain; give each its own domain to enable the unique domain index")
+	} else if err != nil {
+		return apperrors.Wrap(err, "failed to create clients domain index")
 	}
 
 	return nil
 }
 
-// CreateSubmission creates a new submission after validating the input.
-func (s *Store) CreateSubmission(formID int64, input store.SubmissionInput) (store.Submission, error) {
-	// Trim and validate input
-	input = validator.TrimSubmissionInput(input)
-	if err := validator.ValidateSubmission(input); err != nil {
-		return store.Submission{}, err
-	}
-
-	// Verify form exists and get client ID
-	form, err := s.GetForm(formID)
+// normalizeClientDomains rewrites stored allowed domains with validator.NormalizeDomain.
+// Changes are collected first so no query runs while the rows are still open.
+func (s *Store) normalizeClientDomains() error {
+	rows, err := s.db.Query(`SELECT id, allowed_domain FROM clients`)
 	if err != nil {
-		return store.Submission{}, apperrors.Wrapf(err, "form %d not found", formID)
+		return apperrors.Wrap(err, "failed to read clients for domain normalization")
+	}
+	changed := map[int64]string{}
+	for rows.Next() {
+		var id int64
+		var domain string
+		if err := rows.Scan(&id, &domain); err != nil {
+			rows.Close()
+			return apperrors.Wrap(err, "failed to scan client for domain normalization")
+		}
+		if normalized := validator.NormalizeDomain(domain); normalized != domain {
+			changed[id] = normalized
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return apperrors.Wrap(err, "error iterating clients for domain normalization")
 	}
 
-	result, err := s.db.Exec(`
-INSERT INTO submissions (client_id, form_id, status, name, email, subject, message, priority, ip, user_agent)
-VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-`, form.ClientID, form.ID, validator.StatusOpen, input.Name, input.Email, input.Subject, input.Message, input.Priority, input.IP, input.UserAgent)
-	if err != nil {
-		return store.Submission{}, apperrors.Wrap(err, "failed to create submission")
+	for id, domain := range changed {
+		if _, err := s.db.Exec(`UPDATE clients SET allowed_domain = ? WHERE id = ?`, domain, id); err != nil {
+			if isUniqueViolation(err) {
+				// Another client already has the normalized domain; leave this one for an admin to fix
+				continue
+			}
+			return apperrors.Wrapf(err, "failed to normalize domain of client %d", id)
+		}
 	}
+	return nil
+}
 
-	id, err := result.LastInsertId()
+// backfillEmailValid sets email_valid on existing submissions using strict validation.
+// Invalid IDs are collected first so no query runs while the rows are still open.
+func (s *Store) backfillEmailValid() error {
+	rows, err := s.db.Query(`SELECT id, COALESCE(email, '') FROM submissions`)
 	if err != nil {
-		return store.Submission{}, apperrors.Wrap(err, "failed to get submission ID")
+		return apperrors.Wrap(err, "failed to read submissions for email backfill")
+	}
+	var invalid []int64
+	for rows.Next() {
+		var id int64
+		var email string
+		if err := rows.Scan(&id, &email); err != nil {
+			rows.Close()
+			return apperrors.Wrap(err, "failed to scan submission for email backfill")
+		}
+		if validator.ValidateEmailStrict(email) != nil {
+			invalid = append(invalid, id)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return apperrors.Wrap(err, "error iterating submissions for email backfill")
 	}
 
-	return s.GetSubmission(id)
+	for _, id := range invalid {
+		if _, err := s.db.Exec(`UPDATE submissions SET email_valid = 0 WHERE id = ?`, id); err != nil {
+			return apperrors.Wrapf(err, "failed to flag submission %d email", id)
+		}
+	}
+	return nil
 }
 
-// ListSubmissions returns a paginated list of submissions with denormalized client and form data.
-func (s *Store) ListSubmissions(offset, limit int) ([]store.Submission, int, error) {
+// CreateClient creates a new client after validating the input.
+func (s *Store) CreateClient(name, allowedDomain string) (store.Client, error) {
+	// Validate and trim input
+	name, allowedDomain, err := validator.TrimAndValidateClient(name, allowedDomain)
+	if err != nil {
+		return store.Client{}, err
+	}
+
+	if err := s.checkDomainAvailable(allowedDomain, 0); err != nil {
+		return store.Client{}, err
+	}
+
+	result, err := s.db.Exec(`INSERT INTO clients (name, allowed_domain) VALUES (?, ?)`, name, allowedDomain)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return store.Client{}, domainTakenError(allowedDomain)
+		}
+		return store.Client{}, apperrors.Wrap(err, "failed to create client")
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return store.Client{}, apperrors.Wrap(err, "failed to get client ID")
+	}
+
+	return s.GetClient(id)
+}
+
+// checkDomainAvailable returns an invalid input error if a client other than id already
+// has the allowed domain. Pass id 0 for a new client.
+func (s *Store) checkDomainAvailable(domain string, id int64) error {
+	var other int64
+	err := s.db.QueryRow(`SELECT id FROM clients WHERE allowed_domain = ? AND id != ? LIMIT 1`, domain, id).Scan(&other)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return apperrors.Wrap(err, "failed to check client domain")
+	}
+	return domainTakenError(domain)
+}
+
+// domainTakenError is returned when a client's allowed domain is already used by another client,
+// which would make it ambiguous which client an origin belongs to.
+func domainTakenError(domain string) error {
+	return apperrors.InvalidInputError("domain", fmt.Sprintf("%s is already used by another client", domain))
+}
+
+// ListClients returns a paginated list of clients ordered by creation date (newest first).
+func (s *Store) ListClients(offset, limit int) ([]store.Client, int, error) {
 	// Apply default pagination limits
-	limit = formatLimit(limit)
+	limit = s.formatLimit(limit)
+	offset = formatOffset(offset)
+
+	var total int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM clients`).Scan(&total); err != nil {
+		return nil, 0, apperrors.Wrap(err, "failed to count clients")
+	}
+
+	rows, err := s.db.Query(`SELECT id, name, allowed_domain, created_at FROM clients ORDER BY created_at DESC LIMIT ? OFFSET ?`, limit, offset)
+	if err != nil {
+		return nil, 0, apperrors.Wrap(err, "failed to list clients")
+	}
+	defer rows.Close()
+
+	clients := []store.Client{}
+	for rows.Next() {
+		var client store.Client
+		var created string
+		if err := rows.Scan(&client.ID, &client.Name, &client.AllowedDomain, &created); err != nil {
+			return nil, 0, apperrors.Wrap(err, "failed to scan client row")
+		}
+		client.CreatedAt = parseTime(created)
+		clients = append(clients, client)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, apperrors.Wrap(err, "error iterating client rows")
+	}
+
+	return clients, total, nil
+}
+
+// ListClientsWithCounts returns a paginated list of clients with their submission counts.
+// Counts come from a single grouped LEFT JOIN so clients without submissions report zero.
+func (s *Store) ListClientsWithCounts(offset, limit int) ([]store.ClientWithCount, int, error) {
+	limit = s.formatLimit(limit)
 	offset = formatOffset(offset)
 
-	var total int
-	if err := s.db.QueryRow(`SELECT COUNT(*) FROM submissions`).Scan(&total); err != nil {
-		return nil, 0, apperrors.Wrap(err, "failed to count submissions")
+	var total int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM clients`).Scan(&total); err != nil {
+		return nil, 0, apperrors.Wrap(err, "failed to count clients")
+	}
+
+	rows, err := s.db.Query(`
+SELECT c.id, c.name, c.allowed_domain, c.created_at, COUNT(s.id)
+FROM clients c
+LEFT JOIN submissions s ON s.client_id = c.id
+GROUP BY c.id
+ORDER BY c.created_at DESC
+LIMIT ? OFFSET ?
+`, limit, offset)
+	if err != nil {
+		return nil, 0, apperrors.Wrap(err, "failed to list clients with counts")
+	}
+	defer rows.Close()
+
+	clients := []store.ClientWithCount{}
+	for rows.Next() {
+		var client store.ClientWithCount
+		var created string
+		if err := rows.Scan(&client.ID, &client.Name, &client.AllowedDomain, &created, &client.SubmissionCount); err != nil {
+			return nil, 0, apperrors.Wrap(err, "failed to scan client row")
+		}
+		client.CreatedAt = parseTime(created)
+		clients = append(clients, client)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, apperrors.Wrap(err, "error iterating client rows")
+	}
+
+	return clients, total, nil
+}
+
+// maxSparklineDays is the longest range ClientSubmissionSparkline covers.
+const maxSparklineDays = 366
+
+// ClientSubmissionSparkline returns a client's daily submission counts, oldest day first.
+// One grouped query fetches the days that have submissions; the rest are zero-filled.
+func (s *Store) ClientSubmissionSparkline(clientID int64, days int) ([]int, error) {
+	if days < 1 || days > maxSparklineDays {
+		return nil, apperrors.InvalidInputError("days", fmt.Sprintf("must be between 1 and %d", maxSparklineDays))
+	}
+
+	now := time.Now().UTC()
+	first := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, -(days - 1))
+
+	rows, err := s.db.Query(`
+SELECT DATE(created_at) AS day, COUNT(*)
+FROM submissions
+WHERE client_id = ? AND created_at >= ?
+GROUP BY day
+`, clientID, formatTimeParam(first))
+	if err != nil {
+		return nil, apperrors.Wrapf(err, "failed to count daily submissions for client %d", clientID)
+	}
+	defer rows.Close()
+
+	counts := make([]int, days)
+	for rows.Next() {
+		var day string
+		var count int
+		if err := rows.Scan(&day, &count); err != nil {
+			return nil, apperrors.Wrap(err, "failed to scan daily submission count")
+		}
+		index := int(parseTime(day).Sub(first).Hours() / 24)
+		if index >= 0 && index < days {
+			counts[index] = count
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, apperrors.Wrap(err, "error iterating daily submission counts")
+	}
+
+	return counts, nil
+}
+
+// OldestOpenSubmissionAgeByClient returns the age of each client's oldest open submission.
+func (s *Store) OldestOpenSubmissionAgeByClient() (map[int64]time.Duration, error) {
+	rows, err := s.db.Query(`
+SELECT client_id, MIN(created_at)
+FROM submissions
+WHERE status IN ('OPEN', '') AND deleted_at IS NULL
+GROUP BY client_id
+`)
+	if err != nil {
+		return nil, apperrors.Wrap(err, "failed to find oldest open submissions")
+	}
+	defer rows.Close()
+
+	now := time.Now()
+	ages := map[int64]time.Duration{}
+	for rows.Next() {
+		var clientID int64
+		var oldest string
+		if err := rows.Scan(&clientID, &oldest); err != nil {
+			return nil, apperrors.Wrap(err, "failed to scan oldest open submission")
+		}
+		ages[clientID] = max(now.Sub(parseTime(oldest)), 0)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, apperrors.Wrap(err, "error iterating oldest open submissions")
+	}
+
+	return ages, nil
+}
+
+// GetClient retrieves a client by ID.
+func (s *Store) GetClient(id int64) (store.Client, error) {
+	var client store.Client
+	var created string
+	row := s.db.QueryRow(`SELECT id, name, allowed_domain, custom_css, created_at FROM clients WHERE id = ?`, id)
+	if err := row.Scan(&client.ID, &client.Name, &client.AllowedDomain, &client.CustomCSS, &created); err != nil {
+		if err == sql.ErrNoRows {
+			return store.Client{}, apperrors.NotFoundError("client", id)
+		}
+		return store.Client{}, apperrors.Wrapf(err, "failed to get client %d", id)
+	}
+	client.CreatedAt = parseTime(created)
+	return client, nil
+}
+
+// GetClientByDomain finds the client whose allowed domain accepts the host domain.
+// Instead of scanning all clients it looks up the host and each of its parent domains
+// in the allowed domain index, preferring the longest match.
+func (s *Store) GetClientByDomain(domain string) (store.Client, error) {
+	host := validator.NormalizeDomain(domain)
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if host == "" {
+		return store.Client{}, fmt.Errorf("client for domain %q: %w", domain, apperrors.ErrNotFound)
+	}
+
+	var row *sql.Row
+	if host == "localhost" || host == "127.0.0.1" {
+		// Both loopback names match each other and stored domains with any port
+		row = s.db.QueryRow(`
+SELECT id, name, allowed_domain, custom_css, created_at FROM clients
+WHERE allowed_domain IN ('localhost', '127.0.0.1') OR allowed_domain LIKE 'localhost:%' OR allowed_domain LIKE '127.0.0.1:%'
+ORDER BY allowed_domain = ? DESC, id
+LIMIT 1`, host)
+	} else {
+		candidates := []any{host}
+		for rest := host; strings.Contains(rest, "."); {
+			rest = rest[strings.Index(rest, ".")+1:]
+			candidates = append(candidates, rest)
+		}
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(candidates)), ", ")
+		row = s.db.QueryRow(`
+SELECT id, name, allowed_domain, custom_css, created_at FROM clients
+WHERE allowed_domain IN (`+placeholders+`)
+ORDER BY LENGTH(allowed_domain) DESC
+LIMIT 1`, candidates...)
+	}
+
+	var client store.Client
+	var created string
+	if err := row.Scan(&client.ID, &client.Name, &client.AllowedDomain, &client.CustomCSS, &created); err != nil {
+		if err == sql.ErrNoRows {
+			return store.Client{}, fmt.Errorf("client for domain %q: %w", domain, apperrors.ErrNotFound)
+		}
+		return store.Client{}, apperrors.Wrapf(err, "failed to get client for domain %s", domain)
+	}
+	client.CreatedAt = parseTime(created)
+	return client, nil
+}
+
+// UpdateClient updates an existing client's name, allowed domain, and custom CSS.
+func (s *Store) UpdateClient(id int64, name, allowedDomain, customCSS string) error {
+	// Validate and trim input
+	name, allowedDomain, err := validator.TrimAndValidateClient(name, allowedDomain)
+	if err != nil {
+		return err
+	}
+	customCSS = strings.TrimSpace(customCSS)
+	if err := validator.ValidateCustomCSS(customCSS); err != nil {
+		return err
+	}
+
+	if err := s.checkDomainAvailable(allowedDomain, id); err != nil {
+		return err
+	}
+
+	result, err := s.db.Exec(`UPDATE clients SET name = ?, allowed_domain = ?, custom_css = ? WHERE id = ?`, name, allowedDomain, customCSS, id)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return domainTakenError(allowedDomain)
+		}
+		return apperrors.Wrapf(err, "failed to update client %d", id)
+	}
+
+	// Check if any rows were affected
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return apperrors.Wrap(err, "failed to check rows affected")
+	}
+	if rowsAffected == 0 {
+		return apperrors.NotFoundError("client", id)
+	}
+
+	return nil
+}
+
+// DeleteClient permanently deletes a client and all associated forms and submissions.
+func (s *Store) DeleteClient(id int64) error {
+	// Check if client exists
+	if _, err := s.GetClient(id); err != nil {
+		return err
+	}
+
+	// Delete notes, status history, and submissions for all forms of this client first
+	if _, err := s.db.Exec(`DELETE FROM submission_notes WHERE submission_id IN (SELECT id FROM submissions WHERE client_id = ?)`, id); err != nil {
+		return apperrors.Wrapf(err, "failed to delete submission notes for client %d", id)
+	}
+	if _, err := s.db.Exec(`DELETE FROM submission_status_history WHERE submission_id IN (SELECT id FROM submissions WHERE client_id = ?)`, id); err != nil {
+		return apperrors.Wrapf(err, "failed to delete submission status history for client %d", id)
+	}
+	if _, err := s.db.Exec(`DELETE FROM submissions WHERE client_id = ?`, id); err != nil {
+		return apperrors.Wrapf(err, "failed to delete submissions for client %d", id)
+	}
+
+	// Delete all forms for this client
+	if _, err := s.db.Exec(`DELETE FROM forms WHERE client_id = ?`, id); err != nil {
+		return apperrors.Wrapf(err, "failed to delete forms for client %d", id)
+	}
+
+	// Delete the client
+	if _, err := s.db.Exec(`DELETE FROM clients WHERE id = ?`, id); err != nil {
+		return apperrors.Wrapf(err, "failed to delete client %d", id)
+	}
+
+	return nil
+}
+
+// CreateForm creates a new form after validating the input.
+func (s *Store) CreateForm(clientID int64, input store.FormInput) (store.Form, error) {
+	// Validate input
+	input, err := normalizeFormInput(input)
+	if err != nil {
+		return store.Form{}, err
+	}
+
+	// Verify client exists
+	if _, err := s.GetClient(clientID); err != nil {
+		return store.Form{}, apperrors.Wrapf(err, "client %d not found", clientID)
+	}
+
+	// Reject scripted mass creation on shared instances
+	if s.formCreateLimit > 0 {
+		recent, err := s.CountFormsCreatedSince(clientID, time.Now().Add(-s.formCreateWindow))
+		if err != nil {
+			return store.Form{}, err
+		}
+		if recent >= s.formCreateLimit {
+			return store.Form{}, apperrors.RateLimitedError("form", fmt.Sprintf("creation limit of %d per %s reached for client %d", s.formCreateLimit, s.formCreateWindow, clientID))
+		}
+	}
+
+	result, err := s.db.Exec(`INSERT INTO forms (client_id, name, type, unique_email, priority_field, language, success_url, theme_primary, theme_radius, theme_font, categories, require_consent, consent_label, privacy_url) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`, clientID, input.Name, string(input.Type), input.UniqueEmail, input.PriorityField, input.Language, input.SuccessURL, input.Theme.PrimaryColor, input.Theme.BorderRadius, input.Theme.FontFamily, joinCategories(input.Categories), input.RequireConsent, input.ConsentLabel, input.PrivacyURL)
+	if err != nil {
+		return store.Form{}, apperrors.Wrap(err, "failed to create form")
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return store.Form{}, apperrors.Wrap(err, "failed to get form ID")
+	}
+
+	return s.GetForm(id)
+}
+
+// CloneForm creates a copy of a form through CreateForm, so the copy is validated and
+// counts towards the client's form creation limit like any new form. The copy keeps
+// every setting, including the active flag, and is named after the original plus " (copy)".
+func (s *Store) CloneForm(formID int64) (store.Form, error) {
+	form, err := s.GetForm(formID)
+	if err != nil {
+		return store.Form{}, err
+	}
+	clone, err := s.CreateForm(form.ClientID, store.FormInput{
+		Name:           validator.SuffixName(form.Name, " (copy)"),
+		Type:           form.Type,
+		UniqueEmail:    form.UniqueEmail,
+		PriorityField:  form.PriorityField,
+		Language:       form.Language,
+		SuccessURL:     form.SuccessURL,
+		Theme:          form.Theme,
+		Categories:     form.Categories,
+		RequireConsent: form.RequireConsent,
+		ConsentLabel:   form.ConsentLabel,
+		PrivacyURL:     form.PrivacyURL,
+	})
+	if err != nil {
+		return store.Form{}, err
+	}
+
+	// New forms start active, so a copy of a disabled form is disabled separately
+	if !form.Active {
+		if err := s.SetFormActive(clone.ID, false); err != nil {
+			return store.Form{}, err
+		}
+		clone.Active = false
+	}
+	return clone, nil
+}
+
+// CountFormsCreatedSince returns the number of a client's forms created at or after since.
+func (s *Store) CountFormsCreatedSince(clientID int64, since time.Time) (int, error) {
+	var count int
+	err := s.db.QueryRow(`SELECT COUNT(*) FROM forms WHERE client_id = ? AND created_at >= ?`, clientID, formatTimeParam(since)).Scan(&count)
+	if err != nil {
+		return 0, apperrors.Wrapf(err, "failed to count recent forms for client %d", clientID)
+	}
+	return count, nil
+}
+
+// ListForms returns all forms for a client ordered by creation date (newest first).
+func (s *Store) ListForms(clientID int64) ([]store.Form, error) {
+	rows, err := s.db.Query(`SELECT `+formColumns+` FROM forms WHERE client_id = ? ORDER BY created_at DESC`, clientID)
+	if err != nil {
+		return nil, apperrors.Wrapf(err, "failed to list forms for client %d", clientID)
+	}
+	defer rows.Close()
+
+	forms := []store.Form{}
+	for rows.Next() {
+		form, err := scanForm(rows)
+		if err != nil {
+			return nil, apperrors.Wrap(err, "failed to scan form row")
+		}
+		forms = append(forms, form)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, apperrors.Wrap(err, "error iterating form rows")
+	}
+
+	return forms, nil
+}
+
+// ListFormsPaginated returns a page of a client's forms ordered by creation date (newest first).
+func (s *Store) ListFormsPaginated(clientID int64, offset, limit int) ([]store.Form, int, error) {
+	limit = s.formatLimit(limit)
+	offset = formatOffset(offset)
+
+	var total int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM forms WHERE client_id = ?`, clientID).Scan(&total); err != nil {
+		return nil, 0, apperrors.Wrapf(err, "failed to count forms for client %d", clientID)
+	}
+
+	rows, err := s.db.Query(`SELECT `+formColumns+` FROM forms WHERE client_id = ? ORDER BY created_at DESC, id DESC LIMIT ? OFFSET ?`, clientID, limit, offset)
+	if err != nil {
+		return nil, 0, apperrors.Wrapf(err, "failed to list forms for client %d", clientID)
+	}
+	defer rows.Close()
+
+	forms := []store.Form{}
+	for rows.Next() {
+		form, err := scanForm(rows)
+		if err != nil {
+			return nil, 0, apperrors.Wrap(err, "failed to scan form row")
+		}
+		forms = append(forms, form)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, apperrors.Wrap(err, "error iterating form rows")
+	}
+
+	return forms, total, nil
+}
+
+// ListFormsByType returns a paginated list of forms of the given type across all clients,
+// ordered by client name and then form name.
+func (s *Store) ListFormsByType(formType store.FormType, offset, limit int) ([]store.Form, int, error) {
+	limit = s.formatLimit(limit)
+	offset = formatOffset(offset)
+
+	whereClause := ""
+	var args []interface{}
+	if formType != "" {
+		if err := validator.ValidateFormType(formType); err != nil {
+			return nil, 0, err
+		}
+		whereClause = "WHERE f.type = ?"
+		args = append(args, string(formType))
+	}
+
+	var total int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM forms f `+whereClause, args...).Scan(&total); err != nil {
+		return nil, 0, apperrors.Wrap(err, "failed to count forms")
+	}
+
+	rows, err := s.db.Query(`
+SELECT f.id, f.client_id, c.name, f.name, f.type, f.unique_email, f.priority_field, f.language, f.success_url, f.theme_primary, f.theme_radius, f.theme_font, f.categories, f.active, f.require_consent, f.consent_label, f.privacy_url, f.created_at
+FROM forms f
+JOIN clients c ON c.id = f.client_id
+`+whereClause+`
+ORDER BY c.name ASC, f.name ASC, f.id ASC
+LIMIT ? OFFSET ?
+`, append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, apperrors.Wrap(err, "failed to list forms by type")
+	}
+	defer rows.Close()
+
+	forms := []store.Form{}
+	for rows.Next() {
+		var form store.Form
+		var categories, created string
+		if err := rows.Scan(&form.ID, &form.ClientID, &form.Client, &form.Name, &form.Type, &form.UniqueEmail, &form.PriorityField, &form.Language, &form.SuccessURL, &form.Theme.PrimaryColor, &form.Theme.BorderRadius, &form.Theme.FontFamily, &categories, &form.Active, &form.RequireConsent, &form.ConsentLabel, &form.PrivacyURL, &created); err != nil {
+			return nil, 0, apperrors.Wrap(err, "failed to scan form row")
+		}
+		form.Categories = splitCategories(categories)
+		form.CreatedAt = parseTime(created)
+		forms = append(forms, form)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, apperrors.Wrap(err, "error iterating form rows")
+	}
+
+	return forms, total, nil
+}
+
+// GetForm retrieves a form by ID.
+func (s *Store) GetForm(id int64) (store.Form, error) {
+	row := s.db.QueryRow(`SELECT `+formColumns+` FROM forms WHERE id = ?`, id)
+	form, err := scanForm(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return store.Form{}, apperrors.NotFoundError("form", id)
+		}
+		return store.Form{}, apperrors.Wrapf(err, "failed to get form %d", id)
+	}
+	return form, nil
+}
+
+// normalizeFormInput trims and defaults form settings, then validates them.
+func normalizeFormInput(input store.FormInput) (store.FormInput, error) {
+	input.Name = strings.TrimSpace(input.Name)
+	input.Language = strings.ToLower(strings.TrimSpace(input.Language))
+	input.SuccessURL = strings.TrimSpace(input.SuccessURL)
+	input.Theme.PrimaryColor = strings.ToLower(strings.TrimSpace(input.Theme.PrimaryColor))
+	input.Theme.BorderRadius = strings.ToLower(strings.TrimSpace(input.Theme.BorderRadius))
+	input.Theme.FontFamily = strings.TrimSpace(input.Theme.FontFamily)
+	input.ConsentLabel = strings.TrimSpace(input.ConsentLabel)
+	input.PrivacyURL = strings.TrimSpace(input.PrivacyURL)
+	categories := []string{}
+	for _, category := range input.Categories {
+		if category = strings.TrimSpace(category); category != "" {
+			categories = append(categories, category)
+		}
+	}
+	input.Categories = categories
+	if input.Language == "" {
+		input.Language = store.DefaultLanguage
+	}
+	if err := validator.ValidateForm(input.Name, input.Type); err != nil {
+		return input, err
+	}
+	if err := validator.ValidateLanguage(input.Language); err != nil {
+		return input, err
+	}
+	if err := validator.ValidateSuccessURL(input.SuccessURL); err != nil {
+		return input, err
+	}
+	if err := validator.ValidateTheme(input.Theme); err != nil {
+		return input, err
+	}
+	if err := validator.ValidateCategories(input.Categories); err != nil {
+		return input, err
+	}
+	if err := validator.ValidateConsent(input.ConsentLabel, input.PrivacyURL); err != nil {
+		return input, err
+	}
+	return input, nil
+}
+
+// formColumns is the column list for form queries. It must stay in sync with scanForm.
+const formColumns = `id, client_id, name, type, unique_email, priority_field, language, success_url, theme_primary, theme_radius, theme_font, categories, active, require_consent, consent_label, privacy_url, created_at`
+
+// scanForm scans a row selected with formColumns.
+func scanForm(row rowScanner) (store.Form, error) {
+	var form store.Form
+	var categories, created string
+	if err := row.Scan(&form.ID, &form.ClientID, &form.Name, &form.Type, &form.UniqueEmail, &form.PriorityField, &form.Language, &form.SuccessURL, &form.Theme.PrimaryColor, &form.Theme.BorderRadius, &form.Theme.FontFamily, &categories, &form.Active, &form.RequireConsent, &form.ConsentLabel, &form.PrivacyURL, &created); err != nil {
+		return store.Form{}, err
+	}
+	form.Categories = splitCategories(categories)
+	form.CreatedAt = parseTime(created)
+	return form, nil
+}
+
+// joinCategories encodes form categories for the categories column, one per line.
+// Categories are validated not to contain line breaks.
+func joinCategories(categories []string) string {
+	return strings.Join(categories, "\n")
+}
+
+// splitCategories decodes the categories column written by joinCategories.
+func splitCategories(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, "\n")
+}
+
+// UpdateForm updates an existing form's settings.
+func (s *Store) UpdateForm(id int64, input store.FormInput) error {
+	// Validate input
+	input, err := normalizeFormInput(input)
+	if err != nil {
+		return err
+	}
+
+	result, err := s.db.Exec(`UPDATE forms SET name = ?, type = ?, unique_email = ?, priority_field = ?, language = ?, success_url = ?, theme_primary = ?, theme_radius = ?, theme_font = ?, categories = ?, require_consent = ?, consent_label = ?, privacy_url = ? WHERE id = ?`, input.Name, string(input.Type), input.UniqueEmail, input.PriorityField, input.Language, input.SuccessURL, input.Theme.PrimaryColor, input.Theme.BorderRadius, input.Theme.FontFamily, joinCategories(input.Categories), input.RequireConsent, input.ConsentLabel, input.PrivacyURL, id)
+	if err != nil {
+		return apperrors.Wrapf(err, "failed to update form %d", id)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return apperrors.Wrap(err, "failed to check rows affected")
+	}
+	if rowsAffected == 0 {
+		return apperrors.NotFoundError("form", id)
+	}
+
+	return nil
+}
+
+// SetFormActive sets whether a form accepts submissions.
+func (s *Store) SetFormActive(id int64, active bool) error {
+	result, err := s.db.Exec(`UPDATE forms SET active = ? WHERE id = ?`, active, id)
+	if err != nil {
+		return apperrors.Wrapf(err, "failed to update active flag of form %d", id)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return apperrors.Wrap(err, "failed to check rows affected")
+	}
+	if rowsAffected == 0 {
+		return apperrors.NotFoundError("form", id)
+	}
+
+	return nil
+}
+
+// DeleteForm permanently deletes a form and all associated submissions.
+func (s *Store) DeleteForm(id int64) error {
+	// Check if form exists
+	if _, err := s.GetForm(id); err != nil {
+		return err
+	}
+
+	// Delete notes, status history, and submissions for this form first (foreign key constraint)
+	if _, err := s.db.Exec(`DELETE FROM submission_notes WHERE submission_id IN (SELECT id FROM submissions WHERE form_id = ?)`, id); err != nil {
+		return apperrors.Wrapf(err, "failed to delete submission notes for form %d", id)
+	}
+	if _, err := s.db.Exec(`DELETE FROM submission_status_history WHERE submission_id IN (SELECT id FROM submissions WHERE form_id = ?)`, id); err != nil {
+		return apperrors.Wrapf(err, "failed to delete submission status history for form %d", id)
+	}
+	if _, err := s.db.Exec(`DELETE FROM submissions WHERE form_id = ?`, id); err != nil {
+		return apperrors.Wrapf(err, "failed to delete submissions for form %d", id)
+	}
+
+	// Delete the form
+	if _, err := s.db.Exec(`DELETE FROM forms WHERE id = ?`, id); err != nil {
+		return apperrors.Wrapf(err, "failed to delete form %d", id)
+	}
+
+	return nil
+}
+
+// CreateSubmission creates a new submission after validating the input.
+func (s *Store) CreateSubmission(formID int64, input store.SubmissionInput) (store.Submission, error) {
+	// Trim and validate input
+	input = validator.TrimSubmissionInput(input, s.phoneRegion)
+	if err := validator.ValidateSubmission(input, s.limits); err != nil {
+		return store.Submission{}, err
+	}
+	if s.anonymizeIP {
+		input.IP = validator.AnonymizeIP(input.IP)
+	}
+
+	// Verify form exists and get client ID
+	form, err := s.GetForm(formID)
+	if err != nil {
+		return store.Submission{}, apperrors.Wrapf(err, "form %d not found", formID)
+	}
+
+	// Forms with categories require one of them; others never store a category
+	input.Category, err = validator.MatchCategory(input.Category, form.Categories)
+	if err != nil {
+		return store.Submission{}, err
+	}
+
+	// Consent isn't checked when the form doesn't ask for it, so it is only kept when asked
+	if !form.RequireConsent {
+		input.Consent = ""
+	} else if strings.TrimSpace(input.Consent) == "" {
+		return store.Submission{}, apperrors.InvalidInputError("consent", "is required for this form")
+	}
+
+	// One-shot forms accept a single submission per email address
+	if form.UniqueEmail && input.Email == "" {
+		return store.Submission{}, apperrors.InvalidInputError("email", "is required for this form")
+	}
+
+	status := validator.StatusOpen
+	if input.Spam {
+		status = validator.StatusSpam
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return store.Submission{}, apperrors.Wrap(err, "failed to begin submission insert")
+	}
+	defer tx.Rollback()
+
+	// Checked inside the transaction, so concurrent submissions from the same address
+	// can't both pass before either is inserted
+	if form.UniqueEmail {
+		var exists bool
+		err := tx.QueryRow(`SELECT EXISTS(SELECT 1 FROM submissions WHERE form_id = ? AND LOWER(email) = LOWER(?))`, form.ID, input.Email).Scan(&exists)
+		if err != nil {
+			return store.Submission{}, apperrors.Wrap(err, "failed to check for duplicate submission")
+		}
+		if exists {
+			return store.Submission{}, apperrors.ConflictError("submission", "this email address has already submitted this form")
+		}
+	}
+
+	// The reference needs the new ID, so the row is inserted with a unique placeholder first
+	token := rand.Text()
+	result, err := tx.Exec(`
+INSERT INTO submissions (client_id, form_id, status, name, email, phone, phone_e164, subject, message, priority, category, ip, user_agent, email_valid, source, status_token, reference, consent)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+`, form.ClientID, form.ID, status, input.Name, input.Email, input.Phone, input.PhoneE164, input.Subject, input.Message, input.Priority, input.Category, input.IP, input.UserAgent, validator.ValidateEmailStrict(input.Email) == nil, input.Source, token, token, input.Consent)
+	if err != nil {
+		return store.Submission{}, apperrors.Wrap(err, "failed to create submission")
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return store.Submission{}, apperrors.Wrap(err, "failed to get submission ID")
+	}
+
+	if _, err := tx.Exec(`UPDATE submissions SET reference = ? WHERE id = ?`, s.formatReference(time.Now().UTC().Year(), id), id); err != nil {
+		return store.Submission{}, apperrors.Wrap(err, "failed to set submission reference")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return store.Submission{}, apperrors.Wrap(err, "failed to commit submission insert")
+	}
+
+	return s.GetSubmission(id)
+}
+
+// submissionColumns is the column list for submission queries joined with clients (c) and forms (f).
+// It must stay in sync with scanSubmission.
+const submissionColumns = `s.id, s.client_id, c.name, s.form_id, f.name, f.type, s.status, s.name, s.email, s.phone, s.phone_e164, s.subject, s.message, s.priority, s.category, s.ip, s.user_agent, s.assignee, s.email_valid, s.source, s.created_at, COALESCE(s.deleted_at, ''), s.updated_by, s.status_token, s.reference, s.consent`
+
+// submissionSortColumns maps allowed sort fields to their ORDER BY expressions.
+// Only these fixed expressions are ever interpolated into SQL.
+var submissionSortColumns = map[string]string{
+	store.SortCreatedAt: "s.created_at",
+	store.SortStatus:    "s.status",
+	store.SortClient:    "c.name",
+	store.SortPriority:  "CASE s.priority WHEN 'high' THEN 3 WHEN 'medium' THEN 2 WHEN 'low' THEN 1 ELSE 0 END",
+}
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanSubmission scans a row selected with submissionColumns.
+func scanSubmission(row rowScanner) (store.Submission, error) {
+	var submission store.Submission
+	var created, archived string
+	if err := row.Scan(&submission.ID, &submission.ClientID, &submission.Client, &submission.FormID, &submission.Form, &submission.FormType, &submission.Status, &submission.Name, &submission.Email, &submission.Phone, &submission.PhoneE164, &submission.Subject, &submission.Message, &submission.Priority, &submission.Category, &submission.IP, &submission.UserAgent, &submission.Assignee, &submission.EmailValid, &submission.Source, &created, &archived, &submission.UpdatedBy, &submission.StatusToken, &submission.Reference, &submission.Consent); err != nil {
+		return store.Submission{}, err
+	}
+	submission.CreatedAt = parseTime(created)
+	submission.ArchivedAt = parseTime(archived)
+	return submission, nil
+}
+
+// ListSubmissions returns a paginated list of unarchived submissions with denormalized client and form data.
+func (s *Store) ListSubmissions(offset, limit int) ([]store.Submission, int, error) {
+	// Apply default pagination limits
+	limit = s.formatLimit(limit)
+	offset = formatOffset(offset)
+
+	var total int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM submissions WHERE deleted_at IS NULL`).Scan(&total); err != nil {
+		return nil, 0, apperrors.Wrap(err, "failed to count submissions")
+	}
+
+	rows, err := s.db.Query(`
+SELECT `+submissionColumns+`
+FROM submissions s
+JOIN clients c ON c.id = s.client_id
+JOIN forms f ON f.id = s.form_id
+WHERE s.deleted_at IS NULL
+ORDER BY s.created_at DESC
+LIMIT ? OFFSET ?
+`, limit, offset)
+	if err != nil {
+		return nil, 0, apperrors.Wrap(err, "failed to list submissions")
+	}
+	defer rows.Close()
+
+	submissions := []store.Submission{}
+	for rows.Next() {
+		submission, err := scanSubmission(rows)
+		if err != nil {
+			return nil, 0, apperrors.Wrap(err, "failed to scan submission row")
+		}
+		submissions = append(submissions, submission)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, apperrors.Wrap(err, "error iterating submission rows")
+	}
+
+	return submissions, total, nil
+}
+
+// ListSubmissionsAfter returns a page of unarchived submissions after cursor using keyset
+// pagination on (created_at, id). One extra row is read to tell whether a next page exists.
+func (s *Store) ListSubmissionsAfter(cursor string, limit int) ([]store.Submission, string, error) {
+	limit = s.formatLimit(limit)
+
+	conditions := []string{"s.deleted_at IS NULL"}
+	var args []interface{}
+	if cursor != "" {
+		created, id, err := decodeSubmissionCursor(cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		conditions = append(conditions, "(s.created_at, s.id) < (?, ?)")
+		args = append(args, created, id)
+	}
+	args = append(args, limit+1)
+
+	rows, err := s.db.Query(`
+SELECT `+submissionColumns+`
+FROM submissions s
+JOIN clients c ON c.id = s.client_id
+JOIN forms f ON f.id = s.form_id
+WHERE `+strings.Join(conditions, " AND ")+`
+ORDER BY s.created_at DESC, s.id DESC
+LIMIT ?
+`, args...)
+	if err != nil {
+		return nil, "", apperrors.Wrap(err, "failed to list submissions after cursor")
+	}
+	defer rows.Close()
+
+	submissions := []store.Submission{}
+	for rows.Next() {
+		submission, err := scanSubmission(rows)
+		if err != nil {
+			return nil, "", apperrors.Wrap(err, "failed to scan submission row")
+		}
+		submissions = append(submissions, submission)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, "", apperrors.Wrap(err, "error iterating submission rows")
+	}
+
+	if len(submissions) <= limit {
+		return submissions, "", nil
+	}
+	submissions = submissions[:limit]
+	last := submissions[limit-1]
+
+	// The cursor carries created_at exactly as stored: go-sqlite3 parses TIMESTAMP columns into
+	// time.Time, and reformatting that would drop fractional seconds and skip rows sharing a second.
+	var created string
+	if err := s.db.QueryRow(`SELECT CAST(created_at AS TEXT) FROM submissions WHERE id = ?`, last.ID).Scan(&created); err != nil {
+		return nil, "", apperrors.Wrapf(err, "failed to read created_at of submission %d", last.ID)
+	}
+	return submissions, encodeSubmissionCursor(created, last.ID), nil
+}
+
+// ListClientSubmissions returns a page of a client's submissions ordered by ID, archived or not.
+func (s *Store) ListClientSubmissions(clientID, afterID int64, limit int) ([]store.Submission, error) {
+	limit = s.formatLimit(limit)
+
+	rows, err := s.db.Query(`
+SELECT `+submissionColumns+`
+FROM submissions s
+JOIN clients c ON c.id = s.client_id
+JOIN forms f ON f.id = s.form_id
+WHERE s.client_id = ? AND s.id > ?
+ORDER BY s.id ASC
+LIMIT ?
+`, clientID, afterID, limit)
+	if err != nil {
+		return nil, apperrors.Wrapf(err, "failed to list submissions for client %d", clientID)
+	}
+	defer rows.Close()
+
+	submissions := []store.Submission{}
+	for rows.Next() {
+		submission, err := scanSubmission(rows)
+		if err != nil {
+			return nil, apperrors.Wrap(err, "failed to scan submission row")
+		}
+		submissions = append(submissions, submission)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, apperrors.Wrap(err, "error iterating submission rows")
+	}
+
+	return submissions, nil
+}
+
+// encodeSubmissionCursor packs a submission's raw stored created_at and ID
+// into an opaque URL-safe cursor.
+func encodeSubmissionCursor(created string, id int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(created + "|" + strconv.FormatInt(id, 10)))
+}
+
+// decodeSubmissionCursor reverses encodeSubmissionCursor.
+func decodeSubmissionCursor(cursor string) (string, int64, error) {
+	malformed := apperrors.InvalidInputError("cursor", "is malformed")
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", 0, malformed
+	}
+	created, idText, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return "", 0, malformed
+	}
+	id, err := strconv.ParseInt(idText, 10, 64)
+	if err != nil || id <= 0 {
+		return "", 0, malformed
+	}
+	if parseTime(created).IsZero() {
+		return "", 0, malformed
+	}
+	return created, id, nil
+}
+
+// FilterSubmissions returns a filtered, sorted, paginated list of submissions.
+// Filters are applied dynamically based on provided parameters.
+// Empty/zero values are ignored (no filtering for that field).
+// The sort field is mapped through an allowlist so it can never inject SQL.
+func (s *Store) FilterSubmissions(offset, limit int, filter store.SubmissionFilter) ([]store.Submission, int, error) {
+	limit = s.formatLimit(limit)
+	offset = formatOffset(offset)
+
+	if err := validator.ValidateSubmissionSort(filter.SortField, filter.SortDir); err != nil {
+		return nil, 0, err
+	}
+	sortField := filter.SortField
+	if sortField == "" {
+		sortField = store.SortCreatedAt
+	}
+	sortDir := "DESC"
+	if filter.SortDir == store.SortAsc {
+		sortDir = "ASC"
+	}
+	orderBy := fmt.Sprintf("%s %s, s.id %s", submissionSortColumns[sortField], sortDir, sortDir)
+
+	// Build dynamic WHERE clause
+	var conditions []string
+	var args []interface{}
+
+	if filter.Status != "" {
+		conditions = append(conditions, "s.status = ?")
+		args = append(args, filter.Status)
+	}
+	if filter.ClientID > 0 {
+		conditions = append(conditions, "s.client_id = ?")
+		args = append(args, filter.ClientID)
+	}
+	if filter.FormID > 0 {
+		conditions = append(conditions, "s.form_id = ?")
+		args = append(args, filter.FormID)
+	}
+	if filter.Unassigned {
+		conditions = append(conditions, "s.assignee = ''")
+	} else if filter.Assignee != "" {
+		conditions = append(conditions, "s.assignee = ?")
+		args = append(args, filter.Assignee)
+	}
+	if filter.Category != "" {
+		conditions = append(conditions, "s.category = ?")
+		args = append(args, filter.Category)
+	}
+	if filter.InvalidEmail {
+		conditions = append(conditions, "s.email_valid = 0")
+	}
+	if filter.ArchivedOnly {
+		conditions = append(conditions, "s.deleted_at IS NOT NULL")
+	} else if !filter.IncludeArchived {
+		conditions = append(conditions, "s.deleted_at IS NULL")
+	}
+	if filter.Search != "" {
+		conditions = append(conditions, "s.subject LIKE ?")
+		args = append(args, "%"+filter.Search+"%")
+	}
+
+	whereClause := ""
+	if len(conditions) > 0 {
+		whereClause = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	// Count total filtered results
+	countQuery := fmt.Sprintf(`SELECT COUNT(*) FROM submissions s %s`, whereClause)
+	var total int
+	if err := s.db.QueryRow(countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, apperrors.Wrap(err, "failed to count filtered submissions")
+	}
+
+	// Get filtered submissions
+	query := fmt.Sprintf(`
+SELECT %s
+FROM submissions s
+JOIN clients c ON c.id = s.client_id
+JOIN forms f ON f.id = s.form_id
+%s
+ORDER BY %s
+LIMIT ? OFFSET ?
+`, submissionColumns, whereClause, orderBy)
+
+	// Append limit and offset to args
+	queryArgs := append(args, limit, offset)
+
+	rows, err := s.db.Query(query, queryArgs...)
+	if err != nil {
+		return nil, 0, apperrors.Wrap(err, "failed to filter submissions")
+	}
+	defer rows.Close()
+
+	submissions := []store.Submission{}
+	for rows.Next() {
+		submission, err := scanSubmission(rows)
+		if err != nil {
+			return nil, 0, apperrors.Wrap(err, "failed to scan filtered submission row")
+		}
+		submissions = append(submissions, submission)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, apperrors.Wrap(err, "error iterating filtered submission rows")
+	}
+
+	return submissions, total, nil
+}
+
+// ListSubmissionsWithInvalidEmail returns unarchived submissions flagged as having an invalid email, newest first.
+func (s *Store) ListSubmissionsWithInvalidEmail() ([]store.Submission, error) {
+	rows, err := s.db.Query(`
+SELECT ` + submissionColumns + `
+FROM submissions s
+JOIN clients c ON c.id = s.client_id
+JOIN forms f ON f.id = s.form_id
+WHERE s.email_valid = 0 AND s.deleted_at IS NULL
+ORDER BY s.created_at DESC, s.id DESC
+`)
+	if err != nil {
+		return nil, apperrors.Wrap(err, "failed to list submissions with invalid email")
+	}
+	defer rows.Close()
+
+	submissions := []store.Submission{}
+	for rows.Next() {
+		submission, err := scanSubmission(rows)
+		if err != nil {
+			return nil, apperrors.Wrap(err, "failed to scan submission row")
+		}
+		submissions = append(submissions, submission)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, apperrors.Wrap(err, "error iterating submission rows")
+	}
+
+	return submissions, nil
+}
+
+// GetSubmission retrieves a submission by ID with denormalized client and form data.
+func (s *Store) GetSubmission(id int64) (store.Submission, error) {
+	row := s.db.QueryRow(`
+SELECT `+submissionColumns+`
+FROM submissions s
+JOIN clients c ON c.id = s.client_id
+JOIN forms f ON f.id = s.form_id
+WHERE s.id = ?
+`, id)
+
+	submission, err := scanSubmission(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return store.Submission{}, apperrors.NotFoundError("submission", id)
+		}
+		return store.Submission{}, apperrors.Wrapf(err, "failed to get submission %d", id)
+	}
+	return submission, nil
+}
+
+// GetSubmissionByToken retrieves a submission by its status token with denormalized client and form data.
+func (s *Store) GetSubmissionByToken(token string) (store.Submission, error) {
+	// Old submissions share the empty token, which must never match
+	if token == "" {
+		return store.Submission{}, fmt.Errorf("submission for status token: %w", apperrors.ErrNotFound)
+	}
+	row := s.db.QueryRow(`
+SELECT `+submissionColumns+`
+FROM submissions s
+JOIN clients c ON c.id = s.client_id
+JOIN forms f ON f.id = s.form_id
+WHERE s.status_token = ?
+`, token)
+
+	submission, err := scanSubmission(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return store.Submission{}, fmt.Errorf("submission for status token: %w", apperrors.ErrNotFound)
+		}
+		return store.Submission{}, apperrors.Wrap(err, "failed to get submission by status token")
+	}
+	return submission, nil
+}
+
+// GetSubmissionByReference retrieves a submission by its reference number with denormalized client and form data.
+func (s *Store) GetSubmissionByReference(ref string) (store.Submission, error) {
+	row := s.db.QueryRow(`
+SELECT `+submissionColumns+`
+FROM submissions s
+JOIN clients c ON c.id = s.client_id
+JOIN forms f ON f.id = s.form_id
+WHERE s.reference = ?
+`, strings.ToUpper(strings.TrimSpace(ref)))
+
+	submission, err := scanSubmission(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return store.Submission{}, apperrors.NotFoundError("submission", ref)
+		}
+		return store.Submission{}, apperrors.Wrap(err, "failed to get submission by reference")
+	}
+	return submission, nil
+}
+
+// UpdateSubmissionStatus updates the status of a submission after validating it.
+// The transition is added to the status history unless the status is unchanged.
+func (s *Store) UpdateSubmissionStatus(id int64, status, actor string) error {
+	// Validate status
+	status = strings.TrimSpace(status)
+	if err := validator.ValidateStatus(status); err != nil {
+		return err
+	}
+	actor = strings.TrimSpace(actor)
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return apperrors.Wrapf(err, "failed to begin status update for submission %d", id)
+	}
+	defer tx.Rollback()
+
+	var from string
+	err = tx.QueryRow(`SELECT `+currentStatus+` FROM submissions WHERE id = ?`, id).Scan(&from)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return apperrors.NotFoundError("submission", id)
+		}
+		return apperrors.Wrapf(err, "failed to get submission %d status", id)
+	}
+
+	if _, err := tx.Exec(`UPDATE submissions SET status = ?, updated_by = ? WHERE id = ?`, status, actor, id); err != nil {
+		return apperrors.Wrapf(err, "failed to update submission %d status", id)
+	}
+	if from != status {
+		_, err = tx.Exec(`INSERT INTO submission_status_history (submission_id, from_status, to_status, actor) VALUES (?, ?, ?, ?)`, id, from, status, actor)
+		if err != nil {
+			return apperrors.Wrapf(err, "failed to record status change of submission %d", id)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return apperrors.Wrapf(err, "failed to commit status update for submission %d", id)
+	}
+	return nil
+}
+
+// currentStatus selects the status of a submission, counting an empty status as OPEN.
+const currentStatus = `COALESCE(NULLIF(status, ''), '` + validator.StatusOpen + `')`
+
+// GetStatusHistory returns the status transitions of a submission in the order they happened.
+func (s *Store) GetStatusHistory(id int64) ([]store.StatusChange, error) {
+	rows, err := s.db.Query(`
+SELECT id, submission_id, from_status, to_status, actor, created_at
+FROM submission_status_history
+WHERE submission_id = ?
+ORDER BY created_at ASC, id ASC
+`, id)
+	if err != nil {
+		return nil, apperrors.Wrapf(err, "failed to get status history for submission %d", id)
+	}
+	defer rows.Close()
+
+	history := []store.StatusChange{}
+	for rows.Next() {
+		var change store.StatusChange
+		var created string
+		if err := rows.Scan(&change.ID, &change.SubmissionID, &change.FromStatus, &change.ToStatus, &change.Actor, &created); err != nil {
+			return nil, apperrors.Wrap(err, "failed to scan status history row")
+		}
+		change.CreatedAt = parseTime(created)
+		history = append(history, change)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, apperrors.Wrap(err, "error iterating status history rows")
+	}
+
+	return history, nil
+}
+
+// ListSubmissionsModifiedBy returns a paginated list of submissions last changed by admin, newest first.
+func (s *Store) ListSubmissionsModifiedBy(admin string, offset, limit int) ([]store.Submission, int, error) {
+	limit = s.formatLimit(limit)
+	offset = formatOffset(offset)
+
+	admin = strings.TrimSpace(admin)
+	if err := validator.ValidateUsername(admin); err != nil {
+		return nil, 0, err
+	}
+
+	var total int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM submissions WHERE updated_by = ?`, admin).Scan(&total); err != nil {
+		return nil, 0, apperrors.Wrapf(err, "failed to count submissions modified by %s", admin)
+	}
+
+	rows, err := s.db.Query(`
+SELECT `+submissionColumns+`
+FROM submissions s
+JOIN clients c ON c.id = s.client_id
+JOIN forms f ON f.id = s.form_id
+WHERE s.updated_by = ?
+ORDER BY s.created_at DESC, s.id DESC
+LIMIT ? OFFSET ?
+`, admin, limit, offset)
+	if err != nil {
+		return nil, 0, apperrors.Wrapf(err, "failed to list submissions modified by %s", admin)
+	}
+	defer rows.Close()
+
+	submissions := []store.Submission{}
+	for rows.Next() {
+		submission, err := scanSubmission(rows)
+		if err != nil {
+			return nil, 0, apperrors.Wrap(err, "failed to scan submission row")
+		}
+		submissions = append(submissions, submission)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, apperrors.Wrap(err, "error iterating submission rows")
+	}
+
+	return submissions, total, nil
+}
+
+// AssignSubmission sets or clears the assignee of a submission.
+func (s *Store) AssignSubmission(id int64, assignee, actor string) error {
+	assignee = strings.TrimSpace(assignee)
+	if assignee != "" {
+		if err := validator.ValidateUsername(assignee); err != nil {
+			return err
+		}
+	}
+
+	result, err := s.db.Exec(`UPDATE submissions SET assignee = ?, updated_by = ? WHERE id = ?`, assignee, strings.TrimSpace(actor), id)
+	if err != nil {
+		return apperrors.Wrapf(err, "failed to assign submission %d", id)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return apperrors.Wrap(err, "failed to check rows affected")
+	}
+	if rowsAffected == 0 {
+		return apperrors.NotFoundError("submission", id)
+	}
+
+	return nil
+}
+
+// AddSubmissionNote adds an internal note to a submission after validating it.
+func (s *Store) AddSubmissionNote(submissionID int64, author, body string) (store.SubmissionNote, error) {
+	author = strings.TrimSpace(author)
+	body = strings.TrimSpace(body)
+	if err := validator.ValidateNote(author, body); err != nil {
+		return store.SubmissionNote{}, err
+	}
+
+	// Verify submission exists
+	if _, err := s.GetSubmission(submissionID); err != nil {
+		return store.SubmissionNote{}, err
+	}
+
+	result, err := s.db.Exec(`INSERT INTO submission_notes (submission_id, author, body) VALUES (?, ?, ?)`, submissionID, author, body)
+	if err != nil {
+		return store.SubmissionNote{}, apperrors.Wrapf(err, "failed to add note to submission %d", submissionID)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return store.SubmissionNote{}, apperrors.Wrap(err, "failed to get note ID")
+	}
+
+	var note store.SubmissionNote
+	var created string
+	err = s.db.QueryRow(`SELECT id, submission_id, author, body, created_at FROM submission_notes WHERE id = ?`, id).
+		Scan(&note.ID, &note.SubmissionID, &note.Author, &note.Body, &created)
+	if err != nil {
+		return store.SubmissionNote{}, apperrors.Wrapf(err, "failed to get note %d", id)
+	}
+	note.CreatedAt = parseTime(created)
+	return note, nil
+}
+
+// ListSubmissionNotes returns the notes on a submission in the order they were written.
+func (s *Store) ListSubmissionNotes(submissionID int64) ([]store.SubmissionNote, error) {
+	rows, err := s.db.Query(`
+SELECT id, submission_id, author, body, created_at
+FROM submission_notes
+WHERE submission_id = ?
+ORDER BY created_at ASC, id ASC
+`, submissionID)
+	if err != nil {
+		return nil, apperrors.Wrapf(err, "failed to list notes for submission %d", submissionID)
+	}
+	defer rows.Close()
+
+	notes := []store.SubmissionNote{}
+	for rows.Next() {
+		var note store.SubmissionNote
+		var created string
+		if err := rows.Scan(&note.ID, &note.SubmissionID, &note.Author, &note.Body, &created); err != nil {
+			return nil, apperrors.Wrap(err, "failed to scan note row")
+		}
+		note.CreatedAt = parseTime(created)
+		notes = append(notes, note)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, apperrors.Wrap(err, "error iterating note rows")
+	}
+
+	return notes, nil
+}
+
+// idPlaceholders returns the placeholder list and arguments for an "IN (...)" clause over ids.
+func idPlaceholders(ids []int64) (string, []interface{}) {
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	return strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", "), args
+}
+
+// BulkUpdateStatus sets the status of the given submissions in a single transaction.
+func (s *Store) BulkUpdateStatus(ids []int64, status, actor string) (int64, error) {
+	if err := validator.ValidateIDs(ids); err != nil {
+		return 0, err
+	}
+	status = strings.TrimSpace(status)
+	if err := validator.ValidateStatus(status); err != nil {
+		return 0, err
+	}
+	placeholders, args := idPlaceholders(ids)
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, apperrors.Wrap(err, "failed to begin bulk status update")
+	}
+	defer tx.Rollback()
+	actor = strings.TrimSpace(actor)
+
+	// Record the history while the old statuses are still there
+	_, err = tx.Exec(`
+INSERT INTO submission_status_history (submission_id, from_status, to_status, actor)
+SELECT id, `+currentStatus+`, ?, ? FROM submissions
+WHERE id IN (`+placeholders+`) AND `+currentStatus+` != ?`, append(append([]interface{}{status, actor}, args...), status)...)
+	if err != nil {
+		return 0, apperrors.Wrap(err, "failed to record submission status changes")
+	}
+
+	result, err := tx.Exec(`UPDATE submissions SET status = ?, updated_by = ? WHERE id IN (`+placeholders+`)`, append([]interface{}{status, actor}, args...)...)
+	if err != nil {
+		return 0, apperrors.Wrap(err, "failed to update submission statuses")
+	}
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return 0, apperrors.Wrap(err, "failed to check rows affected")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, apperrors.Wrap(err, "failed to commit bulk status update")
+	}
+	return updated, nil
+}
+
+// BulkDelete permanently deletes the given submissions, their notes, and their status history
+// in a single transaction.
+func (s *Store) BulkDelete(ids []int64) (int64, error) {
+	if err := validator.ValidateIDs(ids); err != nil {
+		return 0, err
+	}
+	placeholders, args := idPlaceholders(ids)
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, apperrors.Wrap(err, "failed to begin bulk delete")
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM submission_notes WHERE submission_id IN (`+placeholders+`)`, args...); err != nil {
+		return 0, apperrors.Wrap(err, "failed to delete notes for submissions")
+	}
+	if _, err := tx.Exec(`DELETE FROM submission_status_history WHERE submission_id IN (`+placeholders+`)`, args...); err != nil {
+		return 0, apperrors.Wrap(err, "failed to delete status history for submissions")
+	}
+	result, err := tx.Exec(`DELETE FROM submissions WHERE id IN (`+placeholders+`)`, args...)
+	if err != nil {
+		return 0, apperrors.Wrap(err, "failed to delete submissions")
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, apperrors.Wrap(err, "failed to check rows affected")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, apperrors.Wrap(err, "failed to commit bulk delete")
+	}
+	return deleted, nil
+}
+
+// systemNoteAuthor is the author of notes written by TicketD itself rather than an admin.
+const systemNoteAuthor = "system"
+
+// BulkCloseSubmissionsOlderThan closes stale open submissions and notes why on each of them.
+// Submissions without a status count as OPEN.
+func (s *Store) BulkCloseSubmissionsOlderThan(t time.Time, reason string) (int64, error) {
+	if t.IsZero() {
+		return 0, apperrors.InvalidInputError("before", "is required")
+	}
+	reason = strings.TrimSpace(reason)
+	if err := validator.ValidateCloseReason(reason); err != nil {
+		return 0, err
+	}
+	before := formatTimeParam(t)
+	body := fmt.Sprintf("Closed in bulk with tickets received before %s UTC: %s", before, reason)
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, apperrors.Wrap(err, "failed to begin bulk close")
+	}
+	defer tx.Rollback()
+
+	// All statements select the same rows: notes and history go in first, while they are still open
+	const stale = `status IN ('OPEN', 'IN_PROGRESS', '') AND created_at < ? AND deleted_at IS NULL`
+
+	_, err = tx.Exec(`
+INSERT INTO submission_notes (submission_id, author, body)
+SELECT id, ?, ? FROM submissions WHERE `+stale, systemNoteAuthor, body, before)
+	if err != nil {
+		return 0, apperrors.Wrap(err, "failed to add bulk close notes")
+	}
+
+	_, err = tx.Exec(`
+INSERT INTO submission_status_history (submission_id, from_status, to_status, actor)
+SELECT id, `+currentStatus+`, ?, ? FROM submissions WHERE `+stale, validator.StatusClosed, systemNoteAuthor, before)
+	if err != nil {
+		return 0, apperrors.Wrap(err, "failed to record bulk close status changes")
+	}
+
+	result, err := tx.Exec(`UPDATE submissions SET status = ?, updated_by = ? WHERE `+stale, validator.StatusClosed, systemNoteAuthor, before)
+	if err != nil {
+		return 0, apperrors.Wrap(err, "failed to close submissions")
+	}
+	closed, err := result.RowsAffected()
+	if err != nil {
+		return 0, apperrors.Wrap(err, "failed to check rows affected")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, apperrors.Wrap(err, "failed to commit bulk close")
+	}
+	return closed, nil
+}
+
+// DeleteSubmissionsOlderThan permanently deletes submissions created before cutoff,
+// their notes, and their status history in a single transaction.
+func (s *Store) DeleteSubmissionsOlderThan(cutoff time.Time) (int64, error) {
+	if cutoff.IsZero() {
+		return 0, apperrors.InvalidInputError("cutoff", "is required")
+	}
+	before := formatTimeParam(cutoff)
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, apperrors.Wrap(err, "failed to begin retention delete")
+	}
+	defer tx.Rollback()
+
+	const expired = `SELECT id FROM submissions WHERE created_at < ?`
+	if _, err := tx.Exec(`DELETE FROM submission_notes WHERE submission_id IN (`+expired+`)`, before); err != nil {
+		return 0, apperrors.Wrap(err, "failed to delete notes for expired submissions")
+	}
+	if _, err := tx.Exec(`DELETE FROM submission_status_history WHERE submission_id IN (`+expired+`)`, before); err != nil {
+		return 0, apperrors.Wrap(err, "failed to delete status history for expired submissions")
+	}
+	result, err := tx.Exec(`DELETE FROM submissions WHERE created_at < ?`, before)
+	if err != nil {
+		return 0, apperrors.Wrap(err, "failed to delete expired submissions")
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, apperrors.Wrap(err, "failed to check rows affected")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, apperrors.Wrap(err, "failed to commit retention delete")
+	}
+	return deleted, nil
+}
+
+// DeleteSubmissionsByEmail permanently deletes all submissions from an email address,
+// their notes, and their status history in a single transaction.
+func (s *Store) DeleteSubmissionsByEmail(email string) (int64, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" {
+		return 0, apperrors.InvalidInputError("email", "is required")
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, apperrors.Wrap(err, "failed to begin erasure")
+	}
+	defer tx.Rollback()
+
+	const matching = `SELECT id FROM submissions WHERE LOWER(TRIM(email)) = ?`
+	if _, err := tx.Exec(`DELETE FROM submission_notes WHERE submission_id IN (`+matching+`)`, email); err != nil {
+		return 0, apperrors.Wrap(err, "failed to delete notes for erased submissions")
+	}
+	if _, err := tx.Exec(`DELETE FROM submission_status_history WHERE submission_id IN (`+matching+`)`, email); err != nil {
+		return 0, apperrors.Wrap(err, "failed to delete status history for erased submissions")
+	}
+	result, err := tx.Exec(`DELETE FROM submissions WHERE LOWER(TRIM(email)) = ?`, email)
+	if err != nil {
+		return 0, apperrors.Wrap(err, "failed to erase submissions")
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, apperrors.Wrap(err, "failed to check rows affected")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, apperrors.Wrap(err, "failed to commit erasure")
+	}
+	return deleted, nil
+}
+
+// ArchiveSubmission marks a submission as archived, keeping the first archive time.
+func (s *Store) ArchiveSubmission(id int64) error {
+	result, err := s.db.Exec(`UPDATE submissions SET deleted_at = COALESCE(deleted_at, CURRENT_TIMESTAMP) WHERE id = ?`, id)
+	if err != nil {
+		return apperrors.Wrapf(err, "failed to archive submission %d", id)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return apperrors.Wrap(err, "failed to check rows affected")
+	}
+	if rowsAffected == 0 {
+		return apperrors.NotFoundError("submission", id)
+	}
+
+	return nil
+}
+
+// RestoreSubmission clears the archive mark of a submission.
+func (s *Store) RestoreSubmission(id int64) error {
+	result, err := s.db.Exec(`UPDATE submissions SET deleted_at = NULL WHERE id = ?`, id)
+	if err != nil {
+		return apperrors.Wrapf(err, "failed to restore submission %d", id)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return apperrors.Wrap(err, "failed to check rows affected")
+	}
+	if rowsAffected == 0 {
+		return apperrors.NotFoundError("submission", id)
+	}
+
+	return nil
+}
+
+// DeleteSubmission permanently deletes a submission, its notes, and its status history.
+func (s *Store) DeleteSubmission(id int64) error {
+	if _, err := s.db.Exec(`DELETE FROM submission_notes WHERE submission_id = ?`, id); err != nil {
+		return apperrors.Wrapf(err, "failed to delete notes for submission %d", id)
+	}
+	if _, err := s.db.Exec(`DELETE FROM submission_status_history WHERE submission_id = ?`, id); err != nil {
+		return apperrors.Wrapf(err, "failed to delete status history for submission %d", id)
+	}
+
+	result, err := s.db.Exec(`DELETE FROM submissions WHERE id = ?`, id)
+	if err != nil {
+		return apperrors.Wrapf(err, "failed to delete submission %d", id)
+	}
+
+	// Check if any rows were affected
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return apperrors.Wrap(err, "failed to check rows affected")
+	}
+	if rowsAffected == 0 {
+		return apperrors.NotFoundError("submission", id)
+	}
+
+	return nil
+}
+
+// TopSubjects returns the most frequent normalized subjects in the given time range.
+func (s *Store) TopSubjects(limit int, from, to time.Time) ([]store.SubjectCount, error) {
+	limit = s.formatLimit(limit)
+
+	conditions := []string{"TRIM(subject) != ''"}
+	var args []interface{}
+	if !from.IsZero() {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, formatTimeParam(from))
+	}
+	if !to.IsZero() {
+		conditions = append(conditions, "created_at < ?")
+		args = append(args, formatTimeParam(to))
+	}
+	args = append(args, limit)
+
+	query := fmt.Sprintf(`
+SELECT LOWER(TRIM(subject)) AS normalized, COUNT(*) AS total
+FROM submissions
+WHERE %s
+GROUP BY normalized
+ORDER BY total DESC, normalized ASC
+LIMIT ?
+`, strings.Join(conditions, " AND "))
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, apperrors.Wrap(err, "failed to query top subjects")
+	}
+	defer rows.Close()
+
+	subjects := []store.SubjectCount{}
+	for rows.Next() {
+		var subject store.SubjectCount
+		if err := rows.Scan(&subject.Subject, &subject.Count); err != nil {
+			return nil, apperrors.Wrap(err, "failed to scan subject row")
+		}
+		subjects = append(subjects, subject)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, apperrors.Wrap(err, "error iterating subject rows")
 	}
 
+	return subjects, nil
+}
+
+// CountSubmissionsByFormType returns submission counts grouped by the type of form they came through.
+func (s *Store) CountSubmissionsByFormType() (map[store.FormType]int, error) {
 	rows, err := s.db.Query(`
-SELECT s.id, s.client_id, c.name, s.form_id, f.name, f.type, s.status, s.name, s.email, s.subject, s.message, s.priority, s.ip, s.user_agent, s.created_at
+SELECT f.type, COUNT(*)
 FROM submissions s
-JOIN clients c ON c.id = s.client_id
 JOIN forms f ON f.id = s.form_id
-ORDER BY s.created_at DESC
-LIMIT ? OFFSET ?
-`, limit, offset)
+GROUP BY f.type
+`)
 	if err != nil {
-		return nil, 0, apperrors.Wrap(err, "failed to list submissions")
+		return nil, apperrors.Wrap(err, "failed to count submissions by form type")
 	}
 	defer rows.Close()
 
-	submissions := []store.Submission{}
+	counts := map[store.FormType]int{
+		store.FormTypeSupport: 0,
+		store.FormTypeContact: 0,
+	}
 	for rows.Next() {
-		var submission store.Submission
-		var created string
-		if err := rows.Scan(&submission.ID, &submission.ClientID, &submission.Client, &submission.FormID, &submission.Form, &submission.FormType, &submission.Status, &submission.Name, &submission.Email, &submission.Subject, &submission.Message, &submission.Priority, &submission.IP, &submission.UserAgent, &created); err != nil {
-			return nil, 0, apperrors.Wrap(err, "failed to scan submission row")
+		var formType store.FormType
+		var count int
+		if err := rows.Scan(&formType, &count); err != nil {
+			return nil, apperrors.Wrap(err, "failed to scan form type count")
 		}
-		submission.CreatedAt = parseTime(created)
-		submissions = append(submissions, submission)
+		counts[formType] = count
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, 0, apperrors.Wrap(err, "error iterating submission rows")
+		return nil, apperrors.Wrap(err, "error iterating form type counts")
 	}
 
-	return submissions, total, nil
+	return counts, nil
 }
 
-// FilterSubmissions returns a filtered paginated list of submissions.
-// Filters are applied dynamically based on provided parameters.
-// Empty/zero values are ignored (no filtering for that field).
-func (s *Store) FilterSubmissions(offset, limit int, status string, clientID, formID int64, subjectSearch string) ([]store.Submission, int, error) {
-	// Build dynamic WHERE clause
-	var conditions []string
-	var args []interface{}
+// CountSubmissionsBySource counts submissions per source, reporting untracked rows as "unknown".
+func (s *Store) CountSubmissionsBySource() (map[string]int, error) {
+	rows, err := s.db.Query(`
+SELECT COALESCE(NULLIF(source, ''), 'unknown') AS normalized, COUNT(*)
+FROM submissions
+GROUP BY normalized
+`)
+	if err != nil {
+		return nil, apperrors.Wrap(err, "failed to count submissions by source")
+	}
+	defer rows.Close()
 
-	if status != "" {
-		conditions = append(conditions, "s.status = ?")
-		args = append(args, status)
+	counts := map[string]int{
+		store.SourceWidget:   0,
+		store.SourceAPIJSON:  0,
+		store.SourceFormPost: 0,
 	}
-	if clientID > 0 {
-		conditions = append(conditions, "s.client_id = ?")
-		args = append(args, clientID)
+	for rows.Next() {
+		var source string
+		var count int
+		if err := rows.Scan(&source, &count); err != nil {
+			return nil, apperrors.Wrap(err, "failed to scan source count")
+		}
+		counts[source] = count
 	}
-	if formID > 0 {
-		conditions = append(conditions, "s.form_id = ?")
-		args = append(args, formID)
+
+	if err := rows.Err(); err != nil {
+		return nil, apperrors.Wrap(err, "error iterating source counts")
 	}
-	if subjectSearch != "" {
-		conditions = append(conditions, "s.subject LIKE ?")
-		args = append(args, "%"+subjectSearch+"%")
+
+	return counts, nil
+}
+
+// BusiestForms ranks forms by submission count since the given time, ties broken by form name.
+func (s *Store) BusiestForms(since time.Time, limit int) ([]store.FormActivity, error) {
+	limit = s.formatLimit(limit)
+
+	rows, err := s.db.Query(`
+SELECT f.id, f.name, c.id, c.name, COUNT(*) AS total
+FROM submissions s
+JOIN forms f ON f.id = s.form_id
+JOIN clients c ON c.id = f.client_id
+WHERE s.created_at >= ?
+GROUP BY f.id, f.name, c.id, c.name
+ORDER BY total DESC, f.name ASC, f.id ASC
+LIMIT ?
+`, formatTimeParam(since), limit)
+	if err != nil {
+		return nil, apperrors.Wrap(err, "failed to query busiest forms")
 	}
+	defer rows.Close()
 
-	whereClause := ""
-	if len(conditions) > 0 {
-		whereClause = "WHERE " + strings.Join(conditions, " AND ")
+	forms := []store.FormActivity{}
+	for rows.Next() {
+		var activity store.FormActivity
+		if err := rows.Scan(&activity.FormID, &activity.Form, &activity.ClientID, &activity.Client, &activity.Count); err != nil {
+			return nil, apperrors.Wrap(err, "failed to scan form activity row")
+		}
+		forms = append(forms, activity)
 	}
 
-	// Count total filtered results
-	countQuery := fmt.Sprintf(`SELECT COUNT(*) FROM submissions s %s`, whereClause)
-	var total int
-	if err := s.db.QueryRow(countQuery, args...).Scan(&total); err != nil {
-		return nil, 0, apperrors.Wrap(err, "failed to count filtered submissions")
+	if err := rows.Err(); err != nil {
+		return nil, apperrors.Wrap(err, "error iterating form activity rows")
 	}
 
-	// Get filtered submissions
-	query := fmt.Sprintf(`
-SELECT s.id, s.client_id, c.name, s.form_id, f.name, f.type, s.status, s.name, s.email, s.subject, s.message, s.priority, s.ip, s.user_agent, s.created_at
+	return forms, nil
+}
+
+// FormSubmissionsByWeekday buckets a form's submissions by local weekday.
+// SQL groups by UTC minute, which keeps the result small while still converting
+// correctly for zones with half-hour offsets and across DST changes.
+func (s *Store) FormSubmissionsByWeekday(formID int64, from, to time.Time) ([7]int, error) {
+	var counts [7]int
+
+	loc := from.Location()
+	if from.IsZero() {
+		loc = to.Location()
+	}
+
+	conditions := []string{"form_id = ?"}
+	args := []interface{}{formID}
+	if !from.IsZero() {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, formatTimeParam(from))
+	}
+	if !to.IsZero() {
+		conditions = append(conditions, "created_at < ?")
+		args = append(args, formatTimeParam(to))
+	}
+
+	rows, err := s.db.Query(`
+SELECT strftime('%Y-%m-%d %H:%M:00', created_at) AS minute, COUNT(*)
+FROM submissions
+WHERE `+strings.Join(conditions, " AND ")+`
+GROUP BY minute
+`, args...)
+	if err != nil {
+		return counts, apperrors.Wrapf(err, "failed to count submissions by weekday for form %d", formID)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var minute string
+		var count int
+		if err := rows.Scan(&minute, &count); err != nil {
+			return counts, apperrors.Wrap(err, "failed to scan weekday count")
+		}
+		counts[parseTime(minute).In(loc).Weekday()] += count
+	}
+
+	if err := rows.Err(); err != nil {
+		return counts, apperrors.Wrap(err, "error iterating weekday counts")
+	}
+
+	return counts, nil
+}
+
+// statsTopClients is how many clients SubmissionStats reports in TopClients.
+const statsTopClients = 5
+
+// SubmissionStats aggregates submission counts in SQL for the admin dashboard.
+func (s *Store) SubmissionStats() (store.SubmissionStats, error) {
+	stats := store.SubmissionStats{
+		ByStatus: map[string]int{
+			validator.StatusOpen:       0,
+			validator.StatusInProgress: 0,
+			validator.StatusClosed:     0,
+			validator.StatusSpam:       0,
+		},
+		TopClients: []store.ClientCount{},
+	}
+
+	weekAgo := formatTimeParam(time.Now().AddDate(0, 0, -7))
+	err := s.db.QueryRow(`
+SELECT COUNT(*), COALESCE(SUM(CASE WHEN created_at >= ? THEN 1 ELSE 0 END), 0)
+FROM submissions
+`, weekAgo).Scan(&stats.Total, &stats.LastWeek)
+	if err != nil {
+		return stats, apperrors.Wrap(err, "failed to count submissions")
+	}
+
+	rows, err := s.db.Query(`
+SELECT COALESCE(NULLIF(status, ''), ?) AS normalized, COUNT(*)
+FROM submissions
+GROUP BY normalized
+`, validator.StatusOpen)
+	if err != nil {
+		return stats, apperrors.Wrap(err, "failed to count submissions by status")
+	}
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			rows.Close()
+			return stats, apperrors.Wrap(err, "failed to scan status count")
+		}
+		stats.ByStatus[status] = count
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return stats, apperrors.Wrap(err, "error iterating status counts")
+	}
+
+	stats.ByFormType, err = s.CountSubmissionsByFormType()
+	if err != nil {
+		return stats, err
+	}
+
+	rows, err = s.db.Query(`
+SELECT c.id, c.name, COUNT(*) AS total
 FROM submissions s
 JOIN clients c ON c.id = s.client_id
-JOIN forms f ON f.id = s.form_id
-%s
-ORDER BY s.created_at DESC
-LIMIT ? OFFSET ?
-`, whereClause)
+GROUP BY c.id, c.name
+ORDER BY total DESC, c.name ASC
+LIMIT ?
+`, statsTopClients)
+	if err != nil {
+		return stats, apperrors.Wrap(err, "failed to query top clients")
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var client store.ClientCount
+		if err := rows.Scan(&client.ClientID, &client.Client, &client.Count); err != nil {
+			return stats, apperrors.Wrap(err, "failed to scan client count")
+		}
+		stats.TopClients = append(stats.TopClients, client)
+	}
+	if err := rows.Err(); err != nil {
+		return stats, apperrors.Wrap(err, "error iterating client counts")
+	}
 
-	// Append limit and offset to args
-	queryArgs := append(args, limit, offset)
+	return stats, nil
+}
 
-	rows, err := s.db.Query(query, queryArgs...)
+// MessageLengthDistribution counts messages per length bucket.
+// Bucketing happens in SQL with a CASE expression built from the (validated) bounds.
+func (s *Store) MessageLengthDistribution(buckets []int) (map[int]int, error) {
+	if err := validator.ValidateBuckets(buckets); err != nil {
+		return nil, err
+	}
+
+	// Check the largest bound first so each message lands in the highest bucket it reaches
+	var cases strings.Builder
+	var args []interface{}
+	for i := len(buckets) - 1; i >= 0; i-- {
+		cases.WriteString(" WHEN LENGTH(message) >= ? THEN ?")
+		args = append(args, buckets[i], buckets[i])
+	}
+
+	rows, err := s.db.Query(`
+SELECT bucket, COUNT(*)
+FROM (SELECT CASE`+cases.String()+` ELSE -1 END AS bucket FROM submissions WHERE message IS NOT NULL)
+WHERE bucket >= 0
+GROUP BY bucket
+`, args...)
 	if err != nil {
-		return nil, 0, apperrors.Wrap(err, "failed to filter submissions")
+		return nil, apperrors.Wrap(err, "failed to compute message length distribution")
 	}
 	defer rows.Close()
 
-	submissions := []store.Submission{}
+	distribution := make(map[int]int, len(buckets))
+	for _, bound := range buckets {
+		distribution[bound] = 0
+	}
+	for rows.Next() {
+		var bound, count int
+		if err := rows.Scan(&bound, &count); err != nil {
+			return nil, apperrors.Wrap(err, "failed to scan message length bucket")
+		}
+		distribution[bound] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, apperrors.Wrap(err, "error iterating message length buckets")
+	}
+
+	return distribution, nil
+}
+
+// CreateAdminUser creates a new admin user after validating the username.
+func (s *Store) CreateAdminUser(username, passwordHash string) (store.AdminUser, error) {
+	username = strings.TrimSpace(username)
+	if err := validator.ValidateUsername(username); err != nil {
+		return store.AdminUser{}, err
+	}
+	if passwordHash == "" {
+		return store.AdminUser{}, apperrors.InvalidInputError("password hash", "cannot be empty")
+	}
+
+	result, err := s.db.Exec(`INSERT INTO admin_users (username, password_hash) VALUES (?, ?)`, username, passwordHash)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return store.AdminUser{}, apperrors.ConflictError("admin user "+username, "already exists")
+		}
+		return store.AdminUser{}, apperrors.Wrap(err, "failed to create admin user")
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return store.AdminUser{}, apperrors.Wrap(err, "failed to get admin user ID")
+	}
+
+	return s.getAdminUser(`id = ?`, id)
+}
+
+// ListAdminUsers returns all admin users ordered by username.
+func (s *Store) ListAdminUsers() ([]store.AdminUser, error) {
+	rows, err := s.db.Query(`SELECT id, username, password_hash, created_at FROM admin_users ORDER BY username ASC`)
+	if err != nil {
+		return nil, apperrors.Wrap(err, "failed to list admin users")
+	}
+	defer rows.Close()
+
+	users := []store.AdminUser{}
 	for rows.Next() {
-		var submission store.Submission
+		var user store.AdminUser
 		var created string
-		if err := rows.Scan(&submission.ID, &submission.ClientID, &submission.Client, &submission.FormID, &submission.Form, &submission.FormType, &submission.Status, &submission.Name, &submission.Email, &submission.Subject, &submission.Message, &submission.Priority, &submission.IP, &submission.UserAgent, &created); err != nil {
-			return nil, 0, apperrors.Wrap(err, "failed to scan filtered submission row")
+		if err := rows.Scan(&user.ID, &user.Username, &user.PasswordHash, &created); err != nil {
+			return nil, apperrors.Wrap(err, "failed to scan admin user row")
 		}
-		submission.CreatedAt = parseTime(created)
-		submissions = append(submissions, submission)
+		user.CreatedAt = parseTime(created)
+		users = append(users, user)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, 0, apperrors.Wrap(err, "error iterating filtered submission rows")
+		return nil, apperrors.Wrap(err, "error iterating admin user rows")
 	}
 
-	return submissions, total, nil
+	return users, nil
 }
 
-// GetSubmission retrieves a submission by ID with denormalized client and form data.
-func (s *Store) GetSubmission(id int64) (store.Submission, error) {
-	row := s.db.QueryRow(`
-SELECT s.id, s.client_id, c.name, s.form_id, f.name, f.type, s.status, s.name, s.email, s.subject, s.message, s.priority, s.ip, s.user_agent, s.created_at
-FROM submissions s
-JOIN clients c ON c.id = s.client_id
-JOIN forms f ON f.id = s.form_id
-WHERE s.id = ?
-`, id)
+// GetAdminUserByUsername retrieves an admin user by username.
+func (s *Store) GetAdminUserByUsername(username string) (store.AdminUser, error) {
+	return s.getAdminUser(`username = ?`, strings.TrimSpace(username))
+}
 
-	var submission store.Submission
+// getAdminUser retrieves a single admin user matching the given condition.
+func (s *Store) getAdminUser(condition string, arg interface{}) (store.AdminUser, error) {
+	var user store.AdminUser
 	var created string
-	if err := row.Scan(&submission.ID, &submission.ClientID, &submission.Client, &submission.FormID, &submission.Form, &submission.FormType, &submission.Status, &submission.Name, &submission.Email, &submission.Subject, &submission.Message, &submission.Priority, &submission.IP, &submission.UserAgent, &created); err != nil {
+	row := s.db.QueryRow(`SELECT id, username, password_hash, created_at FROM admin_users WHERE `+condition, arg)
+	if err := row.Scan(&user.ID, &user.Username, &user.PasswordHash, &created); err != nil {
 		if err == sql.ErrNoRows {
-			return store.Submission{}, apperrors.NotFoundError("submission", id)
+			return store.AdminUser{}, apperrors.NotFoundError("admin user", arg)
 		}
-		return store.Submission{}, apperrors.Wrapf(err, "failed to get submission %d", id)
+		return store.AdminUser{}, apperrors.Wrapf(err, "failed to get admin user %v", arg)
 	}
-	submission.CreatedAt = parseTime(created)
-	return submission, nil
+	user.CreatedAt = parseTime(created)
+	return user, nil
 }
 
-// UpdateSubmissionStatus updates the status of a submission after validating it.
-func (s *Store) UpdateSubmissionStatus(id int64, status string) error {
-	// Validate status
-	status = strings.TrimSpace(status)
-	if err := validator.ValidateStatus(status); err != nil {
-		return err
+// CountAdminUsers returns the number of admin users.
+func (s *Store) CountAdminUsers() (int, error) {
+	var total int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM admin_users`).Scan(&total); err != nil {
+		return 0, apperrors.Wrap(err, "failed to count admin users")
 	}
+	return total, nil
+}
 
-	result, err := s.db.Exec(`UPDATE submissions SET status = ? WHERE id = ?`, status, id)
+// DeleteAdminUser permanently deletes an admin user and unassigns their submissions
+// in a single transaction.
+func (s *Store) DeleteAdminUser(id int64) error {
+	tx, err := s.db.Begin()
 	if err != nil {
-		return apperrors.Wrapf(err, "failed to update submission %d status", id)
+		return apperrors.Wrap(err, "failed to begin admin user deletion")
+	}
+	defer tx.Rollback()
+
+	// Release the user's tickets so they show up as unassigned
+	if _, err := tx.Exec(`UPDATE submissions SET assignee = '' WHERE assignee = (SELECT username FROM admin_users WHERE id = ?)`, id); err != nil {
+		return apperrors.Wrapf(err, "failed to unassign submissions for admin user %d", id)
+	}
+
+	result, err := tx.Exec(`DELETE FROM admin_users WHERE id = ?`, id)
+	if err != nil {
+		return apperrors.Wrapf(err, "failed to delete admin user %d", id)
 	}
 
-	// Check if any rows were affected
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
 		return apperrors.Wrap(err, "failed to check rows affected")
 	}
 	if rowsAffected == 0 {
-		return apperrors.NotFoundError("submission", id)
+		return apperrors.NotFoundError("admin user", id)
 	}
 
+	if err := tx.Commit(); err != nil {
+		return apperrors.Wrap(err, "failed to commit admin user deletion")
+	}
 	return nil
 }
 
-// DeleteSubmission permanently deletes a submission.
-func (s *Store) DeleteSubmission(id int64) error {
-	result, err := s.db.Exec(`DELETE FROM submissions WHERE id = ?`, id)
+// AddAuditEntry appends a validated entry to the audit log.
+func (s *Store) AddAuditEntry(entry store.AuditEntry) error {
+	entry.Actor = strings.TrimSpace(entry.Actor)
+	entry.Detail = strings.TrimSpace(entry.Detail)
+	if err := validator.ValidateAuditEntry(entry.Actor, entry.Action, entry.TargetType, entry.Detail); err != nil {
+		return err
+	}
+
+	_, err := s.db.Exec(`
+INSERT INTO audit_log (actor, action, target_type, target_id, detail)
+VALUES (?, ?, ?, ?, ?)
+`, entry.Actor, entry.Action, entry.TargetType, entry.TargetID, entry.Detail)
 	if err != nil {
-		return apperrors.Wrapf(err, "failed to delete submission %d", id)
+		return apperrors.Wrapf(err, "failed to add audit entry for %s %s", entry.TargetType, entry.Action)
 	}
+	return nil
+}
 
-	// Check if any rows were affected
-	rowsAffected, err := result.RowsAffected()
+// ListAuditEntries returns a paginated list of audit log entries, newest first.
+func (s *Store) ListAuditEntries(offset, limit int) ([]store.AuditEntry, int, error) {
+	limit = s.formatLimit(limit)
+	offset = formatOffset(offset)
+
+	var total int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM audit_log`).Scan(&total); err != nil {
+		return nil, 0, apperrors.Wrap(err, "failed to count audit entries")
+	}
+
+	rows, err := s.db.Query(`
+SELECT id, actor, action, target_type, target_id, detail, created_at
+FROM audit_log
+ORDER BY created_at DESC, id DESC
+LIMIT ? OFFSET ?
+`, limit, offset)
 	if err != nil {
-		return apperrors.Wrap(err, "failed to check rows affected")
+		return nil, 0, apperrors.Wrap(err, "failed to list audit entries")
 	}
-	if rowsAffected == 0 {
-		return apperrors.NotFoundError("submission", id)
+	defer rows.Close()
+
+	entries := []store.AuditEntry{}
+	for rows.Next() {
+		var entry store.AuditEntry
+		var created string
+		if err := rows.Scan(&entry.ID, &entry.Actor, &entry.Action, &entry.TargetType, &entry.TargetID, &entry.Detail, &created); err != nil {
+			return nil, 0, apperrors.Wrap(err, "failed to scan audit entry row")
+		}
+		entry.CreatedAt = parseTime(created)
+		entries = append(entries, entry)
 	}
 
-	return nil
+	if err := rows.Err(); err != nil {
+		return nil, 0, apperrors.Wrap(err, "error iterating audit entry rows")
+	}
+
+	return entries, total, nil
+}
+
+// isUniqueViolation reports whether err is a SQLite UNIQUE constraint failure.
+func isUniqueViolation(err error) bool {
+	var sqliteErr sqlite3.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique
+}
+
+// sqliteTimeFormats are the timestamp layouts SQLite and the driver produce, most common first.
+// Fractional seconds are accepted by the plain layouts too, since Go parses a fraction
+// after the seconds field even when the layout has none.
+var sqliteTimeFormats = []string{
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05-0700",
+	"2006-01-02T15:04:05",
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05-0700",
+	"2006-01-02 15:04:05 -0700 MST", // time.Time.String, stored when a time.Time was bound as text
+	"2006-01-02 15:04",
+	"2006-01-02T15:04",
+	"2006-01-02",
 }
 
 // parseTime attempts to parse a timestamp string from SQLite.
-// It tries multiple formats: SQLite datetime format and RFC3339.
-// Returns zero time if parsing fails.
+// It tries the formats in sqliteTimeFormats, with or without fractional seconds and
+// timezone offsets, and returns the result in UTC. Values without an offset are UTC,
+// like CURRENT_TIMESTAMP. Returns zero time if parsing fails.
 func parseTime(value string) time.Time {
+	value = strings.TrimSpace(value)
 	if value == "" {
 		return time.Time{}
 	}
 
-	// Try SQLite datetime format first (most common)
-	parsed, err := time.Parse("2006-01-02 15:04:05", value)
-	if err == nil {
-		return parsed
-	}
-
-	// Try RFC3339 format as fallback
-	parsed, err = time.Parse(time.RFC3339, value)
-	if err == nil {
-		return parsed
+	for _, layout := range sqliteTimeFormats {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return parsed.UTC()
+		}
 	}
 
 	// Return zero time if all parsing attempts fail
 	return time.Time{}
 }
 
+// formatTimeParam formats a time as a UTC string comparable with SQLite's CURRENT_TIMESTAMP values.
+func formatTimeParam(t time.Time) string {
+	return t.UTC().Format("2006-01-02 15:04:05")
+}
+
+// defaultPageSize is the list limit used until SetPageSize is called.
+const defaultPageSize = 20
+
+// Form creation limit used until SetFormCreateLimit is called.
+const (
+	defaultFormCreateLimit  = 50
+	defaultFormCreateWindow = time.Hour
+)
+
 // formatLimit ensures limit is within valid bounds for pagination.
-// Returns default page size (20) if limit is <= 0.
-func formatLimit(limit int) int {
-	const defaultPageSize = 20
+// Returns the store's page size if limit is <= 0.
+func (s *Store) formatLimit(limit int) int {
 	if limit <= 0 {
-		return defaultPageSize
+		return s.pageSize
 	}
 	return limit
 }
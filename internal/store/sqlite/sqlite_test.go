@@ -0,0 +1,1687 @@
+package sqlite
+
+import (
+	"encoding/base64"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	apperrors "ticketd/internal/errors"
+	"ticketd/internal/store"
+	"ticketd/internal/validator"
+)
+
+// newTestStore returns a migrated store backed by a database file in a temporary directory.
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+	s, err := New(filepath.Join(t.TempDir(), "ticketd.db"), 0)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	if err := s.Migrate(); err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+	return s
+}
+
+// createTestClient creates a client with the given allowed domain.
+func createTestClient(t *testing.T, s *Store, domain string) store.Client {
+	t.Helper()
+	client, err := s.CreateClient("Client "+domain, domain)
+	if err != nil {
+		t.Fatalf("CreateClient(%q): %v", domain, err)
+	}
+	return client
+}
+
+// createTestForm creates a form of the given type for a client.
+func createTestForm(t *testing.T, s *Store, clientID int64, formType store.FormType) store.Form {
+	t.Helper()
+	form, err := s.CreateForm(clientID, store.FormInput{Name: fmt.Sprintf("%s form", formType), Type: formType})
+	if err != nil {
+		t.Fatalf("CreateForm: %v", err)
+	}
+	return form
+}
+
+// createTestSubmission creates a submission to a form with valid defaults for every field
+// input leaves empty.
+func createTestSubmission(t *testing.T, s *Store, formID int64, input store.SubmissionInput) store.Submission {
+	t.Helper()
+	if input.Name == "" {
+		input.Name = "Jane Doe"
+	}
+	if input.Email == "" {
+		input.Email = "jane@example.com"
+	}
+	if input.Subject == "" {
+		input.Subject = "Help"
+	}
+	if input.Message == "" {
+		input.Message = "Something is broken."
+	}
+	submission, err := s.CreateSubmission(formID, input)
+	if err != nil {
+		t.Fatalf("CreateSubmission: %v", err)
+	}
+	return submission
+}
+
+// setCreatedAt overwrites the stored created_at of a submission with a raw value.
+func setCreatedAt(t *testing.T, s *Store, id int64, value string) {
+	t.Helper()
+	if _, err := s.db.Exec(`UPDATE submissions SET created_at = ? WHERE id = ?`, value, id); err != nil {
+		t.Fatalf("set created_at of submission %d: %v", id, err)
+	}
+}
+
+func TestTopSubjects(t *testing.T) {
+	s := newTestStore(t)
+	client := createTestClient(t, s, "example.com")
+	form := createTestForm(t, s, client.ID, store.FormTypeSupport)
+
+	for _, subject := range []string{"Login issue", "login ISSUE", "  Login issue ", "Billing", "Billing", "Refund"} {
+		createTestSubmission(t, s, form.ID, store.SubmissionInput{Subject: subject})
+	}
+
+	got, err := s.TopSubjects(2, time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("TopSubjects: %v", err)
+	}
+	want := []store.SubjectCount{{Subject: "login issue", Count: 3}, {Subject: "billing", Count: 2}}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("TopSubjects(2) = %v, want %v", got, want)
+	}
+
+	all, err := s.TopSubjects(10, time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("TopSubjects: %v", err)
+	}
+	if len(all) != 3 {
+		t.Errorf("TopSubjects(10) returned %d subjects, want 3: %v", len(all), all)
+	}
+}
+
+func TestTopSubjectsRange(t *testing.T) {
+	s := newTestStore(t)
+	client := createTestClient(t, s, "example.com")
+	form := createTestForm(t, s, client.ID, store.FormTypeSupport)
+
+	old := createTestSubmission(t, s, form.ID, store.SubmissionInput{Subject: "Old"})
+	setCreatedAt(t, s, old.ID, "2024-01-01 12:00:00")
+	createTestSubmission(t, s, form.ID, store.SubmissionInput{Subject: "New"})
+
+	got, err := s.TopSubjects(10, time.Now().Add(-time.Hour), time.Time{})
+	if err != nil {
+		t.Fatalf("TopSubjects: %v", err)
+	}
+	if len(got) != 1 || got[0].Subject != "new" {
+		t.Errorf("TopSubjects since an hour ago = %v, want only \"new\"", got)
+	}
+
+	got, err = s.TopSubjects(10, time.Time{}, time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("TopSubjects: %v", err)
+	}
+	if len(got) != 1 || got[0].Subject != "old" {
+		t.Errorf("TopSubjects before June 2024 = %v, want only \"old\"", got)
+	}
+}
+
+func TestCountSubmissionsByFormType(t *testing.T) {
+	s := newTestStore(t)
+
+	counts, err := s.CountSubmissionsByFormType()
+	if err != nil {
+		t.Fatalf("CountSubmissionsByFormType on empty database: %v", err)
+	}
+	want := map[store.FormType]int{store.FormTypeSupport: 0, store.FormTypeContact: 0}
+	if fmt.Sprint(counts) != fmt.Sprint(want) {
+		t.Errorf("empty database: got %v, want %v", counts, want)
+	}
+
+	client := createTestClient(t, s, "example.com")
+	support := createTestForm(t, s, client.ID, store.FormTypeSupport)
+	contact := createTestForm(t, s, client.ID, store.FormTypeContact)
+	for range 3 {
+		createTestSubmission(t, s, support.ID, store.SubmissionInput{})
+	}
+	createTestSubmission(t, s, contact.ID, store.SubmissionInput{})
+
+	counts, err = s.CountSubmissionsByFormType()
+	if err != nil {
+		t.Fatalf("CountSubmissionsByFormType: %v", err)
+	}
+	want = map[store.FormType]int{store.FormTypeSupport: 3, store.FormTypeContact: 1}
+	if fmt.Sprint(counts) != fmt.Sprint(want) {
+		t.Errorf("got %v, want %v", counts, want)
+	}
+}
+
+func TestListFormsByType(t *testing.T) {
+	s := newTestStore(t)
+	alpha := createTestClient(t, s, "alpha.example")
+	beta := createTestClient(t, s, "beta.example")
+	for _, input := range []struct {
+		clientID int64
+		name     string
+		formType store.FormType
+	}{
+		{beta.ID, "B support", store.FormTypeSupport},
+		{alpha.ID, "A support 2", store.FormTypeSupport},
+		{alpha.ID, "A contact", store.FormTypeContact},
+		{alpha.ID, "A support 1", store.FormTypeSupport},
+	} {
+		if _, err := s.CreateForm(input.clientID, store.FormInput{Name: input.name, Type: input.formType}); err != nil {
+			t.Fatalf("CreateForm(%q): %v", input.name, err)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		formType  store.FormType
+		offset    int
+		limit     int
+		wantNames []string
+		wantTotal int
+	}{
+		{name: "support", formType: store.FormTypeSupport, limit: 10, wantNames: []string{"A support 1", "A support 2", "B support"}, wantTotal: 3},
+		{name: "contact", formType: store.FormTypeContact, limit: 10, wantNames: []string{"A contact"}, wantTotal: 1},
+		{name: "all types", limit: 10, wantNames: []string{"A contact", "A support 1", "A support 2", "B support"}, wantTotal: 4},
+		{name: "first page", formType: store.FormTypeSupport, limit: 2, wantNames: []string{"A support 1", "A support 2"}, wantTotal: 3},
+		{name: "second page", formType: store.FormTypeSupport, offset: 2, limit: 2, wantNames: []string{"B support"}, wantTotal: 3},
+		{name: "past the end", formType: store.FormTypeSupport, offset: 4, limit: 2, wantNames: []string{}, wantTotal: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forms, total, err := s.ListFormsByType(tt.formType, tt.offset, tt.limit)
+			if err != nil {
+				t.Fatalf("ListFormsByType: %v", err)
+			}
+			if total != tt.wantTotal {
+				t.Errorf("total = %d, want %d", total, tt.wantTotal)
+			}
+			names := []string{}
+			for _, form := range forms {
+				names = append(names, form.Name)
+				if tt.formType != "" && form.Type != tt.formType {
+					t.Errorf("form %q has type %q, want %q", form.Name, form.Type, tt.formType)
+				}
+				if want := map[int64]string{alpha.ID: alpha.Name, beta.ID: beta.Name}[form.ClientID]; form.Client != want {
+					t.Errorf("form %q has client name %q, want %q", form.Name, form.Client, want)
+				}
+			}
+			if fmt.Sprint(names) != fmt.Sprint(tt.wantNames) {
+				t.Errorf("forms = %v, want %v", names, tt.wantNames)
+			}
+		})
+	}
+
+	if _, _, err := s.ListFormsByType("survey", 0, 10); !apperrors.IsInvalidInput(err) {
+		t.Errorf("ListFormsByType(survey) error = %v, want invalid input", err)
+	}
+}
+
+func TestCreateSubmissionUniqueEmail(t *testing.T) {
+	s := newTestStore(t)
+	client := createTestClient(t, s, "example.com")
+	oneShot, err := s.CreateForm(client.ID, store.FormInput{Name: "Register interest", Type: store.FormTypeContact, UniqueEmail: true})
+	if err != nil {
+		t.Fatalf("CreateForm: %v", err)
+	}
+	open := createTestForm(t, s, client.ID, store.FormTypeContact)
+
+	createTestSubmission(t, s, oneShot.ID, store.SubmissionInput{Email: "jane@example.com"})
+
+	tests := []struct {
+		name         string
+		formID       int64
+		email        string
+		wantConflict bool
+	}{
+		{name: "same email", formID: oneShot.ID, email: "jane@example.com", wantConflict: true},
+		{name: "same email in another case", formID: oneShot.ID, email: "Jane@Example.COM", wantConflict: true},
+		{name: "other email", formID: oneShot.ID, email: "john@example.com"},
+		{name: "same email on a form without the flag", formID: open.ID, email: "jane@example.com"},
+		{name: "same email again on a form without the flag", formID: open.ID, email: "jane@example.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.CreateSubmission(tt.formID, store.SubmissionInput{Name: "Jane Doe", Email: tt.email, Subject: "Hi", Message: "Count me in."})
+			if tt.wantConflict && !apperrors.IsConflict(err) {
+				t.Errorf("CreateSubmission(%q) error = %v, want conflict", tt.email, err)
+			}
+			if !tt.wantConflict && err != nil {
+				t.Errorf("CreateSubmission(%q): %v", tt.email, err)
+			}
+		})
+	}
+}
+
+func TestCreateSubmissionUniqueEmailConcurrent(t *testing.T) {
+	s := newTestStore(t)
+	client := createTestClient(t, s, "example.com")
+	form, err := s.CreateForm(client.ID, store.FormInput{Name: "Register interest", Type: store.FormTypeContact, UniqueEmail: true})
+	if err != nil {
+		t.Fatalf("CreateForm: %v", err)
+	}
+
+	const attempts = 10
+	errs := make(chan error, attempts)
+	var wg sync.WaitGroup
+	for range attempts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := s.CreateSubmission(form.ID, store.SubmissionInput{Name: "Jane Doe", Email: "jane@example.com", Subject: "Hi", Message: "Count me in."})
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	created := 0
+	for err := range errs {
+		switch {
+		case err == nil:
+			created++
+		case !apperrors.IsConflict(err):
+			t.Errorf("CreateSubmission: %v, want nil or conflict", err)
+		}
+	}
+	if created != 1 {
+		t.Errorf("%d concurrent submissions created, want 1", created)
+	}
+}
+
+func TestMessageLengthDistribution(t *testing.T) {
+	s := newTestStore(t)
+	client := createTestClient(t, s, "example.com")
+	form := createTestForm(t, s, client.ID, store.FormTypeSupport)
+	for _, length := range []int{1, 9, 10, 99, 100, 5000} {
+		createTestSubmission(t, s, form.ID, store.SubmissionInput{Message: strings.Repeat("x", length)})
+	}
+
+	tests := []struct {
+		name    string
+		buckets []int
+		want    map[int]int
+	}{
+		{name: "from zero", buckets: []int{0, 10, 100}, want: map[int]int{0: 2, 10: 2, 100: 2}},
+		{name: "shorter than the first bucket", buckets: []int{10, 100}, want: map[int]int{10: 2, 100: 2}},
+		{name: "empty buckets", buckets: []int{0, 10000, 20000}, want: map[int]int{0: 6, 10000: 0, 20000: 0}},
+		{name: "single bucket", buckets: []int{100}, want: map[int]int{100: 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.MessageLengthDistribution(tt.buckets)
+			if err != nil {
+				t.Fatalf("MessageLengthDistribution(%v): %v", tt.buckets, err)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("MessageLengthDistribution(%v) = %v, want %v", tt.buckets, got, tt.want)
+			}
+		})
+	}
+
+	for _, buckets := range [][]int{nil, {-1, 10}, {10, 10}, {100, 10}} {
+		if _, err := s.MessageLengthDistribution(buckets); !apperrors.IsInvalidInput(err) {
+			t.Errorf("MessageLengthDistribution(%v) error = %v, want invalid input", buckets, err)
+		}
+	}
+}
+
+func TestCreateSubmissionEmailValid(t *testing.T) {
+	s := newTestStore(t)
+	client := createTestClient(t, s, "example.com")
+	form := createTestForm(t, s, client.ID, store.FormTypeSupport)
+
+	tests := []struct {
+		email string
+		want  bool
+	}{
+		{email: "jane@example.com", want: true},
+		{email: "jane.doe+tickets@mail.example.co.uk", want: true},
+		{email: "bob@localhost", want: false},
+		{email: "Bob <bob@example.com>", want: false},
+		{email: "bob@example.123", want: false},
+		{email: "bob@-example.com", want: false},
+	}
+	invalid := map[int64]bool{}
+	for _, tt := range tests {
+		submission := createTestSubmission(t, s, form.ID, store.SubmissionInput{Email: tt.email})
+		if submission.EmailValid != tt.want {
+			t.Errorf("EmailValid for %q = %t, want %t", tt.email, submission.EmailValid, tt.want)
+		}
+		if !tt.want {
+			invalid[submission.ID] = true
+		}
+	}
+
+	listed, err := s.ListSubmissionsWithInvalidEmail()
+	if err != nil {
+		t.Fatalf("ListSubmissionsWithInvalidEmail: %v", err)
+	}
+	filtered, _, err := s.FilterSubmissions(0, 100, store.SubmissionFilter{InvalidEmail: true})
+	if err != nil {
+		t.Fatalf("FilterSubmissions: %v", err)
+	}
+	for name, submissions := range map[string][]store.Submission{"ListSubmissionsWithInvalidEmail": listed, "FilterSubmissions": filtered} {
+		if len(submissions) != len(invalid) {
+			t.Errorf("%s returned %d submissions, want %d", name, len(submissions), len(invalid))
+		}
+		for _, submission := range submissions {
+			if !invalid[submission.ID] {
+				t.Errorf("%s returned submission %d with email %q", name, submission.ID, submission.Email)
+			}
+		}
+	}
+}
+
+func TestBusiestForms(t *testing.T) {
+	s := newTestStore(t)
+	client := createTestClient(t, s, "example.com")
+	forms := map[string]store.Form{}
+	for _, name := range []string{"Quiet", "Busy", "Steady", "Stale"} {
+		form, err := s.CreateForm(client.ID, store.FormInput{Name: name, Type: store.FormTypeSupport})
+		if err != nil {
+			t.Fatalf("CreateForm(%q): %v", name, err)
+		}
+		forms[name] = form
+	}
+	for name, count := range map[string]int{"Quiet": 1, "Busy": 3, "Steady": 1} {
+		for range count {
+			createTestSubmission(t, s, forms[name].ID, store.SubmissionInput{})
+		}
+	}
+	// Stale has the most submissions overall, but all of them before the window
+	for range 4 {
+		old := createTestSubmission(t, s, forms["Stale"].ID, store.SubmissionInput{})
+		setCreatedAt(t, s, old.ID, "2024-01-01 12:00:00")
+	}
+
+	got, err := s.BusiestForms(time.Now().Add(-time.Hour), 10)
+	if err != nil {
+		t.Fatalf("BusiestForms: %v", err)
+	}
+	var ranking []string
+	for _, activity := range got {
+		ranking = append(ranking, fmt.Sprintf("%s:%d", activity.Form, activity.Count))
+		if activity.Client != client.Name || activity.ClientID != client.ID {
+			t.Errorf("form %q has client %d %q, want %d %q", activity.Form, activity.ClientID, activity.Client, client.ID, client.Name)
+		}
+	}
+	// Ties are broken by form name
+	if want := "[Busy:3 Quiet:1 Steady:1]"; fmt.Sprint(ranking) != want {
+		t.Errorf("BusiestForms in the last hour = %v, want %s", ranking, want)
+	}
+
+	got, err = s.BusiestForms(time.Time{}, 2)
+	if err != nil {
+		t.Fatalf("BusiestForms: %v", err)
+	}
+	if len(got) != 2 || got[0].Form != "Stale" || got[1].Form != "Busy" {
+		t.Errorf("BusiestForms of all time, limit 2 = %v, want Stale then Busy", got)
+	}
+}
+
+func TestFormSubmissionsByWeekday(t *testing.T) {
+	s := newTestStore(t)
+	client := createTestClient(t, s, "example.com")
+	form := createTestForm(t, s, client.ID, store.FormTypeSupport)
+	other := createTestForm(t, s, client.ID, store.FormTypeContact)
+
+	// 2024-01-01 is a Monday
+	for _, created := range []string{"2024-01-01 09:00:00", "2024-01-01 23:30:00", "2024-01-06 12:00:00", "2024-01-07 12:00:00", "2024-02-05 12:00:00"} {
+		submission := createTestSubmission(t, s, form.ID, store.SubmissionInput{})
+		setCreatedAt(t, s, submission.ID, created)
+	}
+	otherSubmission := createTestSubmission(t, s, other.ID, store.SubmissionInput{})
+	setCreatedAt(t, s, otherSubmission.ID, "2024-01-01 09:00:00")
+
+	plusTwo := time.FixedZone("UTC+2", 2*60*60)
+	tests := []struct {
+		name     string
+		from, to time.Time
+		want     [7]int // Sunday first, like time.Weekday
+	}{
+		{
+			name: "all time in UTC",
+			to:   time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			want: [7]int{time.Sunday: 1, time.Monday: 3, time.Saturday: 1},
+		},
+		{
+			name: "January in UTC",
+			from: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			to:   time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+			want: [7]int{time.Sunday: 1, time.Monday: 2, time.Saturday: 1},
+		},
+		{
+			// 23:30 UTC on Monday is already Tuesday two hours east
+			name: "January two hours east of UTC",
+			from: time.Date(2024, 1, 1, 0, 0, 0, 0, plusTwo),
+			to:   time.Date(2024, 2, 1, 0, 0, 0, 0, plusTwo),
+			want: [7]int{time.Sunday: 1, time.Monday: 1, time.Tuesday: 1, time.Saturday: 1},
+		},
+		{
+			name: "empty range",
+			from: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+			to:   time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.FormSubmissionsByWeekday(form.ID, tt.from, tt.to)
+			if err != nil {
+				t.Fatalf("FormSubmissionsByWeekday: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("FormSubmissionsByWeekday = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBulkCloseSubmissionsOlderThan(t *testing.T) {
+	s := newTestStore(t)
+	client := createTestClient(t, s, "example.com")
+	form := createTestForm(t, s, client.ID, store.FormTypeSupport)
+
+	// newSubmission creates a submission with the given status and creation time.
+	newSubmission := func(status, created string) store.Submission {
+		t.Helper()
+		submission := createTestSubmission(t, s, form.ID, store.SubmissionInput{})
+		if status != validator.StatusOpen {
+			if err := s.UpdateSubmissionStatus(submission.ID, status, "agent"); err != nil {
+				t.Fatalf("UpdateSubmissionStatus: %v", err)
+			}
+		}
+		setCreatedAt(t, s, submission.ID, created)
+		return submission
+	}
+	oldOpen := newSubmission(validator.StatusOpen, "2024-01-01 12:00:00")
+	oldInProgress := newSubmission(validator.StatusInProgress, "2024-01-02 12:00:00")
+	oldClosed := newSubmission(validator.StatusClosed, "2024-01-03 12:00:00")
+	oldSpam := newSubmission(validator.StatusSpam, "2024-01-04 12:00:00")
+	oldArchived := newSubmission(validator.StatusOpen, "2024-01-05 12:00:00")
+	if err := s.ArchiveSubmission(oldArchived.ID); err != nil {
+		t.Fatalf("ArchiveSubmission: %v", err)
+	}
+	recent := newSubmission(validator.StatusOpen, "2024-06-01 12:00:00")
+
+	closed, err := s.BulkCloseSubmissionsOlderThan(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), "stale queue cleanup")
+	if err != nil {
+		t.Fatalf("BulkCloseSubmissionsOlderThan: %v", err)
+	}
+	if closed != 2 {
+		t.Errorf("closed %d submissions, want 2", closed)
+	}
+
+	tests := []struct {
+		name       string
+		submission store.Submission
+		wantStatus string
+		wantFrom   string // Status the bulk action closed the submission from, empty if untouched
+	}{
+		{name: "old open", submission: oldOpen, wantStatus: validator.StatusClosed, wantFrom: validator.StatusOpen},
+		{name: "old in progress", submission: oldInProgress, wantStatus: validator.StatusClosed, wantFrom: validator.StatusInProgress},
+		{name: "old closed", submission: oldClosed, wantStatus: validator.StatusClosed},
+		{name: "old spam", submission: oldSpam, wantStatus: validator.StatusSpam},
+		{name: "old archived", submission: oldArchived, wantStatus: validator.StatusOpen},
+		{name: "recent open", submission: recent, wantStatus: validator.StatusOpen},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.GetSubmission(tt.submission.ID)
+			if err != nil {
+				t.Fatalf("GetSubmission: %v", err)
+			}
+			if got.Status != tt.wantStatus {
+				t.Errorf("status = %q, want %q", got.Status, tt.wantStatus)
+			}
+
+			history, err := s.GetStatusHistory(tt.submission.ID)
+			if err != nil {
+				t.Fatalf("GetStatusHistory: %v", err)
+			}
+			var bulk []store.StatusChange
+			for _, change := range history {
+				if change.Actor == systemNoteAuthor {
+					bulk = append(bulk, change)
+				}
+			}
+			notes, err := s.ListSubmissionNotes(tt.submission.ID)
+			if err != nil {
+				t.Fatalf("ListSubmissionNotes: %v", err)
+			}
+
+			if tt.wantFrom == "" {
+				if len(bulk) != 0 || len(notes) != 0 {
+					t.Errorf("untouched submission got %d system history entries and %d notes", len(bulk), len(notes))
+				}
+				return
+			}
+			if len(bulk) != 1 || bulk[0].FromStatus != tt.wantFrom || bulk[0].ToStatus != validator.StatusClosed {
+				t.Errorf("system history = %+v, want one change from %s to CLOSED", bulk, tt.wantFrom)
+			}
+			if len(notes) != 1 || notes[0].Author != systemNoteAuthor || !strings.Contains(notes[0].Body, "stale queue cleanup") {
+				t.Errorf("notes = %+v, want one system note with the reason", notes)
+			}
+		})
+	}
+
+	if _, err := s.BulkCloseSubmissionsOlderThan(time.Time{}, "cleanup"); !apperrors.IsInvalidInput(err) {
+		t.Errorf("zero time: error = %v, want invalid input", err)
+	}
+}
+
+func TestListSubmissionsModifiedBy(t *testing.T) {
+	s := newTestStore(t)
+	client := createTestClient(t, s, "example.com")
+	form := createTestForm(t, s, client.ID, store.FormTypeSupport)
+	byStatus := createTestSubmission(t, s, form.ID, store.SubmissionInput{})
+	byAssign := createTestSubmission(t, s, form.ID, store.SubmissionInput{})
+	byBulk := createTestSubmission(t, s, form.ID, store.SubmissionInput{})
+	takenOver := createTestSubmission(t, s, form.ID, store.SubmissionInput{})
+	untouched := createTestSubmission(t, s, form.ID, store.SubmissionInput{})
+
+	if err := s.UpdateSubmissionStatus(byStatus.ID, validator.StatusInProgress, "alice"); err != nil {
+		t.Fatalf("UpdateSubmissionStatus: %v", err)
+	}
+	if err := s.AssignSubmission(byAssign.ID, "bob", "alice"); err != nil {
+		t.Fatalf("AssignSubmission: %v", err)
+	}
+	if _, err := s.BulkUpdateStatus([]int64{byBulk.ID, takenOver.ID}, validator.StatusClosed, "alice"); err != nil {
+		t.Fatalf("BulkUpdateStatus: %v", err)
+	}
+	// The last change wins
+	if err := s.UpdateSubmissionStatus(takenOver.ID, validator.StatusOpen, "bob"); err != nil {
+		t.Fatalf("UpdateSubmissionStatus: %v", err)
+	}
+
+	want := map[int64]string{byStatus.ID: "alice", byAssign.ID: "alice", byBulk.ID: "alice", takenOver.ID: "bob", untouched.ID: ""}
+	for id, admin := range want {
+		submission, err := s.GetSubmission(id)
+		if err != nil {
+			t.Fatalf("GetSubmission(%d): %v", id, err)
+		}
+		if submission.UpdatedBy != admin {
+			t.Errorf("submission %d updated_by = %q, want %q", id, submission.UpdatedBy, admin)
+		}
+	}
+
+	tests := []struct {
+		admin     string
+		offset    int
+		limit     int
+		wantCount int
+		wantTotal int
+	}{
+		{admin: "alice", limit: 10, wantCount: 3, wantTotal: 3},
+		{admin: " alice ", limit: 10, wantCount: 3, wantTotal: 3},
+		{admin: "alice", limit: 2, wantCount: 2, wantTotal: 3},
+		{admin: "alice", offset: 2, limit: 2, wantCount: 1, wantTotal: 3},
+		{admin: "bob", limit: 10, wantCount: 1, wantTotal: 1},
+		{admin: "carol", limit: 10, wantCount: 0, wantTotal: 0},
+	}
+	for _, tt := range tests {
+		submissions, total, err := s.ListSubmissionsModifiedBy(tt.admin, tt.offset, tt.limit)
+		if err != nil {
+			t.Fatalf("ListSubmissionsModifiedBy(%q): %v", tt.admin, err)
+		}
+		if len(submissions) != tt.wantCount || total != tt.wantTotal {
+			t.Errorf("ListSubmissionsModifiedBy(%q, %d, %d) = %d submissions of %d, want %d of %d",
+				tt.admin, tt.offset, tt.limit, len(submissions), total, tt.wantCount, tt.wantTotal)
+		}
+		for _, submission := range submissions {
+			if submission.UpdatedBy != strings.TrimSpace(tt.admin) {
+				t.Errorf("ListSubmissionsModifiedBy(%q) returned submission %d updated by %q", tt.admin, submission.ID, submission.UpdatedBy)
+			}
+		}
+	}
+
+	if _, _, err := s.ListSubmissionsModifiedBy("", 0, 10); !apperrors.IsInvalidInput(err) {
+		t.Errorf("empty admin: error = %v, want invalid input", err)
+	}
+}
+
+func TestCreateSubmissionNormalizesPhone(t *testing.T) {
+	s := newTestStore(t)
+	s.SetPhoneRegion("US")
+	client := createTestClient(t, s, "example.com")
+	form := createTestForm(t, s, client.ID, store.FormTypeSupport)
+
+	normalized := createTestSubmission(t, s, form.ID, store.SubmissionInput{Phone: "(555) 123-4567"})
+	if normalized.Phone != "(555) 123-4567" || normalized.PhoneE164 != "+15551234567" {
+		t.Errorf("phone = %q, %q, want the original and +15551234567", normalized.Phone, normalized.PhoneE164)
+	}
+	raw := createTestSubmission(t, s, form.ID, store.SubmissionInput{Phone: "123-4567"})
+	if raw.Phone != "123-4567" || raw.PhoneE164 != "" {
+		t.Errorf("phone = %q, %q, want the original without an E.164 form", raw.Phone, raw.PhoneE164)
+	}
+}
+
+func TestStatusHistory(t *testing.T) {
+	s := newTestStore(t)
+	client := createTestClient(t, s, "example.com")
+	form := createTestForm(t, s, client.ID, store.FormTypeSupport)
+	submission := createTestSubmission(t, s, form.ID, store.SubmissionInput{})
+	other := createTestSubmission(t, s, form.ID, store.SubmissionInput{})
+
+	changes := []struct {
+		status string
+		actor  string
+	}{
+		{validator.StatusInProgress, "alice"},
+		{validator.StatusInProgress, "alice"}, // Unchanged, not recorded
+		{validator.StatusClosed, "bob"},
+		{validator.StatusOpen, "alice"}, // Reopened
+	}
+	for _, change := range changes {
+		if err := s.UpdateSubmissionStatus(submission.ID, change.status, change.actor); err != nil {
+			t.Fatalf("UpdateSubmissionStatus(%s): %v", change.status, err)
+		}
+	}
+	if err := s.UpdateSubmissionStatus(other.ID, validator.StatusClosed, "carol"); err != nil {
+		t.Fatalf("UpdateSubmissionStatus: %v", err)
+	}
+
+	history, err := s.GetStatusHistory(submission.ID)
+	if err != nil {
+		t.Fatalf("GetStatusHistory: %v", err)
+	}
+	var got []string
+	for _, change := range history {
+		got = append(got, fmt.Sprintf("%s->%s by %s", change.FromStatus, change.ToStatus, change.Actor))
+		if change.SubmissionID != submission.ID || change.CreatedAt.IsZero() {
+			t.Errorf("change %+v: want submission %d and a creation time", change, submission.ID)
+		}
+	}
+	want := []string{"OPEN->IN_PROGRESS by alice", "IN_PROGRESS->CLOSED by bob", "CLOSED->OPEN by alice"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("history = %v, want %v", got, want)
+	}
+
+	reopened, err := s.GetSubmission(submission.ID)
+	if err != nil {
+		t.Fatalf("GetSubmission: %v", err)
+	}
+	if reopened.Status != validator.StatusOpen {
+		t.Errorf("status after reopening = %q, want %q", reopened.Status, validator.StatusOpen)
+	}
+
+	if err := s.UpdateSubmissionStatus(9999, validator.StatusClosed, "alice"); !apperrors.IsNotFound(err) {
+		t.Errorf("unknown submission: error = %v, want not found", err)
+	}
+	if history, err := s.GetStatusHistory(9999); err != nil || len(history) != 0 {
+		t.Errorf("GetStatusHistory(unknown) = %v, %v, want empty", history, err)
+	}
+}
+
+func TestCreateFormRateLimit(t *testing.T) {
+	s := newTestStore(t)
+	s.SetFormCreateLimit(3, time.Hour)
+	client := createTestClient(t, s, "example.com")
+	other := createTestClient(t, s, "other.example")
+
+	var first store.Form
+	for i := range 3 {
+		form, err := s.CreateForm(client.ID, store.FormInput{Name: fmt.Sprintf("Form %d", i), Type: store.FormTypeContact})
+		if err != nil {
+			t.Fatalf("CreateForm %d within the limit: %v", i, err)
+		}
+		if i == 0 {
+			first = form
+		}
+	}
+	if _, err := s.CreateForm(client.ID, store.FormInput{Name: "One too many", Type: store.FormTypeContact}); !apperrors.IsRateLimited(err) {
+		t.Fatalf("CreateForm over the limit: error = %v, want rate limited", err)
+	}
+	if _, err := s.CloneForm(first.ID); !apperrors.IsRateLimited(err) {
+		t.Errorf("CloneForm over the limit: error = %v, want rate limited", err)
+	}
+	// Other clients have their own limit
+	if _, err := s.CreateForm(other.ID, store.FormInput{Name: "Other", Type: store.FormTypeContact}); err != nil {
+		t.Errorf("CreateForm for another client: %v", err)
+	}
+
+	count, err := s.CountFormsCreatedSince(client.ID, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("CountFormsCreatedSince: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("CountFormsCreatedSince = %d, want 3", count)
+	}
+
+	// Forms created before the window no longer count
+	if _, err := s.db.Exec(`UPDATE forms SET created_at = ? WHERE id = ?`, "2024-01-01 12:00:00", first.ID); err != nil {
+		t.Fatalf("set form created_at: %v", err)
+	}
+	if _, err := s.CreateForm(client.ID, store.FormInput{Name: "After the window", Type: store.FormTypeContact}); err != nil {
+		t.Errorf("CreateForm once an older form left the window: %v", err)
+	}
+
+	s.SetFormCreateLimit(0, time.Hour)
+	if _, err := s.CreateForm(client.ID, store.FormInput{Name: "Unlimited", Type: store.FormTypeContact}); err != nil {
+		t.Errorf("CreateForm with the limit disabled: %v", err)
+	}
+}
+
+func TestMigrateNormalizesLegacyInProgress(t *testing.T) {
+	s := newTestStore(t)
+	client := createTestClient(t, s, "example.com")
+	form := createTestForm(t, s, client.ID, store.FormTypeSupport)
+	legacy := createTestSubmission(t, s, form.ID, store.SubmissionInput{})
+	lower := createTestSubmission(t, s, form.ID, store.SubmissionInput{})
+	closed := createTestSubmission(t, s, form.ID, store.SubmissionInput{})
+	for id, status := range map[int64]string{legacy.ID: "IN PROGRESS", lower.ID: " in progress ", closed.ID: validator.StatusClosed} {
+		if _, err := s.db.Exec(`UPDATE submissions SET status = ? WHERE id = ?`, status, id); err != nil {
+			t.Fatalf("set status: %v", err)
+		}
+	}
+
+	if err := s.Migrate(); err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+	for id, want := range map[int64]string{legacy.ID: validator.StatusInProgress, lower.ID: validator.StatusInProgress, closed.ID: validator.StatusClosed} {
+		submission, err := s.GetSubmission(id)
+		if err != nil {
+			t.Fatalf("GetSubmission: %v", err)
+		}
+		if submission.Status != want {
+			t.Errorf("submission %d status = %q, want %q", id, submission.Status, want)
+		}
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	want := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+	withMicros := time.Date(2024, 1, 2, 15, 4, 5, 123456000, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Time
+	}{
+		{value: "2024-01-02 15:04:05", want: want},
+		{value: "2024-01-02 15:04:05.123456", want: withMicros},
+		{value: "2024-01-02 15:04:05.123456+00:00", want: withMicros},
+		{value: "2024-01-02 17:04:05.123456+02:00", want: withMicros},
+		{value: "2024-01-02 15:04:05Z", want: want},
+		{value: "2024-01-02 17:04:05+02:00", want: want},
+		{value: "2024-01-02 17:04:05 +0200", want: want},
+		{value: "2024-01-02 17:04:05+0200", want: want},
+		{value: "2024-01-02T15:04:05", want: want},
+		{value: "2024-01-02T15:04:05Z", want: want},
+		{value: "2024-01-02T15:04:05.123456Z", want: withMicros},
+		{value: "2024-01-02T10:04:05-05:00", want: want},
+		{value: "2024-01-02T10:04:05-0500", want: want},
+		{value: "2024-01-02 17:04:05 +0200 CEST", want: want},
+		{value: "2024-01-02 15:04", want: time.Date(2024, 1, 2, 15, 4, 0, 0, time.UTC)},
+		{value: "2024-01-02T15:04", want: time.Date(2024, 1, 2, 15, 4, 0, 0, time.UTC)},
+		{value: "2024-01-02", want: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{value: "  2024-01-02 15:04:05  ", want: want},
+		{value: ""},
+		{value: "yesterday"},
+		{value: "2024-13-02 15:04:05"},
+		{value: "1704207845"},
+	}
+	for _, tt := range tests {
+		got := parseTime(tt.value)
+		if !got.Equal(tt.want) || got.Location() != time.UTC {
+			t.Errorf("parseTime(%q) = %v, want %v in UTC", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestClientSubmissionSparkline(t *testing.T) {
+	s := newTestStore(t)
+	client := createTestClient(t, s, "example.com")
+	other := createTestClient(t, s, "other.example")
+	form := createTestForm(t, s, client.ID, store.FormTypeSupport)
+	otherForm := createTestForm(t, s, other.ID, store.FormTypeSupport)
+
+	// daysAgo returns noon UTC the given number of days before today.
+	daysAgo := func(days int) string {
+		now := time.Now().UTC()
+		return time.Date(now.Year(), now.Month(), now.Day(), 12, 0, 0, 0, time.UTC).AddDate(0, 0, -days).Format("2006-01-02 15:04:05")
+	}
+	for _, days := range []int{1, 3, 10} {
+		submission := createTestSubmission(t, s, form.ID, store.SubmissionInput{})
+		setCreatedAt(t, s, submission.ID, daysAgo(days))
+	}
+	createTestSubmission(t, s, form.ID, store.SubmissionInput{})
+	createTestSubmission(t, s, form.ID, store.SubmissionInput{})
+	createTestSubmission(t, s, otherForm.ID, store.SubmissionInput{})
+
+	tests := []struct {
+		clientID int64
+		days     int
+		want     []int
+	}{
+		{clientID: client.ID, days: 7, want: []int{0, 0, 0, 1, 0, 1, 2}},
+		{clientID: client.ID, days: 1, want: []int{2}},
+		{clientID: client.ID, days: 11, want: []int{1, 0, 0, 0, 0, 0, 0, 1, 0, 1, 2}},
+		{clientID: other.ID, days: 3, want: []int{0, 0, 1}},
+		{clientID: 9999, days: 3, want: []int{0, 0, 0}},
+	}
+	for _, tt := range tests {
+		got, err := s.ClientSubmissionSparkline(tt.clientID, tt.days)
+		if err != nil {
+			t.Fatalf("ClientSubmissionSparkline(%d, %d): %v", tt.clientID, tt.days, err)
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("ClientSubmissionSparkline(%d, %d) = %v, want %v", tt.clientID, tt.days, got, tt.want)
+		}
+	}
+
+	for _, days := range []int{0, maxSparklineDays + 1} {
+		if _, err := s.ClientSubmissionSparkline(client.ID, days); !apperrors.IsInvalidInput(err) {
+			t.Errorf("ClientSubmissionSparkline(%d days) error = %v, want invalid input", days, err)
+		}
+	}
+}
+
+func TestSubmissionIndexes(t *testing.T) {
+	s := newTestStore(t)
+	// The migration must also apply cleanly to a database that already has the indexes
+	if err := s.Migrate(); err != nil {
+		t.Fatalf("second Migrate: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		args  []interface{}
+		index string
+	}{
+		{
+			name: "ordered by created_at",
+			query: `SELECT s.id FROM submissions s
+JOIN clients c ON c.id = s.client_id
+JOIN forms f ON f.id = s.form_id
+WHERE s.deleted_at IS NULL
+ORDER BY s.created_at DESC
+LIMIT 20`,
+			index: "idx_submissions_created_at",
+		},
+		{name: "by client", query: `SELECT id FROM submissions WHERE client_id = ?`, args: []interface{}{1}, index: "idx_submissions_client_id"},
+		{name: "by form", query: `SELECT id FROM submissions WHERE form_id = ?`, args: []interface{}{1}, index: "idx_submissions_form_id"},
+		{name: "by status", query: `SELECT id FROM submissions WHERE status = ?`, args: []interface{}{validator.StatusOpen}, index: "idx_submissions_status"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := s.db.Query("EXPLAIN QUERY PLAN "+tt.query, tt.args...)
+			if err != nil {
+				t.Fatalf("EXPLAIN QUERY PLAN: %v", err)
+			}
+			defer rows.Close()
+
+			var plan []string
+			for rows.Next() {
+				var id, parent, unused int
+				var detail string
+				if err := rows.Scan(&id, &parent, &unused, &detail); err != nil {
+					t.Fatalf("scan plan: %v", err)
+				}
+				plan = append(plan, detail)
+			}
+			if err := rows.Err(); err != nil {
+				t.Fatalf("read plan: %v", err)
+			}
+			if joined := strings.Join(plan, "; "); !strings.Contains(joined, tt.index) {
+				t.Errorf("query plan %q does not use %s", joined, tt.index)
+			}
+		})
+	}
+}
+
+func TestSubmissionCategory(t *testing.T) {
+	s := newTestStore(t)
+	client := createTestClient(t, s, "example.com")
+	form, err := s.CreateForm(client.ID, store.FormInput{Name: "Support", Type: store.FormTypeSupport, Categories: []string{" Billing ", "Technical", "", "Other"}})
+	if err != nil {
+		t.Fatalf("CreateForm: %v", err)
+	}
+	if fmt.Sprint(form.Categories) != "[Billing Technical Other]" {
+		t.Errorf("form categories = %q, want [Billing Technical Other]", form.Categories)
+	}
+	plain := createTestForm(t, s, client.ID, store.FormTypeSupport)
+
+	billing := createTestSubmission(t, s, form.ID, store.SubmissionInput{Category: "billing"})
+	if billing.Category != "Billing" {
+		t.Errorf("category = %q, want %q", billing.Category, "Billing")
+	}
+	createTestSubmission(t, s, form.ID, store.SubmissionInput{Category: "Technical"})
+	createTestSubmission(t, s, form.ID, store.SubmissionInput{Category: "Billing"})
+	if sub := createTestSubmission(t, s, plain.ID, store.SubmissionInput{Category: "Billing"}); sub.Category != "" {
+		t.Errorf("form without categories stored category %q", sub.Category)
+	}
+
+	for _, category := range []string{"", "Sales"} {
+		if _, err := s.CreateSubmission(form.ID, store.SubmissionInput{Name: "Jane Doe", Email: "jane@example.com", Subject: "Help", Message: "Hi", Category: category}); !apperrors.IsInvalidInput(err) {
+			t.Errorf("CreateSubmission(category %q) error = %v, want invalid input", category, err)
+		}
+	}
+
+	if _, err := s.CreateForm(client.ID, store.FormInput{Name: "Duplicates", Type: store.FormTypeSupport, Categories: []string{"Billing", "BILLING"}}); !apperrors.IsInvalidInput(err) {
+		t.Errorf("CreateForm(duplicate categories) error = %v, want invalid input", err)
+	}
+
+	tests := []struct {
+		category string
+		want     int
+	}{
+		{category: "Billing", want: 2},
+		{category: "Technical", want: 1},
+		{category: "Other", want: 0},
+		{category: "", want: 4},
+	}
+	for _, tt := range tests {
+		submissions, total, err := s.FilterSubmissions(0, 10, store.SubmissionFilter{Category: tt.category})
+		if err != nil {
+			t.Fatalf("FilterSubmissions(%q): %v", tt.category, err)
+		}
+		if total != tt.want || len(submissions) != tt.want {
+			t.Errorf("FilterSubmissions(%q) returned %d of %d, want %d", tt.category, len(submissions), total, tt.want)
+		}
+		for _, sub := range submissions {
+			if tt.category != "" && sub.Category != tt.category {
+				t.Errorf("FilterSubmissions(%q) returned submission %d with category %q", tt.category, sub.ID, sub.Category)
+			}
+		}
+	}
+}
+
+func TestConnectionPragmas(t *testing.T) {
+	s := newTestStore(t)
+
+	var journalMode string
+	if err := s.db.QueryRow(`PRAGMA journal_mode`).Scan(&journalMode); err != nil {
+		t.Fatalf("PRAGMA journal_mode: %v", err)
+	}
+	if journalMode != "wal" {
+		t.Errorf("journal_mode = %q, want %q", journalMode, "wal")
+	}
+	var foreignKeys int
+	if err := s.db.QueryRow(`PRAGMA foreign_keys`).Scan(&foreignKeys); err != nil {
+		t.Fatalf("PRAGMA foreign_keys: %v", err)
+	}
+	if foreignKeys != 1 {
+		t.Errorf("foreign_keys = %d, want 1", foreignKeys)
+	}
+	var busyTimeout int
+	if err := s.db.QueryRow(`PRAGMA busy_timeout`).Scan(&busyTimeout); err != nil {
+		t.Fatalf("PRAGMA busy_timeout: %v", err)
+	}
+	if want := int(defaultBusyTimeout.Milliseconds()); busyTimeout != want {
+		t.Errorf("busy_timeout = %d, want %d", busyTimeout, want)
+	}
+}
+
+func TestConcurrentWrites(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ticketd.db")
+	s, err := New(path, 0)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer s.Close()
+	if err := s.Migrate(); err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+	client := createTestClient(t, s, "example.com")
+	form := createTestForm(t, s, client.ID, store.FormTypeSupport)
+	seed := createTestSubmission(t, s, form.ID, store.SubmissionInput{})
+
+	// A second store on the same file writes through its own connection, like another process would
+	other, err := New(path, 0)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer other.Close()
+
+	const writers = 8
+	const writes = 10
+	var wg sync.WaitGroup
+	errs := make(chan error, 2*writers*writes)
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			target := s
+			if i%2 == 1 {
+				target = other
+			}
+			for j := 0; j < writes; j++ {
+				if _, err := target.CreateSubmission(form.ID, store.SubmissionInput{Name: "Jane Doe", Email: fmt.Sprintf("jane%d-%d@example.com", i, j), Subject: "Help", Message: "Hi"}); err != nil {
+					errs <- err
+				}
+				if _, _, err := target.ListSubmissions(0, 10); err != nil {
+					errs <- err
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("concurrent write: %v", err)
+	}
+
+	if err := s.UpdateSubmissionStatus(seed.ID, validator.StatusClosed, "admin"); err != nil {
+		t.Errorf("UpdateSubmissionStatus after concurrent writes: %v", err)
+	}
+	if _, total, _ := s.ListSubmissions(0, 1); total != writers*writes+1 {
+		t.Errorf("%d submissions stored, want %d", total, writers*writes+1)
+	}
+}
+
+func TestListSubmissionsAfter(t *testing.T) {
+	s := newTestStore(t)
+	client := createTestClient(t, s, "example.com")
+	form := createTestForm(t, s, client.ID, store.FormTypeSupport)
+
+	// IDs 2 and 3 share a timestamp; 4 and 5 share a second but not its fraction
+	for _, created := range []string{
+		"2024-01-01 09:00:00",
+		"2024-01-01 10:00:00",
+		"2024-01-01 10:00:00",
+		"2024-01-01 12:00:00.2",
+		"2024-01-01 12:00:00.5",
+	} {
+		submission := createTestSubmission(t, s, form.ID, store.SubmissionInput{})
+		setCreatedAt(t, s, submission.ID, created)
+	}
+	archived := createTestSubmission(t, s, form.ID, store.SubmissionInput{})
+	if err := s.ArchiveSubmission(archived.ID); err != nil {
+		t.Fatalf("ArchiveSubmission: %v", err)
+	}
+
+	for _, limit := range []int{1, 2, 3, 5, 10} {
+		var ids []int64
+		cursor := ""
+		for pages := 0; ; pages++ {
+			if pages > 5 {
+				t.Fatalf("limit %d: cursor never ran out", limit)
+			}
+			submissions, next, err := s.ListSubmissionsAfter(cursor, limit)
+			if err != nil {
+				t.Fatalf("limit %d: ListSubmissionsAfter(%q): %v", limit, cursor, err)
+			}
+			if len(submissions) > limit {
+				t.Fatalf("limit %d: page has %d submissions", limit, len(submissions))
+			}
+			for _, submission := range submissions {
+				ids = append(ids, submission.ID)
+			}
+			if next == "" {
+				break
+			}
+			cursor = next
+		}
+		if fmt.Sprint(ids) != "[5 4 3 2 1]" {
+			t.Errorf("limit %d: paged IDs = %v, want [5 4 3 2 1]", limit, ids)
+		}
+	}
+
+	for _, cursor := range []string{
+		"not base64!",
+		base64.RawURLEncoding.EncodeToString([]byte("2024-01-01 10:00:00")),
+		base64.RawURLEncoding.EncodeToString([]byte("yesterday|3")),
+		base64.RawURLEncoding.EncodeToString([]byte("2024-01-01 10:00:00|0")),
+		base64.RawURLEncoding.EncodeToString([]byte("2024-01-01 10:00:00|x")),
+	} {
+		if _, _, err := s.ListSubmissionsAfter(cursor, 10); !apperrors.IsInvalidInput(err) {
+			t.Errorf("ListSubmissionsAfter(%q) error = %v, want invalid input", cursor, err)
+		}
+	}
+}
+
+func TestDeleteSubmissionsOlderThan(t *testing.T) {
+	s := newTestStore(t)
+	client := createTestClient(t, s, "example.com")
+	form := createTestForm(t, s, client.ID, store.FormTypeSupport)
+
+	seed := func(created string) store.Submission {
+		t.Helper()
+		submission := createTestSubmission(t, s, form.ID, store.SubmissionInput{})
+		setCreatedAt(t, s, submission.ID, created)
+		return submission
+	}
+	old := seed("2024-01-01 00:00:00")
+	oldArchived := seed("2024-02-01 00:00:00")
+	if err := s.ArchiveSubmission(oldArchived.ID); err != nil {
+		t.Fatalf("ArchiveSubmission: %v", err)
+	}
+	atCutoff := seed("2024-03-01 00:00:00")
+	recent := seed("2024-06-01 00:00:00")
+
+	if _, err := s.AddSubmissionNote(old.ID, "admin", "Followed up by phone"); err != nil {
+		t.Fatalf("AddSubmissionNote: %v", err)
+	}
+	if err := s.UpdateSubmissionStatus(old.ID, validator.StatusClosed, "admin"); err != nil {
+		t.Fatalf("UpdateSubmissionStatus: %v", err)
+	}
+
+	if _, err := s.DeleteSubmissionsOlderThan(time.Time{}); !apperrors.IsInvalidInput(err) {
+		t.Errorf("DeleteSubmissionsOlderThan(zero) error = %v, want invalid input", err)
+	}
+
+	cutoff := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	deleted, err := s.DeleteSubmissionsOlderThan(cutoff)
+	if err != nil {
+		t.Fatalf("DeleteSubmissionsOlderThan: %v", err)
+	}
+	if deleted != 2 {
+		t.Errorf("deleted %d submissions, want 2", deleted)
+	}
+
+	for _, id := range []int64{old.ID, oldArchived.ID} {
+		if _, err := s.GetSubmission(id); !apperrors.IsNotFound(err) {
+			t.Errorf("GetSubmission(%d) after retention error = %v, want not found", id, err)
+		}
+	}
+	for _, id := range []int64{atCutoff.ID, recent.ID} {
+		if _, err := s.GetSubmission(id); err != nil {
+			t.Errorf("GetSubmission(%d) after retention: %v", id, err)
+		}
+	}
+	for _, table := range []string{"submission_notes", "submission_status_history"} {
+		var count int
+		if err := s.db.QueryRow(`SELECT COUNT(*) FROM `+table+` WHERE submission_id = ?`, old.ID).Scan(&count); err != nil {
+			t.Fatalf("count %s: %v", table, err)
+		}
+		if count != 0 {
+			t.Errorf("%d rows left in %s for deleted submission", count, table)
+		}
+	}
+
+	if deleted, err := s.DeleteSubmissionsOlderThan(cutoff); err != nil || deleted != 0 {
+		t.Errorf("second DeleteSubmissionsOlderThan = %d, %v, want 0, nil", deleted, err)
+	}
+}
+
+func TestOldestOpenSubmissionAgeByClient(t *testing.T) {
+	s := newTestStore(t)
+	busy := createTestClient(t, s, "busy.example")
+	closed := createTestClient(t, s, "closed.example")
+	working := createTestClient(t, s, "working.example")
+	empty := createTestClient(t, s, "empty.example")
+
+	ago := func(d time.Duration) string { return formatTimeParam(time.Now().Add(-d)) }
+	day := 24 * time.Hour
+	seed := func(client store.Client, created, status string, archive bool) {
+		t.Helper()
+		form := createTestForm(t, s, client.ID, store.FormTypeSupport)
+		submission := createTestSubmission(t, s, form.ID, store.SubmissionInput{})
+		setCreatedAt(t, s, submission.ID, created)
+		if status != validator.StatusOpen {
+			if err := s.UpdateSubmissionStatus(submission.ID, status, "admin"); err != nil {
+				t.Fatalf("UpdateSubmissionStatus: %v", err)
+			}
+		}
+		if archive {
+			if err := s.ArchiveSubmission(submission.ID); err != nil {
+				t.Fatalf("ArchiveSubmission: %v", err)
+			}
+		}
+	}
+	seed(busy, ago(2*day), validator.StatusOpen, false)
+	seed(busy, ago(10*day), validator.StatusOpen, false)
+	seed(busy, ago(30*day), validator.StatusClosed, false)
+	seed(busy, ago(40*day), validator.StatusOpen, true)
+	seed(closed, ago(5*day), validator.StatusClosed, false)
+	seed(closed, ago(6*day), validator.StatusSpam, false)
+	seed(working, ago(7*day), validator.StatusInProgress, false)
+	// Rows from before statuses were recorded count as open
+	legacy := createTestClient(t, s, "legacy.example")
+	seed(legacy, ago(3*day), validator.StatusOpen, false)
+	if _, err := s.db.Exec(`UPDATE submissions SET status = '' WHERE client_id = ?`, legacy.ID); err != nil {
+		t.Fatalf("clear status: %v", err)
+	}
+
+	ages, err := s.OldestOpenSubmissionAgeByClient()
+	if err != nil {
+		t.Fatalf("OldestOpenSubmissionAgeByClient: %v", err)
+	}
+	if len(ages) != 2 {
+		t.Errorf("ages = %v, want entries for clients %d and %d only", ages, busy.ID, legacy.ID)
+	}
+	for _, client := range []store.Client{closed, working, empty} {
+		if age, ok := ages[client.ID]; ok {
+			t.Errorf("client %q has age %v, want no entry", client.AllowedDomain, age)
+		}
+	}
+	for client, want := range map[store.Client]time.Duration{busy: 10 * day, legacy: 3 * day} {
+		if age := ages[client.ID]; age < want || age > want+time.Minute {
+			t.Errorf("client %q age = %v, want about %v", client.AllowedDomain, age, want)
+		}
+	}
+}
+
+func TestDeleteSubmissionsByEmail(t *testing.T) {
+	s := newTestStore(t)
+	client := createTestClient(t, s, "example.com")
+	other := createTestClient(t, s, "other.example")
+	form := createTestForm(t, s, client.ID, store.FormTypeSupport)
+	otherForm := createTestForm(t, s, other.ID, store.FormTypeContact)
+
+	jane := createTestSubmission(t, s, form.ID, store.SubmissionInput{Email: "jane@example.com"})
+	createTestSubmission(t, s, otherForm.ID, store.SubmissionInput{Email: "Jane@Example.COM"})
+	archived := createTestSubmission(t, s, form.ID, store.SubmissionInput{Email: "jane@example.com"})
+	if err := s.ArchiveSubmission(archived.ID); err != nil {
+		t.Fatalf("ArchiveSubmission: %v", err)
+	}
+	keep := []store.Submission{
+		createTestSubmission(t, s, form.ID, store.SubmissionInput{Email: "john@example.com"}),
+		createTestSubmission(t, s, form.ID, store.SubmissionInput{Email: "jane@example.org"}),
+		createTestSubmission(t, s, otherForm.ID, store.SubmissionInput{Email: "xjane@example.com"}),
+	}
+	if _, err := s.AddSubmissionNote(jane.ID, "admin", "Asked to be forgotten"); err != nil {
+		t.Fatalf("AddSubmissionNote: %v", err)
+	}
+	if err := s.UpdateSubmissionStatus(jane.ID, validator.StatusClosed, "admin"); err != nil {
+		t.Fatalf("UpdateSubmissionStatus: %v", err)
+	}
+
+	for _, email := range []string{"", "   "} {
+		if _, err := s.DeleteSubmissionsByEmail(email); !apperrors.IsInvalidInput(err) {
+			t.Errorf("DeleteSubmissionsByEmail(%q) error = %v, want invalid input", email, err)
+		}
+	}
+	if deleted, err := s.DeleteSubmissionsByEmail("nobody@example.com"); err != nil || deleted != 0 {
+		t.Errorf("DeleteSubmissionsByEmail(non-matching) = %d, %v, want 0, nil", deleted, err)
+	}
+
+	deleted, err := s.DeleteSubmissionsByEmail("  JANE@example.com ")
+	if err != nil {
+		t.Fatalf("DeleteSubmissionsByEmail: %v", err)
+	}
+	if deleted != 3 {
+		t.Errorf("deleted %d submissions, want 3", deleted)
+	}
+
+	var remaining []int64
+	rows, err := s.db.Query(`SELECT id FROM submissions ORDER BY id`)
+	if err != nil {
+		t.Fatalf("list remaining: %v", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			t.Fatalf("scan id: %v", err)
+		}
+		remaining = append(remaining, id)
+	}
+	if want := fmt.Sprint([]int64{keep[0].ID, keep[1].ID, keep[2].ID}); fmt.Sprint(remaining) != want {
+		t.Errorf("remaining submissions = %v, want %s", remaining, want)
+	}
+	for _, table := range []string{"submission_notes", "submission_status_history"} {
+		var count int
+		if err := s.db.QueryRow(`SELECT COUNT(*) FROM `+table+` WHERE submission_id = ?`, jane.ID).Scan(&count); err != nil {
+			t.Fatalf("count %s: %v", table, err)
+		}
+		if count != 0 {
+			t.Errorf("%d rows left in %s for erased submission", count, table)
+		}
+	}
+}
+
+func TestClientDomainCollision(t *testing.T) {
+	s := newTestStore(t)
+	example := createTestClient(t, s, "https://Example.com/")
+	if example.AllowedDomain != "example.com" {
+		t.Errorf("stored domain = %q, want %q", example.AllowedDomain, "example.com")
+	}
+	other := createTestClient(t, s, "other.example")
+
+	for _, domain := range []string{"example.com", "EXAMPLE.COM", "http://example.com", "https://example.com/contact", "example.com."} {
+		if _, err := s.CreateClient("Duplicate", domain); !apperrors.IsInvalidInput(err) {
+			t.Errorf("CreateClient(%q) error = %v, want invalid input", domain, err)
+		}
+	}
+	// Subdomains and other ports are different domains
+	for _, domain := range []string{"www.example.com", "example.com:8080"} {
+		if _, err := s.CreateClient("Distinct "+domain, domain); err != nil {
+			t.Errorf("CreateClient(%q): %v", domain, err)
+		}
+	}
+
+	if err := s.UpdateClient(other.ID, other.Name, "https://EXAMPLE.com/", ""); !apperrors.IsInvalidInput(err) {
+		t.Errorf("UpdateClient to a taken domain error = %v, want invalid input", err)
+	}
+	if got, err := s.GetClient(other.ID); err != nil || got.AllowedDomain != "other.example" {
+		t.Errorf("after failed update domain = %q, %v, want %q", got.AllowedDomain, err, "other.example")
+	}
+	// A client keeps its own domain when only its name changes
+	if err := s.UpdateClient(example.ID, "Renamed", "Example.com", ""); err != nil {
+		t.Errorf("UpdateClient keeping its own domain: %v", err)
+	}
+	if err := s.UpdateClient(other.ID, other.Name, "new.example", ""); err != nil {
+		t.Errorf("UpdateClient to a free domain: %v", err)
+	}
+	if _, err := s.CreateClient("Reuse", "other.example"); err != nil {
+		t.Errorf("CreateClient with a released domain: %v", err)
+	}
+}
+
+func TestGetSubmissionByToken(t *testing.T) {
+	s := newTestStore(t)
+	client := createTestClient(t, s, "example.com")
+	form := createTestForm(t, s, client.ID, store.FormTypeSupport)
+	first := createTestSubmission(t, s, form.ID, store.SubmissionInput{})
+	second := createTestSubmission(t, s, form.ID, store.SubmissionInput{})
+	if first.StatusToken == "" || first.StatusToken == second.StatusToken {
+		t.Fatalf("status tokens %q and %q, want two distinct tokens", first.StatusToken, second.StatusToken)
+	}
+
+	got, err := s.GetSubmissionByToken(second.StatusToken)
+	if err != nil {
+		t.Fatalf("GetSubmissionByToken: %v", err)
+	}
+	if got.ID != second.ID {
+		t.Errorf("GetSubmissionByToken returned submission %d, want %d", got.ID, second.ID)
+	}
+
+	// Submissions from before tokens existed have an empty one, which must never match
+	if _, err := s.db.Exec(`UPDATE submissions SET status_token = '' WHERE id = ?`, first.ID); err != nil {
+		t.Fatalf("clear status token: %v", err)
+	}
+	for _, token := range []string{"", "unknown", strings.ToUpper(second.StatusToken)} {
+		if token == second.StatusToken {
+			continue
+		}
+		if _, err := s.GetSubmissionByToken(token); !apperrors.IsNotFound(err) {
+			t.Errorf("GetSubmissionByToken(%q) error = %v, want not found", token, err)
+		}
+	}
+}
+
+func TestCloneForm(t *testing.T) {
+	s := newTestStore(t)
+	client := createTestClient(t, s, "example.com")
+	original, err := s.CreateForm(client.ID, store.FormInput{
+		Name:           "Support",
+		Type:           store.FormTypeContact,
+		UniqueEmail:    true,
+		PriorityField:  true,
+		Language:       "de",
+		SuccessURL:     "https://example.com/thanks",
+		Theme:          store.FormTheme{PrimaryColor: "#2563eb", BorderRadius: "8px", FontFamily: "Inter, sans-serif"},
+		Categories:     []string{"Billing", "Technical"},
+		RequireConsent: true,
+		ConsentLabel:   "I agree",
+		PrivacyURL:     "https://example.com/privacy",
+	})
+	if err != nil {
+		t.Fatalf("CreateForm: %v", err)
+	}
+	createTestSubmission(t, s, original.ID, store.SubmissionInput{Category: "Billing", Consent: "I agree"})
+
+	clone, err := s.CloneForm(original.ID)
+	if err != nil {
+		t.Fatalf("CloneForm: %v", err)
+	}
+	if clone.ID == original.ID || clone.Name != "Support (copy)" {
+		t.Errorf("clone is form %d named %q, want a new form named %q", clone.ID, clone.Name, "Support (copy)")
+	}
+	stored, err := s.GetForm(clone.ID)
+	if err != nil {
+		t.Fatalf("GetForm: %v", err)
+	}
+	// Everything but the identity and name must match the original
+	for _, form := range []store.Form{clone, stored} {
+		form.ID, form.Name, form.CreatedAt = original.ID, original.Name, original.CreatedAt
+		if fmt.Sprintf("%+v", form) != fmt.Sprintf("%+v", original) {
+			t.Errorf("clone = %+v, want the settings of %+v", form, original)
+		}
+	}
+	var submissions int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM submissions WHERE form_id = ?`, clone.ID).Scan(&submissions); err != nil {
+		t.Fatalf("count clone submissions: %v", err)
+	}
+	if submissions != 0 {
+		t.Errorf("clone has %d submissions, want 0", submissions)
+	}
+
+	// An inactive form stays inactive when copied
+	if err := s.SetFormActive(original.ID, false); err != nil {
+		t.Fatalf("SetFormActive: %v", err)
+	}
+	clone, err = s.CloneForm(original.ID)
+	if err != nil {
+		t.Fatalf("CloneForm of inactive form: %v", err)
+	}
+	if stored, err := s.GetForm(clone.ID); err != nil || clone.Active || stored.Active {
+		t.Errorf("clone of inactive form: active %t, stored active %t (%v), want inactive", clone.Active, stored.Active, err)
+	}
+
+	// Long names are shortened to make room for the suffix
+	long, err := s.CreateForm(client.ID, store.FormInput{Name: strings.Repeat("é", 127), Type: store.FormTypeSupport})
+	if err != nil {
+		t.Fatalf("CreateForm with long name: %v", err)
+	}
+	clone, err = s.CloneForm(long.ID)
+	if err != nil {
+		t.Fatalf("CloneForm of long name: %v", err)
+	}
+	if want := strings.Repeat("é", 124) + " (copy)"; clone.Name != want {
+		t.Errorf("clone name = %q, want %q", clone.Name, want)
+	}
+
+	if _, err := s.CloneForm(9999); !apperrors.IsNotFound(err) {
+		t.Errorf("CloneForm(unknown) error = %v, want not found", err)
+	}
+}
+
+func TestGetClientByDomainCustomCSS(t *testing.T) {
+	s := newTestStore(t)
+	const css = ".ticketd-form { color: #333; }"
+	for _, domain := range []string{"example.com", "localhost:3000"} {
+		client := createTestClient(t, s, domain)
+		if err := s.UpdateClient(client.ID, client.Name, client.AllowedDomain, css); err != nil {
+			t.Fatalf("UpdateClient(%q): %v", domain, err)
+		}
+	}
+
+	for _, host := range []string{"www.example.com", "127.0.0.1"} {
+		client, err := s.GetClientByDomain(host)
+		if err != nil {
+			t.Fatalf("GetClientByDomain(%q): %v", host, err)
+		}
+		want, err := s.GetClient(client.ID)
+		if err != nil {
+			t.Fatalf("GetClient: %v", err)
+		}
+		if client.CustomCSS != css || fmt.Sprint(client) != fmt.Sprint(want) {
+			t.Errorf("GetClientByDomain(%q) = %+v, want %+v", host, client, want)
+		}
+	}
+}
+
+func TestBulkUpdateStatus(t *testing.T) {
+	s := newTestStore(t)
+	client := createTestClient(t, s, "example.com")
+	form := createTestForm(t, s, client.ID, store.FormTypeSupport)
+	open := createTestSubmission(t, s, form.ID, store.SubmissionInput{})
+	closed := createTestSubmission(t, s, form.ID, store.SubmissionInput{})
+	untouched := createTestSubmission(t, s, form.ID, store.SubmissionInput{})
+	if err := s.UpdateSubmissionStatus(closed.ID, validator.StatusClosed, "bob"); err != nil {
+		t.Fatalf("UpdateSubmissionStatus: %v", err)
+	}
+
+	updated, err := s.BulkUpdateStatus([]int64{open.ID, closed.ID, 9999}, validator.StatusClosed, "alice")
+	if err != nil {
+		t.Fatalf("BulkUpdateStatus: %v", err)
+	}
+	if updated != 2 {
+		t.Errorf("updated = %d, want 2", updated)
+	}
+
+	tests := []struct {
+		name        string
+		id          int64
+		wantStatus  string
+		wantHistory []string
+	}{
+		{name: "open", id: open.ID, wantStatus: validator.StatusClosed, wantHistory: []string{"OPEN->CLOSED by alice"}},
+		{name: "already closed", id: closed.ID, wantStatus: validator.StatusClosed, wantHistory: []string{"OPEN->CLOSED by bob"}},
+		{name: "not selected", id: untouched.ID, wantStatus: validator.StatusOpen},
+	}
+	for _, tt := range tests {
+		submission, err := s.GetSubmission(tt.id)
+		if err != nil {
+			t.Fatalf("%s: GetSubmission: %v", tt.name, err)
+		}
+		if submission.Status != tt.wantStatus {
+			t.Errorf("%s: status = %q, want %q", tt.name, submission.Status, tt.wantStatus)
+		}
+		history, err := s.GetStatusHistory(tt.id)
+		if err != nil {
+			t.Fatalf("%s: GetStatusHistory: %v", tt.name, err)
+		}
+		var got []string
+		for _, change := range history {
+			got = append(got, fmt.Sprintf("%s->%s by %s", change.FromStatus, change.ToStatus, change.Actor))
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tt.wantHistory) {
+			t.Errorf("%s: history = %v, want %v", tt.name, got, tt.wantHistory)
+		}
+	}
+
+	if _, err := s.BulkUpdateStatus(nil, validator.StatusClosed, "alice"); !apperrors.IsInvalidInput(err) {
+		t.Errorf("no IDs: error = %v, want invalid input", err)
+	}
+	if _, err := s.BulkUpdateStatus([]int64{untouched.ID}, "DONE", "alice"); !apperrors.IsInvalidInput(err) {
+		t.Errorf("invalid status: error = %v, want invalid input", err)
+	}
+}
+
+func TestBulkDelete(t *testing.T) {
+	s := newTestStore(t)
+	client := createTestClient(t, s, "example.com")
+	form := createTestForm(t, s, client.ID, store.FormTypeSupport)
+	deleted := createTestSubmission(t, s, form.ID, store.SubmissionInput{})
+	kept := createTestSubmission(t, s, form.ID, store.SubmissionInput{})
+	if _, err := s.AddSubmissionNote(deleted.ID, "alice", "Called back"); err != nil {
+		t.Fatalf("AddSubmissionNote: %v", err)
+	}
+	if err := s.UpdateSubmissionStatus(deleted.ID, validator.StatusClosed, "alice"); err != nil {
+		t.Fatalf("UpdateSubmissionStatus: %v", err)
+	}
+
+	n, err := s.BulkDelete([]int64{deleted.ID, 9999})
+	if err != nil {
+		t.Fatalf("BulkDelete: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("deleted = %d, want 1", n)
+	}
+	if _, err := s.GetSubmission(deleted.ID); !apperrors.IsNotFound(err) {
+		t.Errorf("deleted submission: error = %v, want not found", err)
+	}
+	if _, err := s.GetSubmission(kept.ID); err != nil {
+		t.Errorf("unselected submission: %v", err)
+	}
+	for _, table := range []string{"submission_notes", "submission_status_history"} {
+		var count int
+		if err := s.db.QueryRow(`SELECT COUNT(*) FROM `+table+` WHERE submission_id = ?`, deleted.ID).Scan(&count); err != nil {
+			t.Fatalf("count %s: %v", table, err)
+		}
+		if count != 0 {
+			t.Errorf("%d rows left in %s for the deleted submission", count, table)
+		}
+	}
+
+	if _, err := s.BulkDelete(nil); !apperrors.IsInvalidInput(err) {
+		t.Errorf("no IDs: error = %v, want invalid input", err)
+	}
+}
+
+func TestCreateSubmissionAnonymizesIP(t *testing.T) {
+	s := newTestStore(t)
+	client := createTestClient(t, s, "example.com")
+	form := createTestForm(t, s, client.ID, store.FormTypeSupport)
+
+	kept := createTestSubmission(t, s, form.ID, store.SubmissionInput{IP: "203.0.113.7"})
+	s.SetAnonymizeIP(true)
+	tests := []struct {
+		ip   string
+		want string
+	}{
+		{ip: "203.0.113.7", want: "203.0.113.0"},
+		{ip: "2001:db8:1:2:3:4:5:6", want: "2001:db8:1::"},
+		{ip: "not an ip", want: ""},
+	}
+	for _, tt := range tests {
+		created := createTestSubmission(t, s, form.ID, store.SubmissionInput{IP: tt.ip})
+		stored, err := s.GetSubmission(created.ID)
+		if err != nil {
+			t.Fatalf("GetSubmission: %v", err)
+		}
+		if stored.IP != tt.want {
+			t.Errorf("stored IP for %q = %q, want %q", tt.ip, stored.IP, tt.want)
+		}
+	}
+
+	// Submissions stored before anonymization was enabled keep their address
+	if stored, _ := s.GetSubmission(kept.ID); stored.IP != "203.0.113.7" {
+		t.Errorf("existing submission IP = %q, want it unchanged", stored.IP)
+	}
+}
+
+func TestDeleteAdminUser(t *testing.T) {
+	s := newTestStore(t)
+	client := createTestClient(t, s, "example.com")
+	form := createTestForm(t, s, client.ID, store.FormTypeSupport)
+	alice, err := s.CreateAdminUser("alice", "unused-hash")
+	if err != nil {
+		t.Fatalf("CreateAdminUser: %v", err)
+	}
+	bob, err := s.CreateAdminUser("bob", "unused-hash")
+	if err != nil {
+		t.Fatalf("CreateAdminUser: %v", err)
+	}
+	submission := createTestSubmission(t, s, form.ID, store.SubmissionInput{})
+	if err := s.AssignSubmission(submission.ID, "alice", "bob"); err != nil {
+		t.Fatalf("AssignSubmission: %v", err)
+	}
+	assignee := func() string {
+		t.Helper()
+		got, err := s.GetSubmission(submission.ID)
+		if err != nil {
+			t.Fatalf("GetSubmission: %v", err)
+		}
+		return got.Assignee
+	}
+
+	// A failed delete must not leave the user's tickets unassigned
+	if _, err := s.db.Exec(`CREATE TRIGGER keep_admins BEFORE DELETE ON admin_users BEGIN SELECT RAISE(ABORT, 'locked'); END`); err != nil {
+		t.Fatalf("create trigger: %v", err)
+	}
+	if err := s.DeleteAdminUser(alice.ID); err == nil {
+		t.Fatalf("DeleteAdminUser succeeded despite the trigger")
+	}
+	if got := assignee(); got != "alice" {
+		t.Errorf("assignee after a failed delete = %q, want alice", got)
+	}
+	if _, err := s.db.Exec(`DROP TRIGGER keep_admins`); err != nil {
+		t.Fatalf("drop trigger: %v", err)
+	}
+
+	if err := s.DeleteAdminUser(alice.ID); err != nil {
+		t.Fatalf("DeleteAdminUser: %v", err)
+	}
+	if got := assignee(); got != "" {
+		t.Errorf("assignee after deleting the user = %q, want none", got)
+	}
+	if err := s.DeleteAdminUser(alice.ID); !apperrors.IsNotFound(err) {
+		t.Errorf("deleting again: error = %v, want not found", err)
+	}
+	if users, err := s.ListAdminUsers(); err != nil || len(users) != 1 || users[0].ID != bob.ID {
+		t.Errorf("ListAdminUsers = %v, %v, want only bob", users, err)
+	}
+}
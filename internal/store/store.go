@@ -3,7 +3,10 @@
 // while maintaining a consistent API for data access.
 package store
 
-import "time"
+import (
+	"context"
+	"time"
+)
 
 // Client represents a client organization that can create forms.
 // Each client has an allowed domain used for CORS validation of form submissions.
@@ -11,9 +14,16 @@ type Client struct {
 	ID            int64
 	Name          string
 	AllowedDomain string
+	CustomCSS     string // Appended to the global embed stylesheet for the client's forms, only set by GetClient
 	CreatedAt     time.Time
 }
 
+// ClientWithCount is a client together with the number of submissions it has received.
+type ClientWithCount struct {
+	Client
+	SubmissionCount int
+}
+
 // FormType represents the type of form (support or contact).
 type FormType string
 
@@ -27,42 +37,237 @@ const (
 
 // Form represents a contact or support form belonging to a client.
 type Form struct {
-	ID        int64
-	ClientID  int64
-	Name      string
-	Type      FormType
-	CreatedAt time.Time
+	ID            int64
+	ClientID      int64
+	Client        string // Denormalized client name, only set by ListFormsByType
+	Name          string
+	Type          FormType
+	UniqueEmail   bool   // Accept one submission per email address (case-insensitive)
+	PriorityField bool   // Show the priority field on a contact form; support forms always have it
+	Language      string // Language code for the embed widget's labels and messages (default: "en")
+	SuccessURL    string // Page to send submitters to after a successful submission, empty for the inline message
+	Theme         FormTheme
+	Categories    []string // Options submitters must pick a category from, empty for no category field
+	Active        bool     // Accepting submissions; inactive forms reject them and their widget shows a notice
+	CreatedAt     time.Time
+
+	RequireConsent bool   // Submitters must tick a consent checkbox, e.g. for the GDPR
+	ConsentLabel   string // Text of the consent checkbox, empty for the default in the widget's language
+	PrivacyURL     string // Privacy policy linked next to the consent checkbox, optional
+}
+
+// FormInput contains the editable settings of a form.
+type FormInput struct {
+	Name          string
+	Type          FormType
+	UniqueEmail   bool
+	PriorityField bool   // Only affects contact forms
+	Language      string // Empty means DefaultLanguage
+	SuccessURL    string // Optional absolute http(s) URL
+	Theme         FormTheme
+	Categories    []string // Optional; when set, every submission must pick one
+
+	RequireConsent bool
+	ConsentLabel   string // Optional, plain text
+	PrivacyURL     string // Optional absolute http(s) URL
+}
+
+// FormTheme holds optional brand overrides for the embedded form.
+// Empty values keep the stylesheet defaults.
+type FormTheme struct {
+	PrimaryColor string // Hex color such as "#2563eb"
+	BorderRadius string // Pixel length such as "8px"
+	FontFamily   string // CSS font-family list such as "Inter, sans-serif"
 }
 
+// DefaultLanguage is the embed widget language used when a form has none set.
+const DefaultLanguage = "en"
+
 // Submission represents a form submission (ticket).
 // It includes denormalized client and form names for easier display.
 type Submission struct {
-	ID        int64
-	ClientID  int64
-	Client    string   // Denormalized client name
-	FormID    int64
-	Form      string   // Denormalized form name
-	FormType  FormType
-	Status    string
-	Name      string
-	Email     string
-	Subject   string
-	Message   string
-	Priority  string
-	IP        string
-	UserAgent string
-	CreatedAt time.Time
+	ID         int64
+	ClientID   int64
+	Client     string // Denormalized client name
+	FormID     int64
+	Form       string // Denormalized form name
+	FormType   FormType
+	Status     string
+	Name       string
+	Email      string
+	Phone      string // Optional, as entered; validated by validator.ValidatePhone
+	PhoneE164  string // Phone normalized to E.164, empty if it couldn't be normalized
+	Subject    string
+	Message    string
+	Priority   string
+	Category   string // One of the form's categories, empty if the form had none
+	IP         string
+	UserAgent  string
+	Assignee   string // Username of the admin handling the ticket, empty if unassigned
+	EmailValid bool   // Whether Email passed strict validation when the submission was received
+	Source     string // How the submission was sent, one of the Source* constants (empty for old rows)
+	CreatedAt  time.Time
+	ArchivedAt time.Time // Zero unless the submission has been archived
+	UpdatedBy  string    // Admin who last changed the status or assignee, empty if nobody has
+
+	StatusToken string // Unguessable secret for the public status page, empty for old rows
+	Reference   string // Human-friendly ticket number such as TKD-2024-000123, unique
+	Consent     string // Consent text the submitter agreed to, empty if the form didn't ask
 }
 
 // SubmissionInput contains the data needed to create a new submission.
 type SubmissionInput struct {
 	Name      string
 	Email     string
+	Phone     string
+	PhoneE164 string // Derived from Phone by validator.TrimSubmissionInput
 	Subject   string
 	Message   string
 	Priority  string
+	Category  string // Checked against the form's categories by CreateSubmission
 	IP        string
 	UserAgent string
+	Source    string
+	Spam      bool // Store with the SPAM status instead of OPEN
+
+	Consent string // Consent text the submitter agreed to, empty if the form didn't ask
+}
+
+// Submission sources recorded by CreateSubmission.
+const (
+	SourceWidget   = "js-widget" // JSON sent by the embedded form script
+	SourceAPIJSON  = "api-json"  // JSON sent by any other client
+	SourceFormPost = "form-post" // URL-encoded or multipart form post
+)
+
+// Sort fields accepted by FilterSubmissions.
+const (
+	SortCreatedAt = "created_at"
+	SortStatus    = "status"
+	SortClient    = "client"
+	SortPriority  = "priority"
+)
+
+// Sort directions accepted by FilterSubmissions.
+const (
+	SortAsc  = "asc"
+	SortDesc = "desc"
+)
+
+// SubmissionFilter holds the optional filters and ordering for FilterSubmissions.
+// Empty/zero values are ignored (no filtering applied for that field).
+type SubmissionFilter struct {
+	Status   string
+	ClientID int64
+	FormID   int64
+	Search   string // Substring match on the subject
+	Assignee string // Exact assignee username
+	Category string // Exact category
+
+	// Unassigned restricts results to submissions without an assignee.
+	// It takes precedence over Assignee.
+	Unassigned bool
+
+	InvalidEmail bool // Only submissions whose email failed strict validation
+
+	// Archived submissions are excluded unless IncludeArchived is set.
+	// ArchivedOnly restricts results to archived submissions and takes precedence.
+	IncludeArchived bool
+	ArchivedOnly    bool
+
+	SortField string // One of the Sort* field constants (default: created_at)
+	SortDir   string // SortAsc or SortDesc (default: desc)
+}
+
+// SubmissionNote is an internal comment on a submission.
+// Notes are only shown in the admin dashboard, never to the submitter.
+type SubmissionNote struct {
+	ID           int64
+	SubmissionID int64
+	Author       string
+	Body         string
+	CreatedAt    time.Time
+}
+
+// StatusChange records one status transition of a submission.
+type StatusChange struct {
+	ID           int64
+	SubmissionID int64
+	FromStatus   string // Status before the change; submissions without a status count as OPEN
+	ToStatus     string
+	Actor        string // Admin who made the change, or "system" for automatic changes
+	CreatedAt    time.Time
+}
+
+// AdminUser is an account that can sign in to the admin dashboard.
+// Only the bcrypt hash of the password is stored.
+type AdminUser struct {
+	ID           int64
+	Username     string
+	PasswordHash string
+	CreatedAt    time.Time
+}
+
+// AuditEntry records a change an admin made, for compliance review.
+type AuditEntry struct {
+	ID         int64
+	Actor      string // Username of the admin who acted
+	Action     string // One of the Audit* action constants
+	TargetType string // One of the AuditTarget* constants
+	TargetID   int64  // Zero for actions that span several records
+	Detail     string // Human-readable summary, may be empty
+	CreatedAt  time.Time
+}
+
+// Actions recorded in the audit log.
+const (
+	AuditCreate    = "create"
+	AuditUpdate    = "update"
+	AuditDelete    = "delete"
+	AuditStatus    = "status"
+	AuditAssign    = "assign"
+	AuditArchive   = "archive"
+	AuditRestore   = "restore"
+	AuditBulkClose = "bulk_close"
+	AuditErase     = "erase"
+)
+
+// Target types recorded in the audit log.
+const (
+	AuditTargetSubmission = "submission"
+	AuditTargetClient     = "client"
+	AuditTargetForm       = "form"
+)
+
+// SubjectCount is the number of submissions sharing a normalized subject.
+type SubjectCount struct {
+	Subject string
+	Count   int
+}
+
+// ClientCount is the number of submissions received for a client.
+type ClientCount struct {
+	ClientID int64
+	Client   string
+	Count    int
+}
+
+// FormActivity is the number of submissions a form received in a time window.
+type FormActivity struct {
+	FormID   int64
+	Form     string
+	ClientID int64
+	Client   string // Denormalized client name
+	Count    int
+}
+
+// SubmissionStats is an aggregated overview of all submissions for the admin dashboard.
+type SubmissionStats struct {
+	Total      int
+	ByStatus   map[string]int   // Every known status is present; empty statuses count as OPEN
+	ByFormType map[FormType]int // Every known form type is present
+	TopClients []ClientCount    // Busiest clients first
+	LastWeek   int              // Submissions received in the last 7 days
 }
 
 // Store defines the persistence interface for all data operations.
@@ -74,6 +279,9 @@ type Store interface {
 	// Close closes the database connection and releases resources.
 	Close() error
 
+	// Ping checks that the database is reachable, giving up when ctx is done.
+	Ping(ctx context.Context) error
+
 	// CreateClient creates a new client with the given name and allowed domain.
 	// The allowed domain is used for CORS validation of form submissions.
 	// Returns the created client or an error if creation fails.
@@ -83,32 +291,74 @@ type Store interface {
 	// offset specifies how many records to skip, limit specifies max records to return.
 	ListClients(offset, limit int) ([]Client, int, error)
 
+	// ListClientsWithCounts is like ListClients but also returns each client's submission count
+	// across all of its forms.
+	ListClientsWithCounts(offset, limit int) ([]ClientWithCount, int, error)
+
+	// ClientSubmissionSparkline returns a client's daily submission counts for the last days
+	// UTC days, oldest first and ending today, with zero for days without submissions.
+	// Returns ErrInvalidInput if days is below 1 or above 366.
+	ClientSubmissionSparkline(clientID int64, days int) ([]int, error)
+
+	// OldestOpenSubmissionAgeByClient returns, per client ID, how long ago the client's oldest
+	// unarchived OPEN submission was created. Submissions without a status count as OPEN.
+	// Clients without open submissions are not in the map.
+	OldestOpenSubmissionAgeByClient() (map[int64]time.Duration, error)
+
 	// GetClient retrieves a client by ID.
 	// Returns ErrNotFound if the client doesn't exist.
 	GetClient(id int64) (Client, error)
 
-	// UpdateClient updates an existing client's name and allowed domain.
+	// GetClientByDomain finds the client whose allowed domain accepts an origin host, using the
+	// same rules as the origin check: the domain itself or any parent domain, with the most
+	// specific match winning, and localhost and 127.0.0.1 interchangeable on any port.
+	// Returns ErrNotFound if no client matches.
+	GetClientByDomain(domain string) (Client, error)
+
+	// UpdateClient updates an existing client's name, allowed domain, and custom CSS.
 	// Returns an error if the client doesn't exist or update fails.
-	UpdateClient(id int64, name, allowedDomain string) error
+	UpdateClient(id int64, name, allowedDomain, customCSS string) error
 
 	// DeleteClient permanently deletes a client and all associated forms and submissions.
 	// Returns an error if the client doesn't exist or deletion fails.
 	DeleteClient(id int64) error
 
 	// CreateForm creates a new form for the specified client.
+	// Returns ErrRateLimited if the client created too many forms recently.
 	// Returns the created form or an error if creation fails.
-	CreateForm(clientID int64, name string, formType FormType) (Form, error)
+	CreateForm(clientID int64, input FormInput) (Form, error)
+
+	// CloneForm copies a form's settings into a new form of the same client, named after
+	// the original with " (copy)" appended. Submissions are not copied.
+	// Returns ErrNotFound if the form doesn't exist, and ErrRateLimited like CreateForm.
+	CloneForm(formID int64) (Form, error)
+
+	// CountFormsCreatedSince returns how many forms of a client were created at or after since.
+	// Deleted forms are not counted.
+	CountFormsCreatedSince(clientID int64, since time.Time) (int, error)
 
 	// ListForms returns all forms for the specified client.
 	ListForms(clientID int64) ([]Form, error)
 
+	// ListFormsPaginated returns a paginated list of forms for the specified client and the total count.
+	ListFormsPaginated(clientID int64, offset, limit int) ([]Form, int, error)
+
+	// ListFormsByType returns a paginated list of forms across all clients and the total count.
+	// Results include the denormalized client name. An empty formType matches every type.
+	// Returns ErrInvalidInput if formType is not a known form type.
+	ListFormsByType(formType FormType, offset, limit int) ([]Form, int, error)
+
 	// GetForm retrieves a form by ID.
 	// Returns ErrNotFound if the form doesn't exist.
 	GetForm(id int64) (Form, error)
 
-	// UpdateForm updates an existing form's name and type.
+	// UpdateForm updates an existing form's settings.
 	// Returns an error if the form doesn't exist or update fails.
-	UpdateForm(id int64, name string, formType FormType) error
+	UpdateForm(id int64, input FormInput) error
+
+	// SetFormActive starts or stops a form accepting submissions, e.g. during maintenance.
+	// Returns ErrNotFound if the form doesn't exist.
+	SetFormActive(id int64, active bool) error
 
 	// DeleteForm permanently deletes a form and all associated submissions.
 	// Returns an error if the form doesn't exist or deletion fails.
@@ -116,27 +366,180 @@ type Store interface {
 
 	// CreateSubmission creates a new submission for the specified form.
 	// Returns the created submission with denormalized client and form data.
+	// Returns ErrConflict if the form accepts one submission per email and the email already submitted.
+	// Returns ErrInvalidInput if the form has categories and the input names none of them.
 	CreateSubmission(formID int64, input SubmissionInput) (Submission, error)
 
 	// ListSubmissions returns a paginated list of submissions and the total count.
-	// Results include denormalized client and form names for display.
+	// Archived submissions are excluded. Results include denormalized client and form names for display.
 	// offset specifies how many records to skip, limit specifies max records to return.
 	ListSubmissions(offset, limit int) ([]Submission, int, error)
 
-	// FilterSubmissions returns a filtered paginated list of submissions and the total count.
-	// Filters can be applied by status, client ID, form ID, and subject search.
+	// ListSubmissionsAfter returns up to limit unarchived submissions, newest first, starting
+	// after the position encoded in cursor, plus the cursor for the next page. An empty
+	// cursor starts at the newest submission; an empty next cursor means there are no more.
+	// Unlike offsets, cursors don't skip or repeat rows when new submissions arrive.
+	// Returns ErrInvalidInput if the cursor is malformed.
+	ListSubmissionsAfter(cursor string, limit int) ([]Submission, string, error)
+
+	// ListClientSubmissions returns up to limit submissions of a client with IDs above afterID,
+	// oldest first, archived ones included with ArchivedAt set. Pass the last ID of one page as
+	// afterID to get the next; an empty result means there are no more. Used for data exports.
+	ListClientSubmissions(clientID, afterID int64, limit int) ([]Submission, error)
+
+	// FilterSubmissions returns a filtered, sorted, paginated list of submissions and the total count.
+	// Filters can be applied by status, client ID, form ID, assignee, category, and subject search.
 	// Empty/zero values for filters are ignored (no filtering applied for that field).
-	FilterSubmissions(offset, limit int, status string, clientID, formID int64, subjectSearch string) ([]Submission, int, error)
+	// Archived submissions are excluded unless the filter asks for them.
+	// Returns ErrInvalidInput if the sort field or direction is not allowed.
+	FilterSubmissions(offset, limit int, filter SubmissionFilter) ([]Submission, int, error)
 
 	// GetSubmission retrieves a submission by ID with denormalized client and form data.
+	// Archived submissions are returned too, with ArchivedAt set.
 	// Returns ErrNotFound if the submission doesn't exist.
 	GetSubmission(id int64) (Submission, error)
 
-	// UpdateSubmissionStatus updates the status of a submission.
-	// Valid statuses are OPEN, IN_PROGRESS, and CLOSED.
-	UpdateSubmissionStatus(id int64, status string) error
+	// GetSubmissionByToken retrieves a submission by its status token, for the public status page.
+	// Archived submissions are returned too. Returns ErrNotFound if no submission has the token.
+	GetSubmissionByToken(token string) (Submission, error)
+
+	// GetSubmissionByReference retrieves a submission by its reference number, ignoring case.
+	// Returns ErrNotFound if no submission has the reference.
+	GetSubmissionByReference(ref string) (Submission, error)
+
+	// UpdateSubmissionStatus updates the status of a submission and records actor as its last editor.
+	// Valid statuses are OPEN, IN_PROGRESS, CLOSED, and SPAM; any status can follow any other,
+	// so a CLOSED submission is reopened by setting it back to OPEN.
+	// Actual changes are added to the status history in the same transaction.
+	// Returns ErrNotFound if the submission doesn't exist.
+	UpdateSubmissionStatus(id int64, status, actor string) error
+
+	// GetStatusHistory returns the status transitions of a submission, oldest first.
+	GetStatusHistory(id int64) ([]StatusChange, error)
+
+	// ListSubmissionsModifiedBy returns a paginated list of the submissions admin last changed,
+	// newest first, and the total count. Archived submissions are included.
+	ListSubmissionsModifiedBy(admin string, offset, limit int) ([]Submission, int, error)
+
+	// ListSubmissionsWithInvalidEmail returns all unarchived submissions whose email failed strict
+	// validation, newest first, with denormalized client and form data.
+	ListSubmissionsWithInvalidEmail() ([]Submission, error)
+
+	// AssignSubmission sets the admin user responsible for a submission and records actor as
+	// its last editor. An empty assignee clears the assignment.
+	// Returns ErrNotFound if the submission doesn't exist.
+	AssignSubmission(id int64, assignee, actor string) error
+
+	// AddSubmissionNote adds an internal note to a submission.
+	// Returns ErrNotFound if the submission doesn't exist.
+	AddSubmissionNote(submissionID int64, author, body string) (SubmissionNote, error)
+
+	// ListSubmissionNotes returns all notes on a submission, oldest first.
+	ListSubmissionNotes(submissionID int64) ([]SubmissionNote, error)
+
+	// BulkUpdateStatus sets the status of several submissions in one transaction and records
+	// actor as their last editor, adding a status history entry for each actual change.
+	// Unknown IDs are skipped. Returns the number of submissions updated.
+	// Returns ErrInvalidInput if ids is empty or the status is invalid.
+	BulkUpdateStatus(ids []int64, status, actor string) (int64, error)
+
+	// BulkDelete permanently deletes several submissions, their notes, and their status history
+	// in one transaction.
+	// Unknown IDs are skipped. Returns the number of submissions deleted.
+	// Returns ErrInvalidInput if ids is empty.
+	BulkDelete(ids []int64) (int64, error)
+
+	// BulkCloseSubmissionsOlderThan closes every unarchived OPEN or IN_PROGRESS submission
+	// created before t, adding a system note with the reason to each one. The closed
+	// submissions are recorded as last changed by "system", also in their status history.
+	// All changes are made in one transaction. Returns the number of submissions closed.
+	// Returns ErrInvalidInput if t is zero or the reason is empty or too long.
+	BulkCloseSubmissionsOlderThan(t time.Time, reason string) (int64, error)
 
-	// DeleteSubmission permanently deletes a submission.
+	// DeleteSubmissionsOlderThan permanently deletes every submission created before cutoff,
+	// archived or not, with its notes and status history, in one transaction.
+	// Used by the retention job. Returns the number of submissions deleted.
+	// Returns ErrInvalidInput if cutoff is zero.
+	DeleteSubmissionsOlderThan(cutoff time.Time) (int64, error)
+
+	// DeleteSubmissionsByEmail permanently deletes every submission sent from email, across all
+	// clients and archived or not, with its notes and status history, in one transaction.
+	// Emails are matched case-insensitively, ignoring surrounding whitespace.
+	// Use it for erasure requests. Returns the number of submissions deleted.
+	// Returns ErrInvalidInput if email is empty.
+	DeleteSubmissionsByEmail(email string) (int64, error)
+
+	// ArchiveSubmission hides a submission from the default lists without deleting it.
+	// Archiving an already archived submission keeps its original archive time.
+	// Returns ErrNotFound if the submission doesn't exist.
+	ArchiveSubmission(id int64) error
+
+	// RestoreSubmission returns an archived submission to the default lists.
+	// Returns ErrNotFound if the submission doesn't exist.
+	RestoreSubmission(id int64) error
+
+	// DeleteSubmission permanently deletes a submission, its notes, and its status history,
+	// archived or not.
+	// Use it for erasure requests; ArchiveSubmission is the reversible option.
 	// Returns an error if the submission doesn't exist or deletion fails.
 	DeleteSubmission(id int64) error
+
+	// TopSubjects returns the most frequent submission subjects created between from and to,
+	// most common first. Subjects are grouped case-insensitively with surrounding whitespace ignored.
+	// Zero from/to values leave that side of the range open.
+	TopSubjects(limit int, from, to time.Time) ([]SubjectCount, error)
+
+	// CountSubmissionsByFormType returns the number of submissions received through each form type.
+	// Every known form type is present in the result, with a zero count if it has no submissions.
+	CountSubmissionsByFormType() (map[FormType]int, error)
+
+	// CountSubmissionsBySource returns the number of submissions received through each source.
+	// Every Source* constant is present in the result; submissions recorded before sources
+	// were tracked are counted under "unknown".
+	CountSubmissionsBySource() (map[string]int, error)
+
+	// BusiestForms returns the forms with the most submissions created at or after since,
+	// busiest first. Forms without submissions in the window are omitted.
+	BusiestForms(since time.Time, limit int) ([]FormActivity, error)
+
+	// FormSubmissionsByWeekday counts a form's submissions created between from and to,
+	// indexed by time.Weekday (Sunday = 0). Weekdays are taken in from's location (to's if
+	// from is zero), so callers pass times in the timezone they want to report in.
+	// Zero from/to values leave that side of the range open.
+	FormSubmissionsByWeekday(formID int64, from, to time.Time) ([7]int, error)
+
+	// SubmissionStats returns aggregate submission counts for the admin dashboard.
+	SubmissionStats() (SubmissionStats, error)
+
+	// MessageLengthDistribution returns a histogram of submission message lengths in characters.
+	// buckets are ascending lower bounds; each message is counted under the largest bound
+	// not exceeding its length, so the last bucket is open-ended. Messages shorter than the
+	// first bound are not counted. Every bound is present in the result.
+	// Returns ErrInvalidInput if buckets is empty, negative, or not strictly ascending.
+	MessageLengthDistribution(buckets []int) (map[int]int, error)
+
+	// CreateAdminUser creates a new admin user with an already-hashed password.
+	// Returns ErrConflict if the username is taken.
+	CreateAdminUser(username, passwordHash string) (AdminUser, error)
+
+	// ListAdminUsers returns all admin users ordered by username.
+	ListAdminUsers() ([]AdminUser, error)
+
+	// GetAdminUserByUsername retrieves an admin user by username.
+	// Returns ErrNotFound if the user doesn't exist.
+	GetAdminUserByUsername(username string) (AdminUser, error)
+
+	// CountAdminUsers returns the number of admin users.
+	CountAdminUsers() (int, error)
+
+	// DeleteAdminUser permanently deletes an admin user and unassigns their submissions.
+	// Returns ErrNotFound if the user doesn't exist.
+	DeleteAdminUser(id int64) error
+
+	// AddAuditEntry appends an entry to the audit log. ID and CreatedAt are assigned by the store.
+	// Returns ErrInvalidInput if the actor, action, or target type is missing or too long.
+	AddAuditEntry(entry AuditEntry) error
+
+	// ListAuditEntries returns a paginated list of audit log entries, newest first, and the total count.
+	ListAuditEntries(offset, limit int) ([]AuditEntry, int, error)
 }
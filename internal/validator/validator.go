@@ -5,9 +5,15 @@ package validator
 
 import (
 	"fmt"
+	"net"
 	"net/mail"
 	"net/url"
+	"strconv"
 	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/net/idna"
 
 	"ticketd/internal/errors"
 	"ticketd/internal/store"
@@ -15,17 +21,34 @@ import (
 
 const (
 	// Field length constraints
-	minNameLength    = 1
-	maxNameLength    = 255
-	minDomainLength  = 3
-	maxDomainLength  = 255
-	minEmailLength   = 3
-	maxEmailLength   = 255
-	minSubjectLength = 1
-	maxSubjectLength = 500
-	minMessageLength = 1
-	maxMessageLength = 10000
+	minNameLength     = 1
+	maxNameLength     = 255
+	minDomainLength   = 3
+	maxDomainLength   = 255
+	minEmailLength    = 3
+	maxEmailLength    = 255
+	minSubjectLength  = 1
+	maxSubjectLength  = 500
+	minMessageLength  = 1
+	maxMessageLength  = 10000
 	maxPriorityLength = 50
+	maxUsernameLength = 64
+	minPasswordLength = 8
+	maxPasswordLength = 72 // bcrypt ignores bytes beyond 72
+	maxNoteLength     = 10000
+	maxReasonLength   = 500
+	maxAuditLength    = 64
+	maxBulkIDs        = 500
+	maxPhoneLength    = 32
+	maxURLLength      = 2048
+	maxFontLength     = 100
+	maxCategories     = 20
+	maxCategoryLength = 64
+	maxBorderRadius   = 48
+	maxConsentLength  = 500
+	maxCustomCSS      = 20000
+	minPhoneDigits    = 7
+	maxPhoneDigits    = 15 // E.164 limit
 )
 
 // Status constants for submission status validation
@@ -33,8 +56,41 @@ const (
 	StatusOpen       = "OPEN"
 	StatusInProgress = "IN_PROGRESS"
 	StatusClosed     = "CLOSED"
+	StatusSpam       = "SPAM" // Matched the spam blocklist; stored for review instead of rejected
+)
+
+// Priority constants for submissions to forms with a priority field
+const (
+	PriorityLow    = "low"
+	PriorityMedium = "medium"
+	PriorityHigh   = "high"
 )
 
+// Limits holds the length limits for submission fields, in bytes.
+// They are configurable, unlike the limits for other inputs.
+type Limits struct {
+	MinName     int
+	MaxName     int
+	MinSubject  int
+	MaxSubject  int
+	MinMessage  int
+	MaxMessage  int
+	MaxPriority int
+}
+
+// DefaultLimits returns the submission field length limits used unless configured otherwise.
+func DefaultLimits() Limits {
+	return Limits{
+		MinName:     minNameLength,
+		MaxName:     maxNameLength,
+		MinSubject:  minSubjectLength,
+		MaxSubject:  maxSubjectLength,
+		MinMessage:  minMessageLength,
+		MaxMessage:  maxMessageLength,
+		MaxPriority: maxPriorityLength,
+	}
+}
+
 // ValidateFormType checks if the provided form type is valid.
 // Valid types are "support" and "contact".
 func ValidateFormType(formType store.FormType) error {
@@ -46,17 +102,217 @@ func ValidateFormType(formType store.FormType) error {
 	}
 }
 
+// ValidateLanguage checks that lang is a two-letter lowercase language code such as "en" or "de".
+// Whether the embed widget has translations for it is decided by the web layer, which falls
+// back to English.
+func ValidateLanguage(lang string) error {
+	if len(lang) != 2 || lang[0] < 'a' || lang[0] > 'z' || lang[1] < 'a' || lang[1] > 'z' {
+		return errors.InvalidInputError("language", "must be a two-letter code like \"en\"")
+	}
+	return nil
+}
+
+// ValidateSuccessURL checks that a form's success redirect is an absolute http or https URL.
+// Empty values are accepted and mean no redirect.
+func ValidateSuccessURL(rawURL string) error {
+	if rawURL == "" {
+		return nil
+	}
+
+	if len(rawURL) > maxURLLength {
+		return errors.InvalidInputError("success URL", fmt.Sprintf("must be at most %d characters", maxURLLength))
+	}
+
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return errors.InvalidInputError("success URL", "must be an absolute http or https URL")
+	}
+
+	return nil
+}
+
+// ValidateTheme checks embed theme overrides so they can be emitted as CSS values safely.
+// Colors must be hex (#rgb, #rrggbb, or #rrggbbaa), radii whole pixels up to 48px, and
+// fonts may only contain letters, digits, spaces, commas, hyphens, and quotes.
+// Empty values are accepted and mean the stylesheet default.
+func ValidateTheme(theme store.FormTheme) error {
+	if color := theme.PrimaryColor; color != "" {
+		valid := color[0] == '#' && (len(color) == 4 || len(color) == 7 || len(color) == 9)
+		for _, r := range color[1:] {
+			if !(r >= '0' && r <= '9' || r >= 'a' && r <= 'f' || r >= 'A' && r <= 'F') {
+				valid = false
+			}
+		}
+		if !valid {
+			return errors.InvalidInputError("primary color", "must be a hex color like #2563eb")
+		}
+	}
+
+	if radius := theme.BorderRadius; radius != "" {
+		px, err := strconv.Atoi(strings.TrimSuffix(radius, "px"))
+		if !strings.HasSuffix(radius, "px") || err != nil || px < 0 || px > maxBorderRadius || strings.HasPrefix(radius, "+") {
+			return errors.InvalidInputError("border radius", fmt.Sprintf("must be between 0px and %dpx", maxBorderRadius))
+		}
+	}
+
+	if font := theme.FontFamily; font != "" {
+		if len(font) > maxFontLength {
+			return errors.InvalidInputError("font family", fmt.Sprintf("must be at most %d characters", maxFontLength))
+		}
+		for _, r := range font {
+			if !(unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune(" ,-'\"", r)) {
+				return errors.InvalidInputError("font family", "may only contain letters, digits, spaces, commas, hyphens, and quotes")
+			}
+		}
+	}
+
+	return nil
+}
+
+// dangerousCSS lists constructs that custom CSS may not contain, lower-cased: ways to run
+// script or load other resources, and "</" so the CSS can't end a <style> element.
+var dangerousCSS = []string{"@import", "expression(", "javascript:", "vbscript:", "behavior:", "-moz-binding", "</"}
+
+// ValidateCustomCSS checks a client's custom stylesheet: at most 20000 characters, without
+// imports, script URLs, or the legacy IE and Firefox constructs that run code.
+// Empty CSS is accepted and means only the global stylesheet is served.
+func ValidateCustomCSS(css string) error {
+	if len(css) > maxCustomCSS {
+		return errors.InvalidInputError("custom CSS", fmt.Sprintf("must be at most %d characters", maxCustomCSS))
+	}
+	// Escapes could hide a keyword, so they are rejected outright. Comments and whitespace
+	// are dropped before the check, as browsers ignore them inside keywords like expression(
+	if strings.Contains(css, "\\") {
+		return errors.InvalidInputError("custom CSS", "cannot contain backslash escapes")
+	}
+	var compact strings.Builder
+	for rest := strings.ToLower(css); rest != ""; {
+		start := strings.Index(rest, "/*")
+		if start < 0 {
+			compact.WriteString(rest)
+			break
+		}
+		compact.WriteString(rest[:start])
+		end := strings.Index(rest[start+2:], "*/")
+		if end < 0 {
+			break
+		}
+		rest = rest[start+2+end+2:]
+	}
+	stripped := strings.Join(strings.Fields(compact.String()), "")
+	for _, construct := range dangerousCSS {
+		if strings.Contains(stripped, construct) {
+			return errors.InvalidInputError("custom CSS", fmt.Sprintf("cannot contain %s", construct))
+		}
+	}
+	return nil
+}
+
+// ValidateConsent checks a form's consent checkbox settings: a label of at most 500
+// characters without line breaks, and an optional absolute http(s) privacy policy URL.
+// An empty label is accepted and means the default label in the widget's language.
+func ValidateConsent(label, privacyURL string) error {
+	if err := ValidateString("consent label", label, 0, maxConsentLength, false); err != nil {
+		return err
+	}
+	if strings.ContainsAny(label, "\r\n") {
+		return errors.InvalidInputError("consent label", "cannot contain line breaks")
+	}
+	if privacyURL == "" {
+		return nil
+	}
+	if len(privacyURL) > maxURLLength {
+		return errors.InvalidInputError("privacy URL", fmt.Sprintf("must be at most %d characters", maxURLLength))
+	}
+	parsed, err := url.Parse(privacyURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return errors.InvalidInputError("privacy URL", "must be an absolute http or https URL")
+	}
+	return nil
+}
+
+// ValidateCategories checks the category options of a form: at most 20 options of
+// at most 64 characters each, without case-insensitive duplicates or line breaks.
+// An empty list is accepted and means the form has no category field.
+func ValidateCategories(categories []string) error {
+	if len(categories) > maxCategories {
+		return errors.InvalidInputError("categories", fmt.Sprintf("must be at most %d options", maxCategories))
+	}
+
+	seen := make(map[string]bool, len(categories))
+	for _, category := range categories {
+		if err := ValidateString("category", category, 1, maxCategoryLength, true); err != nil {
+			return err
+		}
+		if strings.ContainsAny(category, "\r\n") {
+			return errors.InvalidInputError("category", "cannot contain line breaks")
+		}
+		key := strings.ToLower(category)
+		if seen[key] {
+			return errors.InvalidInputError("categories", fmt.Sprintf("%q is listed twice", category))
+		}
+		seen[key] = true
+	}
+
+	return nil
+}
+
+// MatchCategory checks a submitted category against a form's options and returns the
+// option as configured, matching case-insensitively. Forms without options accept no
+// category and always return "". Forms with options require one of them.
+func MatchCategory(category string, options []string) (string, error) {
+	category = strings.TrimSpace(category)
+	if len(options) == 0 {
+		return "", nil
+	}
+	if category == "" {
+		return "", errors.InvalidInputError("category", "is required")
+	}
+	for _, option := range options {
+		if strings.EqualFold(option, category) {
+			return option, nil
+		}
+	}
+	return "", errors.InvalidInputError("category", "must be one of "+strings.Join(options, ", "))
+}
+
 // ValidateStatus checks if the provided status is valid.
-// Valid statuses are OPEN, IN_PROGRESS, and CLOSED.
+// Valid statuses are OPEN, IN_PROGRESS, CLOSED, and SPAM.
 func ValidateStatus(status string) error {
 	switch status {
-	case StatusOpen, StatusInProgress, StatusClosed:
+	case StatusOpen, StatusInProgress, StatusClosed, StatusSpam:
+		return nil
+	default:
+		return errors.InvalidInputError("status", fmt.Sprintf("must be %q, %q, %q, or %q", StatusOpen, StatusInProgress, StatusClosed, StatusSpam))
+	}
+}
+
+// ValidatePriority checks that priority is "low", "medium", or "high", ignoring case.
+func ValidatePriority(priority string) error {
+	switch strings.ToLower(priority) {
+	case PriorityLow, PriorityMedium, PriorityHigh:
 		return nil
 	default:
-		return errors.InvalidInputError("status", fmt.Sprintf("must be %q, %q, or %q", StatusOpen, StatusInProgress, StatusClosed))
+		return errors.InvalidInputError("priority", fmt.Sprintf("must be %q, %q, or %q", PriorityLow, PriorityMedium, PriorityHigh))
 	}
 }
 
+// ValidateSubmissionSort checks that a submission sort field and direction are allowed.
+// Empty values are accepted and mean the default ordering.
+func ValidateSubmissionSort(field, dir string) error {
+	switch field {
+	case "", store.SortCreatedAt, store.SortStatus, store.SortClient, store.SortPriority:
+	default:
+		return errors.InvalidInputError("sort", fmt.Sprintf("must be one of %q, %q, %q, or %q", store.SortCreatedAt, store.SortStatus, store.SortClient, store.SortPriority))
+	}
+	switch dir {
+	case "", store.SortAsc, store.SortDesc:
+	default:
+		return errors.InvalidInputError("sort direction", fmt.Sprintf("must be %q or %q", store.SortAsc, store.SortDesc))
+	}
+	return nil
+}
+
 // ValidateEmail checks if the provided email address is valid.
 func ValidateEmail(email string) error {
 	if email == "" {
@@ -81,6 +337,219 @@ func ValidateEmail(email string) error {
 	return nil
 }
 
+// ValidateEmailStrict checks that email is a plain, deliverable-looking address.
+// Unlike ValidateEmail it rejects empty values, display names ("Bob <bob@example.com>"),
+// and domains without a dot-separated, alphabetic top-level domain (e.g. "bob@localhost").
+// It is used to flag suspicious submissions, not to reject them.
+func ValidateEmailStrict(email string) error {
+	if email == "" {
+		return errors.InvalidInputError("email", "is required")
+	}
+
+	if err := ValidateEmail(email); err != nil {
+		return err
+	}
+
+	parsed, err := mail.ParseAddress(email)
+	if err != nil || parsed.Address != email {
+		return errors.InvalidInputError("email", "must be a plain address")
+	}
+
+	at := strings.LastIndex(email, "@")
+	local, domain := email[:at], strings.ToLower(email[at+1:])
+	if len(local) > 64 {
+		return errors.InvalidInputError("email", "local part is too long")
+	}
+
+	labels := strings.Split(domain, ".")
+	if len(domain) > 253 || len(labels) < 2 {
+		return errors.InvalidInputError("email", "invalid domain")
+	}
+	for _, label := range labels {
+		if label == "" || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return errors.InvalidInputError("email", "invalid domain")
+		}
+		for _, r := range label {
+			if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-') {
+				return errors.InvalidInputError("email", "invalid domain")
+			}
+		}
+	}
+	tld := labels[len(labels)-1]
+	if len(tld) < 2 || strings.Trim(tld, "abcdefghijklmnopqrstuvwxyz") != "" {
+		return errors.InvalidInputError("email", "invalid top-level domain")
+	}
+
+	return nil
+}
+
+// ValidatePhone checks that phone looks like an international (E.164-style) number.
+// Empty values are accepted because the field is optional. A leading "+" is allowed,
+// and spaces, dashes, dots, and parentheses are ignored as separators; 7-15 digits must remain.
+func ValidatePhone(phone string) error {
+	if phone == "" {
+		return nil
+	}
+
+	if len(phone) > maxPhoneLength {
+		return errors.InvalidInputError("phone", fmt.Sprintf("must be at most %d characters", maxPhoneLength))
+	}
+
+	digits := 0
+	for i, r := range phone {
+		switch {
+		case r >= '0' && r <= '9':
+			digits++
+		case r == '+' && i == 0:
+		case r == ' ' || r == '-' || r == '.' || r == '(' || r == ')':
+		default:
+			return errors.InvalidInputError("phone", "may only contain digits, a leading +, and separators")
+		}
+	}
+	if digits < minPhoneDigits || digits > maxPhoneDigits {
+		return errors.InvalidInputError("phone", fmt.Sprintf("must have between %d and %d digits", minPhoneDigits, maxPhoneDigits))
+	}
+
+	return nil
+}
+
+// phoneRegion describes how national phone numbers are written in a region.
+type phoneRegion struct {
+	callingCode string // Country calling code, without the +
+	trunkPrefix string // Prefix dialed before national numbers and dropped in E.164, if any
+}
+
+// phoneRegions lists the regions supported by NormalizePhone, keyed by ISO 3166-1 alpha-2 code.
+var phoneRegions = map[string]phoneRegion{
+	"AT": {"43", "0"}, "AU": {"61", "0"}, "BE": {"32", "0"}, "BR": {"55", "0"},
+	"CA": {"1", ""}, "CH": {"41", "0"}, "DE": {"49", "0"}, "DK": {"45", ""},
+	"ES": {"34", ""}, "FI": {"358", "0"}, "FR": {"33", "0"}, "GB": {"44", "0"},
+	"IE": {"353", "0"}, "IN": {"91", "0"}, "IT": {"39", ""}, "JP": {"81", "0"},
+	"MX": {"52", ""}, "NL": {"31", "0"}, "NO": {"47", ""}, "NZ": {"64", "0"},
+	"PL": {"48", ""}, "PT": {"351", ""}, "SE": {"46", "0"}, "US": {"1", ""},
+	"ZA": {"27", "0"},
+}
+
+// minNationalDigits is the shortest national number NormalizePhone accepts.
+const minNationalDigits = 4
+
+// ValidatePhoneRegion validates a default region for phone normalization.
+// An empty region is allowed and disables normalization of national numbers.
+func ValidatePhoneRegion(region string) error {
+	if region == "" {
+		return nil
+	}
+	if _, ok := phoneRegions[region]; !ok {
+		return errors.InvalidInputError("phone region", "must be a supported ISO 3166-1 alpha-2 code such as US or DE")
+	}
+	return nil
+}
+
+// NormalizePhone converts a phone number to E.164 (e.g. "+15551234567").
+// Numbers starting with + or 00 are taken as international; other numbers are read as
+// national numbers of defaultRegion. It returns false if the number cannot be normalized,
+// for example because it contains letters or is national and no region is configured.
+func NormalizePhone(phone, defaultRegion string) (string, bool) {
+	phone = strings.TrimSpace(phone)
+	if ValidatePhone(phone) != nil || phone == "" {
+		return "", false
+	}
+
+	// "+49 (0)30 ..." shows the trunk prefix for national dialing; it is not part of the number
+	if strings.HasPrefix(phone, "+") {
+		phone = strings.Replace(phone, "(0)", "", 1)
+	}
+
+	var digits strings.Builder
+	for _, r := range phone {
+		if r >= '0' && r <= '9' {
+			digits.WriteRune(r)
+		}
+	}
+	number := digits.String()
+
+	var e164 string
+	region, hasRegion := phoneRegions[defaultRegion]
+	switch {
+	case strings.HasPrefix(phone, "+"):
+		e164 = number
+	case strings.HasPrefix(number, "00"):
+		e164 = strings.TrimPrefix(number, "00")
+	case hasRegion && region.callingCode == "1" && strings.HasPrefix(number, "011"):
+		e164 = strings.TrimPrefix(number, "011") // North American international prefix
+	case hasRegion && region.callingCode == "1":
+		// North American numbers are 10 digits, optionally dialed with a leading 1
+		if len(number) == 11 && number[0] == '1' {
+			number = number[1:]
+		}
+		if len(number) != 10 {
+			return "", false
+		}
+		e164 = "1" + number
+	case hasRegion:
+		if region.trunkPrefix != "" {
+			number = strings.TrimPrefix(number, region.trunkPrefix)
+		}
+		if len(number) < minNationalDigits {
+			return "", false
+		}
+		e164 = region.callingCode + number
+	default:
+		return "", false
+	}
+
+	if len(e164) < minPhoneDigits || len(e164) > maxPhoneDigits || e164[0] == '0' {
+		return "", false
+	}
+	return "+" + e164, true
+}
+
+// AnonymizeIP zeroes the host part of a submitter IP address so that only its network
+// is kept: the last octet of an IPv4 address and the last 80 bits of an IPv6 address.
+// A port, as in "203.0.113.7:51234", is dropped. Values that aren't IP addresses are
+// replaced with an empty string, so nothing identifying is kept by mistake.
+func AnonymizeIP(ip string) string {
+	network, ok := IPNetwork(ip)
+	if !ok {
+		return ""
+	}
+	return network.IP.String()
+}
+
+// IPNetwork returns the network of a submitter IP address that AnonymizeIP keeps:
+// the /24 of an IPv4 address or the /48 of an IPv6 address. A port is dropped like in
+// AnonymizeIP. It returns false for values that aren't IP addresses.
+func IPNetwork(ip string) (*net.IPNet, bool) {
+	ip = strings.TrimSpace(ip)
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		ip = host
+	}
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return nil, false
+	}
+	if v4 := parsed.To4(); v4 != nil {
+		mask := net.CIDRMask(24, 32)
+		return &net.IPNet{IP: v4.Mask(mask), Mask: mask}, true
+	}
+	mask := net.CIDRMask(48, 128)
+	return &net.IPNet{IP: parsed.Mask(mask), Mask: mask}, true
+}
+
+// SuffixName appends suffix to name, e.g. " (copy)", first shortening name on a character
+// boundary where needed so the result still passes ValidateName.
+func SuffixName(name, suffix string) string {
+	name = strings.TrimSpace(name)
+	if room := maxNameLength - len(suffix); len(name) > room {
+		name = name[:max(room, 0)]
+		for name != "" && !utf8.ValidString(name) {
+			name = name[:len(name)-1]
+		}
+		name = strings.TrimSpace(name)
+	}
+	return name + suffix
+}
+
 // ValidateName validates a name field (client name, form name, etc.).
 func ValidateName(name string) error {
 	name = strings.TrimSpace(name)
@@ -129,6 +598,13 @@ func ValidateDomain(domain string) error {
 		return errors.InvalidInputError("domain", "invalid domain format")
 	}
 
+	// Internationalized domains are stored as punycode, so they must convert cleanly
+	if host := parsedURL.Hostname(); !isASCII(host) {
+		if _, err := idna.Lookup.ToASCII(host); err != nil {
+			return errors.InvalidInputError("domain", "invalid internationalized domain name")
+		}
+	}
+
 	return nil
 }
 
@@ -156,6 +632,115 @@ func ValidateString(fieldName, value string, minLength, maxLength int, required
 	return nil
 }
 
+// ValidateUsername validates an admin username.
+// Usernames must be non-empty, at most 64 characters, and contain no whitespace.
+func ValidateUsername(username string) error {
+	if username == "" {
+		return errors.InvalidInputError("username", "cannot be empty")
+	}
+
+	if len(username) > maxUsernameLength {
+		return errors.InvalidInputError("username", fmt.Sprintf("must be at most %d characters", maxUsernameLength))
+	}
+
+	if strings.ContainsAny(username, " \t\r\n") {
+		return errors.InvalidInputError("username", "cannot contain whitespace")
+	}
+
+	return nil
+}
+
+// ValidatePassword validates a new admin password before it is hashed.
+func ValidatePassword(password string) error {
+	if len(password) < minPasswordLength {
+		return errors.InvalidInputError("password", fmt.Sprintf("must be at least %d characters", minPasswordLength))
+	}
+
+	if len(password) > maxPasswordLength {
+		return errors.InvalidInputError("password", fmt.Sprintf("must be at most %d bytes", maxPasswordLength))
+	}
+
+	return nil
+}
+
+// ValidateNote validates an internal note before it is added to a submission.
+func ValidateNote(author, body string) error {
+	if err := ValidateString("author", author, 1, maxUsernameLength, true); err != nil {
+		return err
+	}
+
+	if err := ValidateString("body", body, 1, maxNoteLength, true); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ValidateCloseReason validates the reason recorded when submissions are closed in bulk.
+func ValidateCloseReason(reason string) error {
+	return ValidateString("reason", reason, 1, maxReasonLength, true)
+}
+
+// ValidateAuditEntry validates an audit log entry before it is stored.
+func ValidateAuditEntry(actor, action, targetType, detail string) error {
+	if err := ValidateString("actor", actor, 1, maxUsernameLength, true); err != nil {
+		return err
+	}
+
+	if err := ValidateString("action", action, 1, maxAuditLength, true); err != nil {
+		return err
+	}
+
+	if err := ValidateString("target type", targetType, 1, maxAuditLength, true); err != nil {
+		return err
+	}
+
+	if err := ValidateString("detail", detail, 0, maxNoteLength, false); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ValidateIDs validates the record IDs of a bulk operation.
+// The list must be non-empty, at most 500 long, and contain only positive IDs.
+func ValidateIDs(ids []int64) error {
+	if len(ids) == 0 {
+		return errors.InvalidInputError("ids", "cannot be empty")
+	}
+
+	if len(ids) > maxBulkIDs {
+		return errors.InvalidInputError("ids", fmt.Sprintf("must contain at most %d IDs", maxBulkIDs))
+	}
+
+	for _, id := range ids {
+		if id <= 0 {
+			return errors.InvalidInputError("ids", "must be positive")
+		}
+	}
+
+	return nil
+}
+
+// ValidateBuckets validates histogram bucket bounds.
+// Bounds must be non-empty, non-negative, and strictly ascending.
+func ValidateBuckets(buckets []int) error {
+	if len(buckets) == 0 {
+		return errors.InvalidInputError("buckets", "cannot be empty")
+	}
+
+	for i, bound := range buckets {
+		if bound < 0 {
+			return errors.InvalidInputError("buckets", "cannot be negative")
+		}
+		if i > 0 && bound <= buckets[i-1] {
+			return errors.InvalidInputError("buckets", "must be strictly ascending")
+		}
+	}
+
+	return nil
+}
+
 // ValidateClient validates client creation/update input.
 func ValidateClient(name, allowedDomain string) error {
 	if err := ValidateName(name); err != nil {
@@ -183,10 +768,10 @@ func ValidateForm(name string, formType store.FormType) error {
 }
 
 // ValidateSubmission validates submission input before storing in database.
-func ValidateSubmission(input store.SubmissionInput) error {
+func ValidateSubmission(input store.SubmissionInput, limits Limits) error {
 	// Name is optional for some form types
 	if input.Name != "" {
-		if err := ValidateString("name", input.Name, minNameLength, maxNameLength, false); err != nil {
+		if err := ValidateString("name", input.Name, limits.MinName, limits.MaxName, false); err != nil {
 			return err
 		}
 	}
@@ -196,21 +781,26 @@ func ValidateSubmission(input store.SubmissionInput) error {
 		return err
 	}
 
+	// Phone validation (optional field)
+	if err := ValidatePhone(input.Phone); err != nil {
+		return err
+	}
+
 	// Subject validation (optional field)
 	if input.Subject != "" {
-		if err := ValidateString("subject", input.Subject, minSubjectLength, maxSubjectLength, false); err != nil {
+		if err := ValidateString("subject", input.Subject, limits.MinSubject, limits.MaxSubject, false); err != nil {
 			return err
 		}
 	}
 
 	// Message is required
-	if err := ValidateString("message", input.Message, minMessageLength, maxMessageLength, true); err != nil {
+	if err := ValidateString("message", input.Message, limits.MinMessage, limits.MaxMessage, true); err != nil {
 		return err
 	}
 
 	// Priority is optional
 	if input.Priority != "" {
-		if err := ValidateString("priority", input.Priority, 1, maxPriorityLength, false); err != nil {
+		if err := ValidateString("priority", input.Priority, 1, limits.MaxPriority, false); err != nil {
 			return err
 		}
 	}
@@ -219,7 +809,7 @@ func ValidateSubmission(input store.SubmissionInput) error {
 }
 
 // TrimAndValidateClient trims whitespace and validates client input.
-// Returns the trimmed values and any validation error.
+// Returns the trimmed name, the domain normalized with NormalizeDomain, and any validation error.
 func TrimAndValidateClient(name, allowedDomain string) (string, string, error) {
 	name = strings.TrimSpace(name)
 	allowedDomain = strings.TrimSpace(allowedDomain)
@@ -228,18 +818,73 @@ func TrimAndValidateClient(name, allowedDomain string) (string, string, error) {
 		return "", "", err
 	}
 
-	return name, allowedDomain, nil
+	return name, NormalizeDomain(allowedDomain), nil
+}
+
+// NormalizeDomain returns an allowed domain in the form it is stored and compared in:
+// lower-cased punycode (see DomainToASCII), without scheme, path, or trailing dot.
+// A port is kept. For example, "https://Café.com/" becomes "xn--caf-dma.com".
+func NormalizeDomain(domain string) string {
+	domain = strings.ToLower(strings.TrimSpace(domain))
+	if i := strings.Index(domain, "://"); i >= 0 {
+		domain = domain[i+len("://"):]
+	}
+	if i := strings.IndexAny(domain, "/?#"); i >= 0 {
+		domain = domain[:i]
+	}
+	return DomainToASCII(strings.TrimSuffix(domain, "."))
+}
+
+// DomainToASCII returns a lower-cased host name, with an optional port, in its ASCII
+// (punycode) form, as browsers send it in Origin headers: "café.com" becomes
+// "xn--caf-dma.com". ASCII names and names that can't be converted are only lower-cased.
+func DomainToASCII(domain string) string {
+	domain = strings.ToLower(strings.TrimSpace(domain))
+	if isASCII(domain) {
+		return domain
+	}
+	host, port, err := net.SplitHostPort(domain)
+	if err != nil {
+		host, port = domain, ""
+	}
+	ascii, err := idna.Lookup.ToASCII(host)
+	if err != nil {
+		return domain
+	}
+	if port != "" {
+		return net.JoinHostPort(ascii, port)
+	}
+	return ascii
+}
+
+// isASCII reports whether s contains only ASCII characters.
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] > unicode.MaxASCII {
+			return false
+		}
+	}
+	return true
 }
 
 // TrimSubmissionInput trims whitespace from all string fields in submission input.
-func TrimSubmissionInput(input store.SubmissionInput) store.SubmissionInput {
+// It also sets PhoneE164 to the phone number normalized with NormalizePhone, reading
+// national numbers as phoneRegion numbers; PhoneE164 is empty if the phone can't be normalized.
+func TrimSubmissionInput(input store.SubmissionInput, phoneRegion string) store.SubmissionInput {
+	phoneE164, _ := NormalizePhone(input.Phone, phoneRegion)
 	return store.SubmissionInput{
 		Name:      strings.TrimSpace(input.Name),
 		Email:     strings.TrimSpace(input.Email),
+		Phone:     strings.TrimSpace(input.Phone),
+		PhoneE164: phoneE164,
 		Subject:   strings.TrimSpace(input.Subject),
 		Message:   strings.TrimSpace(input.Message),
 		Priority:  strings.TrimSpace(input.Priority),
+		Category:  strings.TrimSpace(input.Category),
 		IP:        strings.TrimSpace(input.IP),
 		UserAgent: strings.TrimSpace(input.UserAgent),
+		Source:    input.Source,
+		Spam:      input.Spam,
+		Consent:   strings.TrimSpace(input.Consent),
 	}
 }
@@ -0,0 +1,260 @@
+package validator
+
+import (
+	"strings"
+	"testing"
+
+	"ticketd/internal/errors"
+	"ticketd/internal/store"
+)
+
+func TestNormalizePhone(t *testing.T) {
+	tests := []struct {
+		name   string
+		phone  string
+		region string
+		want   string
+		wantOK bool
+	}{
+		{name: "E.164", phone: "+15551234567", region: "US", want: "+15551234567", wantOK: true},
+		{name: "US national with punctuation", phone: "(555) 123-4567", region: "US", want: "+15551234567", wantOK: true},
+		{name: "US national with leading 1", phone: "1-555-123-4567", region: "US", want: "+15551234567", wantOK: true},
+		{name: "US dots", phone: "555.123.4567", region: "US", want: "+15551234567", wantOK: true},
+		{name: "US international prefix", phone: "011 49 30 1234567", region: "US", want: "+49301234567", wantOK: true},
+		{name: "international with spaces", phone: "+49 30 1234567", want: "+49301234567", wantOK: true},
+		{name: "international with trunk prefix shown", phone: "+49 (0)30 1234567", want: "+49301234567", wantOK: true},
+		{name: "00 prefix", phone: "0049 30 1234567", want: "+49301234567", wantOK: true},
+		{name: "German national", phone: "030 1234567", region: "DE", want: "+49301234567", wantOK: true},
+		{name: "UK national", phone: "020 7946 0018", region: "GB", want: "+442079460018", wantOK: true},
+		{name: "Italian national keeps leading zero", phone: "06 1234 5678", region: "IT", want: "+390612345678", wantOK: true},
+		{name: "surrounding whitespace", phone: "  +15551234567 ", want: "+15551234567", wantOK: true},
+		{name: "national without region", phone: "(555) 123-4567"},
+		{name: "unsupported region", phone: "030 1234567", region: "XX"},
+		{name: "US national too short", phone: "123-4567", region: "US"},
+		{name: "letters", phone: "+1 555 CALL NOW", region: "US"},
+		{name: "too few digits", phone: "+12345", region: "US"},
+		{name: "too many digits", phone: "+1234567890123456", region: "US"},
+		{name: "empty", phone: "", region: "US"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := NormalizePhone(tt.phone, tt.region)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("NormalizePhone(%q, %q) = %q, %t, want %q, %t", tt.phone, tt.region, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestTrimSubmissionInputPhone(t *testing.T) {
+	tests := []struct {
+		phone    string
+		wantRaw  string
+		wantE164 string
+	}{
+		{phone: " (555) 123-4567 ", wantRaw: "(555) 123-4567", wantE164: "+15551234567"},
+		{phone: "+15551234567", wantRaw: "+15551234567", wantE164: "+15551234567"},
+		// Numbers that can't be normalized are kept as entered, without an E.164 form
+		{phone: "555-CALL-NOW", wantRaw: "555-CALL-NOW"},
+		{phone: "12345", wantRaw: "12345"},
+		{phone: "", wantRaw: ""},
+	}
+	for _, tt := range tests {
+		got := TrimSubmissionInput(store.SubmissionInput{Phone: tt.phone}, "US")
+		if got.Phone != tt.wantRaw || got.PhoneE164 != tt.wantE164 {
+			t.Errorf("TrimSubmissionInput(phone %q) = %q, %q, want %q, %q", tt.phone, got.Phone, got.PhoneE164, tt.wantRaw, tt.wantE164)
+		}
+	}
+}
+
+func TestAnonymizeIP(t *testing.T) {
+	tests := []struct {
+		ip          string
+		want        string
+		wantNetwork string
+	}{
+		{ip: "203.0.113.42", want: "203.0.113.0", wantNetwork: "203.0.113.0/24"},
+		{ip: "203.0.113.42:51234", want: "203.0.113.0", wantNetwork: "203.0.113.0/24"},
+		{ip: "::ffff:203.0.113.42", want: "203.0.113.0", wantNetwork: "203.0.113.0/24"},
+		{ip: "2001:db8:1:2:3:4:5:6", want: "2001:db8:1::", wantNetwork: "2001:db8:1::/48"},
+		{ip: "[2001:db8:1:2::6]:443", want: "2001:db8:1::", wantNetwork: "2001:db8:1::/48"},
+		{ip: "not-an-ip"},
+		{ip: ""},
+	}
+	for _, tt := range tests {
+		if got := AnonymizeIP(tt.ip); got != tt.want {
+			t.Errorf("AnonymizeIP(%q) = %q, want %q", tt.ip, got, tt.want)
+		}
+		network, ok := IPNetwork(tt.ip)
+		if ok != (tt.wantNetwork != "") || ok && network.String() != tt.wantNetwork {
+			t.Errorf("IPNetwork(%q) = %v, %t, want %q", tt.ip, network, ok, tt.wantNetwork)
+		}
+	}
+}
+
+func TestValidateCategories(t *testing.T) {
+	tests := []struct {
+		name       string
+		categories []string
+		wantErr    bool
+	}{
+		{name: "none", categories: nil},
+		{name: "options", categories: []string{"Billing", "Technical", "Other"}},
+		{name: "duplicate ignoring case", categories: []string{"Billing", "billing"}, wantErr: true},
+		{name: "line break", categories: []string{"Billing\nTechnical"}, wantErr: true},
+		{name: "too long", categories: []string{strings.Repeat("a", maxCategoryLength+1)}, wantErr: true},
+		{name: "too many", categories: make([]string, maxCategories+1), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateCategories(tt.categories)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("ValidateCategories(%q) error = %v, want error %t", tt.categories, err, tt.wantErr)
+			}
+			if err != nil && !errors.IsInvalidInput(err) {
+				t.Errorf("ValidateCategories(%q) error = %v, want invalid input", tt.categories, err)
+			}
+		})
+	}
+}
+
+func TestMatchCategory(t *testing.T) {
+	options := []string{"Billing", "Technical", "Other"}
+	tests := []struct {
+		category string
+		options  []string
+		want     string
+		wantErr  bool
+	}{
+		{category: "Billing", options: options, want: "Billing"},
+		{category: " technical ", options: options, want: "Technical"},
+		{category: "", options: options, wantErr: true},
+		{category: "Sales", options: options, wantErr: true},
+		// Forms without options ignore whatever was submitted
+		{category: "Billing", want: ""},
+		{category: "", want: ""},
+	}
+	for _, tt := range tests {
+		got, err := MatchCategory(tt.category, tt.options)
+		if got != tt.want || tt.wantErr != (err != nil) {
+			t.Errorf("MatchCategory(%q, %q) = %q, %v, want %q, error %t", tt.category, tt.options, got, err, tt.want, tt.wantErr)
+		}
+		if err != nil && !errors.IsInvalidInput(err) {
+			t.Errorf("MatchCategory(%q) error = %v, want invalid input", tt.category, err)
+		}
+	}
+}
+
+func TestNormalizeDomain(t *testing.T) {
+	tests := []struct {
+		domain string
+		want   string
+	}{
+		{domain: "example.com", want: "example.com"},
+		{domain: " https://Example.COM/contact?x=1 ", want: "example.com"},
+		{domain: "example.com.", want: "example.com"},
+		{domain: "localhost:3000", want: "localhost:3000"},
+		{domain: "café.com", want: "xn--caf-dma.com"},
+		{domain: "https://CAFÉ.com/", want: "xn--caf-dma.com"},
+		{domain: "xn--caf-dma.com", want: "xn--caf-dma.com"},
+		{domain: "menu.café.com:8443", want: "menu.xn--caf-dma.com:8443"},
+		{domain: "bücher.example", want: "xn--bcher-kva.example"},
+	}
+	for _, tt := range tests {
+		if got := NormalizeDomain(tt.domain); got != tt.want {
+			t.Errorf("NormalizeDomain(%q) = %q, want %q", tt.domain, got, tt.want)
+		}
+	}
+}
+
+func TestValidateDomainIDN(t *testing.T) {
+	for _, domain := range []string{"café.com", "https://café.com", "xn--caf-dma.com", "example.com"} {
+		if err := ValidateDomain(domain); err != nil {
+			t.Errorf("ValidateDomain(%q): %v", domain, err)
+		}
+	}
+	for _, domain := range []string{"-café.com", "café_shop.com"} {
+		if err := ValidateDomain(domain); !errors.IsInvalidInput(err) {
+			t.Errorf("ValidateDomain(%q) error = %v, want invalid input", domain, err)
+		}
+	}
+}
+
+func TestValidatePriority(t *testing.T) {
+	tests := []struct {
+		priority string
+		wantErr  bool
+	}{
+		{priority: PriorityLow},
+		{priority: PriorityMedium},
+		{priority: PriorityHigh},
+		{priority: "HIGH"},
+		{priority: "Medium"},
+		{priority: "", wantErr: true},
+		{priority: "URGENT!!!", wantErr: true},
+		{priority: " high ", wantErr: true},
+	}
+	for _, tt := range tests {
+		err := ValidatePriority(tt.priority)
+		if tt.wantErr != (err != nil) {
+			t.Errorf("ValidatePriority(%q) error = %v, want error %t", tt.priority, err, tt.wantErr)
+		}
+		if err != nil && !errors.IsInvalidInput(err) {
+			t.Errorf("ValidatePriority(%q) error = %v, want invalid input", tt.priority, err)
+		}
+	}
+}
+
+func TestSuffixName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "Support", want: "Support (copy)"},
+		{name: "  Support  ", want: "Support (copy)"},
+		{name: strings.Repeat("a", 248), want: strings.Repeat("a", 248) + " (copy)"},
+		{name: strings.Repeat("a", 255), want: strings.Repeat("a", 248) + " (copy)"},
+		{name: strings.Repeat("a", 247) + " b", want: strings.Repeat("a", 247) + " (copy)"},
+		// Two-byte characters are never cut in half
+		{name: strings.Repeat("é", 127), want: strings.Repeat("é", 124) + " (copy)"},
+	}
+	for _, tt := range tests {
+		got := SuffixName(tt.name, " (copy)")
+		if got != tt.want {
+			t.Errorf("SuffixName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+		if err := ValidateName(got); err != nil {
+			t.Errorf("SuffixName(%q) = %q, which fails ValidateName: %v", tt.name, got, err)
+		}
+	}
+}
+
+func TestValidateIDs(t *testing.T) {
+	tooMany := make([]int64, maxBulkIDs+1)
+	for i := range tooMany {
+		tooMany[i] = int64(i + 1)
+	}
+
+	tests := []struct {
+		name    string
+		ids     []int64
+		wantErr bool
+	}{
+		{name: "one", ids: []int64{1}},
+		{name: "several", ids: []int64{3, 1, 2}},
+		{name: "at the limit", ids: tooMany[:maxBulkIDs]},
+		{name: "empty", wantErr: true},
+		{name: "over the limit", ids: tooMany, wantErr: true},
+		{name: "zero", ids: []int64{1, 0}, wantErr: true},
+		{name: "negative", ids: []int64{-1}, wantErr: true},
+	}
+	for _, tt := range tests {
+		err := ValidateIDs(tt.ids)
+		if tt.wantErr != (err != nil) {
+			t.Errorf("%s: ValidateIDs error = %v, want error %t", tt.name, err, tt.wantErr)
+		}
+		if err != nil && !errors.IsInvalidInput(err) {
+			t.Errorf("%s: ValidateIDs error = %v, want invalid input", tt.name, err)
+		}
+	}
+}
@@ -1,8 +1,11 @@
 package web
 
 import (
+	"html/template"
 	"io/fs"
+	"log/slog"
 	"net/http"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
@@ -20,13 +23,23 @@ type App struct {
 	Templates  *templateCache
 	DefaultCSS []byte
 	AdminFS    fs.FS
+
+	sessionKey []byte
+	metrics    *metrics
+	spam       *spamBlocklist
+	disposable disposableDomains
+	dedup      *submissionDedup
+	feed       *submissionFeed
+	location   *time.Location // Timezone for time-of-day statistics
 }
 
 // NewApp creates a new App instance with all dependencies initialized.
-// It loads templates, default CSS, and admin assets.
+// It loads templates, default CSS (with the configured class prefix), and admin assets.
 // Returns an error if any initialization fails.
 func NewApp(cfg config.Config, st store.Store) (*App, error) {
-	tmpl, err := parseTemplates()
+	tmpl, err := parseTemplates(template.FuncMap{
+		"authEnabled": func() bool { return !cfg.DisableAuth },
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -34,26 +47,63 @@ func NewApp(cfg config.Config, st store.Store) (*App, error) {
 	if err != nil {
 		return nil, err
 	}
+	css = prefixEmbedClasses(css, cfg.EmbedClassPrefix)
 	adminFS, err := adminAssets()
 	if err != nil {
 		return nil, err
 	}
+	location, err := time.LoadLocation(cfg.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	sessionKey, err := newSessionKey(cfg.SessionSecret)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.SessionSecret == "" && !cfg.DisableAuth {
+		slog.Warn("TICKETD_SESSION_SECRET not set; using a random key, admin sessions will not survive restarts")
+	}
+	if cfg.DevAllowPrivateOrigins {
+		slog.Warn("TICKETD_DEV_ALLOW_PRIVATE_ORIGINS is enabled; any loopback or private-network origin can submit to every form. Do not use in production")
+	}
+	disposable, err := loadDisposableDomains(cfg.DisposableDomainsPath)
+	if err != nil {
+		return nil, err
+	}
+	if disposable != nil {
+		slog.Info("Loaded disposable email domains", "path", cfg.DisposableDomainsPath, "domains", len(disposable))
+	}
+
 	return &App{
 		Store:      st,
 		Cfg:        cfg,
 		Templates:  tmpl,
 		DefaultCSS: css,
 		AdminFS:    adminFS,
+		sessionKey: sessionKey,
+		metrics:    newMetrics(st),
+		spam:       newSpamBlocklist(cfg.SpamBlocklistPath),
+		disposable: disposable,
+		dedup:      newSubmissionDedup(cfg.DedupWindow),
+		feed:       newSubmissionFeed(),
+		location:   location,
 	}, nil
 }
 
+// CloseStreams ends all open submission streams. Call it when the server shuts down,
+// since streams would otherwise keep their connections busy until the shutdown timeout.
+func (a *App) CloseStreams() {
+	a.feed.close()
+}
+
 // Router creates and configures the HTTP router with all application routes.
 // It sets up middleware, public endpoints, and protected admin routes.
 func (a *App) Router() http.Handler {
 	r := chi.NewRouter()
 	r.Use(middleware.RequestID)
-	r.Use(middleware.RealIP)
+	r.Use(a.realIP)
 	r.Use(middleware.Recoverer)
+	r.Use(a.metrics.instrument)
 
 	// Static assets for admin interface
 	r.Handle("/admin/assets/*", http.StripPrefix("/admin/assets/", http.FileServer(http.FS(a.AdminFS))))
@@ -63,32 +113,71 @@ func (a *App) Router() http.Handler {
 		w.WriteHeader(http.StatusOK)
 		_, _ = w.Write([]byte("ok"))
 	})
+	r.Get("/ready", a.handleReady)
+	r.Handle("/metrics", a.metrics.handler())
 
 	r.Get("/embed/form.css", a.handleFormCSS)
 	r.Get("/embed/{formID}.js", a.handleEmbedJS)
+	r.Get("/embed/{formID}.css", a.handleFormClientCSS)
 	r.Options("/api/forms/{formID}/submit", a.handleSubmitOptions)
 	r.Post("/api/forms/{formID}/submit", a.handleSubmit)
+	r.Get("/api/forms/{formID}/start", a.handleFormStart)
+	r.Get("/forms/{formID}", a.handleHostedForm)
+	r.Get("/forms/{formID}/thanks", a.handleHostedFormThanks)
+	r.Get("/status/{token}", a.handleStatusPage)
+
+	// Admin session endpoints
+	r.Get("/admin/login", a.handleLoginPage)
+	r.Post("/admin/login", a.handleLogin)
+	r.Post("/admin/logout", a.handleLogout)
 
 	// Protected admin routes
 	r.Group(func(admin chi.Router) {
-		admin.Use(a.basicAuth)
+		admin.Use(a.requireSameOrigin)
+		admin.Use(a.requireSession)
 		admin.Get("/admin", func(w http.ResponseWriter, r *http.Request) {
-			http.Redirect(w, r, "/admin/submissions", http.StatusFound)
+			http.Redirect(w, r, "/admin/dashboard", http.StatusFound)
 		})
+		admin.Get("/admin/dashboard", a.handleAdminDashboard)
 		admin.Get("/admin/submissions", a.handleAdminSubmissions)
+		admin.Get("/admin/submissions/stream", a.handleAdminSubmissionStream)
 		admin.Get("/admin/submissions/{submissionID}", a.handleAdminSubmissionView)
 		admin.Post("/admin/submissions/{submissionID}/status", a.handleAdminUpdateSubmissionStatus)
+		admin.Post("/admin/submissions/{submissionID}/assign", a.handleAdminAssignSubmission)
+		admin.Post("/admin/submissions/{submissionID}/notes", a.handleAdminAddSubmissionNote)
+		admin.Post("/admin/submissions/bulk", a.handleAdminBulkSubmissions)
+		admin.Post("/admin/submissions/bulk-close", a.handleAdminBulkCloseSubmissions)
+		admin.Post("/admin/submissions/{submissionID}/archive", a.handleAdminArchiveSubmission)
+		admin.Post("/admin/submissions/{submissionID}/restore", a.handleAdminRestoreSubmission)
 		admin.Post("/admin/submissions/{submissionID}/delete", a.handleAdminDeleteSubmission)
+		admin.Post("/admin/gdpr/erase", a.handleAdminEraseByEmail)
+		admin.Get("/admin/forms", a.handleAdminAllForms)
+		admin.Get("/admin/forms/{formID}/preview", a.handleAdminFormPreview)
 		admin.Get("/admin/clients", a.handleAdminClients)
 		admin.Post("/admin/clients", a.handleAdminCreateClient)
 		admin.Get("/admin/clients/{clientID}/edit", a.handleAdminEditClient)
 		admin.Post("/admin/clients/{clientID}/edit", a.handleAdminUpdateClient)
 		admin.Post("/admin/clients/{clientID}/delete", a.handleAdminDeleteClient)
+		admin.Get("/admin/clients/{clientID}/export.json", a.handleAdminExportClient)
 		admin.Get("/admin/clients/{clientID}/forms", a.handleAdminForms)
 		admin.Post("/admin/clients/{clientID}/forms", a.handleAdminCreateForm)
 		admin.Get("/admin/clients/{clientID}/forms/{formID}/edit", a.handleAdminEditFormPage)
 		admin.Post("/admin/clients/{clientID}/forms/{formID}/edit", a.handleAdminUpdateForm)
+		admin.Post("/admin/clients/{clientID}/forms/{formID}/clone", a.handleAdminCloneForm)
+		admin.Post("/admin/clients/{clientID}/forms/{formID}/active", a.handleAdminSetFormActive)
 		admin.Post("/admin/clients/{clientID}/forms/{formID}/delete", a.handleAdminDeleteForm)
+		admin.Get("/admin/clients/{clientID}/forms/{formID}/stats", a.handleAdminFormStats)
+		admin.Get("/admin/audit", a.handleAdminAudit)
+		admin.Get("/admin/users", a.handleAdminUsers)
+		admin.Post("/admin/users", a.handleAdminCreateUser)
+		admin.Post("/admin/users/{userID}/delete", a.handleAdminDeleteUser)
+	})
+
+	// Protected JSON API for management UIs
+	r.Group(func(api chi.Router) {
+		api.Use(a.requireSession)
+		api.Get("/api/v1/clients/{clientID}/forms", a.handleAPIClientForms)
+		api.Get("/api/v1/submissions", a.handleAPISubmissions)
 	})
 
 	return r
@@ -0,0 +1,151 @@
+package web
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"ticketd/internal/config"
+	"ticketd/internal/store"
+	"ticketd/internal/store/sqlite"
+	"ticketd/internal/validator"
+)
+
+// newTestApp returns an App with the defaults of config.Load and authentication disabled,
+// backed by a migrated SQLite database in a temporary directory. configure, if not nil,
+// adjusts the configuration before the App is created.
+func newTestApp(t *testing.T, configure func(*config.Config)) *App {
+	t.Helper()
+	cfg := config.Config{
+		DisableAuth:      true,
+		Timezone:         "UTC",
+		SessionTTL:       12 * time.Hour,
+		EmbedTokenTTL:    365 * 24 * time.Hour,
+		EmbedCacheTTL:    5 * time.Minute,
+		EmbedClassPrefix: config.DefaultEmbedClassPrefix,
+		CORSMaxAge:       10 * time.Minute,
+		SpamAction:       config.SpamActionReject,
+		MaxBodyBytes:     config.DefaultMaxBodyBytes,
+		PageSize:         config.DefaultPageSize,
+		FormCreateLimit:  50,
+		FormCreateWindow: time.Hour,
+		SubmissionLimits: validator.DefaultLimits(),
+		ReferencePrefix:  "TKD",
+		ReferenceDigits:  6,
+	}
+	if configure != nil {
+		configure(&cfg)
+	}
+
+	st, err := sqlite.New(filepath.Join(t.TempDir(), "ticketd.db"), 0)
+	if err != nil {
+		t.Fatalf("sqlite.New: %v", err)
+	}
+	t.Cleanup(func() { st.Close() })
+	st.SetPageSize(cfg.PageSize)
+	st.SetPhoneRegion(cfg.PhoneRegion)
+	st.SetAnonymizeIP(cfg.AnonymizeIP)
+	st.SetSubmissionLimits(cfg.SubmissionLimits)
+	st.SetFormCreateLimit(cfg.FormCreateLimit, cfg.FormCreateWindow)
+	st.SetReferenceFormat(cfg.ReferencePrefix, cfg.ReferenceDigits)
+	if err := st.Migrate(); err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+
+	app, err := NewApp(cfg, st)
+	if err != nil {
+		t.Fatalf("NewApp: %v", err)
+	}
+	t.Cleanup(app.CloseStreams)
+	return app
+}
+
+// createTestForm creates a client allowing domain and a form of the given type for it.
+func createTestForm(t *testing.T, app *App, domain string, formType store.FormType) store.Form {
+	t.Helper()
+	client, err := app.Store.CreateClient("Client "+domain, domain)
+	if err != nil {
+		t.Fatalf("CreateClient(%q): %v", domain, err)
+	}
+	form, err := app.Store.CreateForm(client.ID, store.FormInput{Name: string(formType) + " form", Type: formType})
+	if err != nil {
+		t.Fatalf("CreateForm: %v", err)
+	}
+	return form
+}
+
+// serve runs req through the App's router and returns the recorded response.
+func serve(t *testing.T, app *App, req *http.Request) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	app.Router().ServeHTTP(rec, req)
+	return rec
+}
+
+// newSubmitRequest builds a submission to formID from origin with the given body and content type.
+func newSubmitRequest(formID int64, origin, contentType string, body io.Reader) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/api/forms/"+strconv.FormatInt(formID, 10)+"/submit", body)
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	return req
+}
+
+// jsonSubmission is a valid JSON submission body for any form type.
+const jsonSubmission = `{"name":"Jane Doe","email":"jane@example.com","subject":"Help","message":"Something is broken."}`
+
+// assertJSONError fails the test unless rec has the given status and a JSON body whose
+// error contains want.
+func assertJSONError(t *testing.T, rec *httptest.ResponseRecorder, status int, want string) {
+	t.Helper()
+	if rec.Code != status {
+		t.Fatalf("status = %d, want %d (body %q)", rec.Code, status, rec.Body.String())
+	}
+	if !strings.Contains(rec.Body.String(), `"error":`) || !strings.Contains(rec.Body.String(), want) {
+		t.Fatalf("body = %q, want error containing %q", rec.Body.String(), want)
+	}
+}
+
+// signIn creates the admin user username if needed and adds a valid session cookie for
+// it to req.
+func signIn(t *testing.T, app *App, req *http.Request, username string) {
+	t.Helper()
+	if _, err := app.Store.GetAdminUserByUsername(username); err != nil {
+		if _, err := app.Store.CreateAdminUser(username, "unused-hash"); err != nil {
+			t.Fatalf("CreateAdminUser(%q): %v", username, err)
+		}
+	}
+	req.AddCookie(app.newSessionCookie(req, username))
+}
+
+// newFormPost builds a URL-encoded POST of values to target.
+func newFormPost(target string, values url.Values) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(values.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return req
+}
+
+// createTestSubmission stores a valid submission to form.
+func createTestSubmission(t *testing.T, app *App, form store.Form) store.Submission {
+	t.Helper()
+	submission, err := app.Store.CreateSubmission(form.ID, store.SubmissionInput{
+		Name:    "Jane Doe",
+		Email:   "jane@example.com",
+		Subject: "Help",
+		Message: "Something is broken.",
+		IP:      "203.0.113.42",
+	})
+	if err != nil {
+		t.Fatalf("CreateSubmission: %v", err)
+	}
+	return submission
+}
@@ -0,0 +1,73 @@
+package web
+
+import (
+	"crypto/sha256"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// submissionDedup remembers recent submissions in memory so identical ones sent again
+// within a window, typically by bots, can be dropped. Entries expire after the window
+// and are swept out at most once per window, so memory stays bounded by the traffic
+// of one window.
+type submissionDedup struct {
+	window time.Duration
+
+	mu        sync.Mutex
+	seen      map[[sha256.Size]byte]time.Time
+	lastSweep time.Time
+}
+
+// newSubmissionDedup returns a dedup check for window, or nil if window is 0 (disabled).
+func newSubmissionDedup(window time.Duration) *submissionDedup {
+	if window <= 0 {
+		return nil
+	}
+	return &submissionDedup{window: window, seen: make(map[[sha256.Size]byte]time.Time)}
+}
+
+// duplicate reports whether the same email sent the same message to the form within
+// the window, and otherwise remembers this submission. Emails are compared ignoring case.
+// A nil dedup never reports duplicates.
+func (d *submissionDedup) duplicate(formID int64, email, message string) bool {
+	if d == nil {
+		return false
+	}
+	key := dedupKey(formID, email, message)
+	now := time.Now()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if now.Sub(d.lastSweep) >= d.window {
+		for k, at := range d.seen {
+			if now.Sub(at) >= d.window {
+				delete(d.seen, k)
+			}
+		}
+		d.lastSweep = now
+	}
+	if at, ok := d.seen[key]; ok && now.Sub(at) < d.window {
+		return true
+	}
+	d.seen[key] = now
+	return false
+}
+
+// forget drops a submission remembered by duplicate, so it can be sent again at once,
+// e.g. after it failed to save. A nil dedup ignores the call.
+func (d *submissionDedup) forget(formID int64, email, message string) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.seen, dedupKey(formID, email, message))
+}
+
+// dedupKey hashes the fields that make submissions identical. Only the hash is kept,
+// so the dedup check holds no submitter data in memory.
+func dedupKey(formID int64, email, message string) [sha256.Size]byte {
+	return sha256.Sum256([]byte(strconv.FormatInt(formID, 10) + "\x00" + strings.ToLower(email) + "\x00" + message))
+}
@@ -0,0 +1,129 @@
+package web
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"ticketd/internal/config"
+	"ticketd/internal/store"
+)
+
+// age moves every submission d remembers window further into the past.
+func (d *submissionDedup) age(window time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for key, at := range d.seen {
+		d.seen[key] = at.Add(-window)
+	}
+}
+
+func TestSubmissionDedup(t *testing.T) {
+	d := newSubmissionDedup(time.Minute)
+	if d.duplicate(1, "jane@example.com", "Help") {
+		t.Fatalf("first submission reported as duplicate")
+	}
+
+	tests := []struct {
+		name    string
+		formID  int64
+		email   string
+		message string
+		want    bool
+	}{
+		{name: "identical", formID: 1, email: "jane@example.com", message: "Help", want: true},
+		{name: "email in other case", formID: 1, email: "JANE@example.com", message: "Help", want: true},
+		{name: "other form", formID: 2, email: "jane@example.com", message: "Help"},
+		{name: "other email", formID: 1, email: "john@example.com", message: "Help"},
+		{name: "other message", formID: 1, email: "jane@example.com", message: "help"},
+	}
+	for _, tt := range tests {
+		if got := d.duplicate(tt.formID, tt.email, tt.message); got != tt.want {
+			t.Errorf("%s: duplicate = %t, want %t", tt.name, got, tt.want)
+		}
+	}
+
+	d.age(time.Minute)
+	if d.duplicate(1, "jane@example.com", "Help") {
+		t.Errorf("submission outside the window reported as duplicate")
+	}
+	if !d.duplicate(1, "jane@example.com", "Help") {
+		t.Errorf("repeat after the window expired not reported as duplicate")
+	}
+
+	d.forget(1, "jane@example.com", "Help")
+	if d.duplicate(1, "jane@example.com", "Help") {
+		t.Errorf("forgotten submission reported as duplicate")
+	}
+
+	if newSubmissionDedup(0) != nil {
+		t.Errorf("newSubmissionDedup(0) is enabled")
+	}
+	var disabled *submissionDedup
+	if disabled.duplicate(1, "jane@example.com", "Help") || disabled.duplicate(1, "jane@example.com", "Help") {
+		t.Errorf("nil dedup reported a duplicate")
+	}
+}
+
+func TestSubmissionDedupSweep(t *testing.T) {
+	d := newSubmissionDedup(time.Minute)
+	d.duplicate(1, "jane@example.com", "Help")
+	d.duplicate(1, "john@example.com", "Help")
+	d.age(time.Minute)
+	d.lastSweep = d.lastSweep.Add(-time.Minute)
+
+	d.duplicate(1, "joe@example.com", "Help")
+	if len(d.seen) != 1 {
+		t.Errorf("%d submissions remembered after the sweep, want 1", len(d.seen))
+	}
+}
+
+func TestSubmitDuplicate(t *testing.T) {
+	submit := func(t *testing.T, app *App, form store.Form) {
+		t.Helper()
+		rec := serve(t, app, newSubmitRequest(form.ID, "https://example.com", "application/json", strings.NewReader(jsonSubmission)))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want %d (body %q)", rec.Code, http.StatusOK, rec.Body.String())
+		}
+	}
+	stored := func(t *testing.T, app *App) int {
+		t.Helper()
+		_, total, err := app.Store.ListSubmissions(0, 10)
+		if err != nil {
+			t.Fatalf("ListSubmissions: %v", err)
+		}
+		return total
+	}
+
+	t.Run("within window", func(t *testing.T) {
+		app := newTestApp(t, func(cfg *config.Config) { cfg.DedupWindow = time.Minute })
+		form := createTestForm(t, app, "example.com", store.FormTypeSupport)
+		submit(t, app, form)
+		submit(t, app, form)
+		if total := stored(t, app); total != 1 {
+			t.Errorf("%d submissions stored, want 1", total)
+		}
+	})
+
+	t.Run("outside window", func(t *testing.T) {
+		app := newTestApp(t, func(cfg *config.Config) { cfg.DedupWindow = time.Minute })
+		form := createTestForm(t, app, "example.com", store.FormTypeSupport)
+		submit(t, app, form)
+		app.dedup.age(time.Minute)
+		submit(t, app, form)
+		if total := stored(t, app); total != 2 {
+			t.Errorf("%d submissions stored, want 2", total)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		app := newTestApp(t, nil)
+		form := createTestForm(t, app, "example.com", store.FormTypeSupport)
+		submit(t, app, form)
+		submit(t, app, form)
+		if total := stored(t, app); total != 2 {
+			t.Errorf("%d submissions stored, want 2", total)
+		}
+	})
+}
@@ -0,0 +1,50 @@
+package web
+
+import (
+	"strings"
+)
+
+// disposableDomains is a set of throwaway email domains, loaded once at startup.
+// A nil set blocks nothing.
+type disposableDomains map[string]struct{}
+
+// loadDisposableDomains reads one domain per line from path, skipping blank lines
+// and # comments. Returns nil if path is empty.
+func loadDisposableDomains(path string) (disposableDomains, error) {
+	if path == "" {
+		return nil, nil
+	}
+	lines, err := readBlocklist(path)
+	if err != nil {
+		return nil, err
+	}
+	domains := make(disposableDomains, len(lines))
+	for _, line := range lines {
+		domains[strings.TrimPrefix(line, "@")] = struct{}{}
+	}
+	return domains, nil
+}
+
+// blocked reports whether email's domain, or any parent domain of it, is in the set,
+// so listing "mailinator.com" also blocks "eu.mailinator.com".
+func (d disposableDomains) blocked(email string) bool {
+	if len(d) == 0 {
+		return false
+	}
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return false
+	}
+	domain := strings.TrimSuffix(strings.ToLower(email[at+1:]), ".")
+	for domain != "" {
+		if _, ok := d[domain]; ok {
+			return true
+		}
+		dot := strings.IndexByte(domain, '.')
+		if dot < 0 {
+			break
+		}
+		domain = domain[dot+1:]
+	}
+	return false
+}
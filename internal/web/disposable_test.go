@@ -0,0 +1,83 @@
+package web
+
+import (
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ticketd/internal/config"
+	"ticketd/internal/store"
+)
+
+func TestDisposableDomainsBlocked(t *testing.T) {
+	domains, err := loadDisposableDomains(writeTestFile(t, "disposable.txt", "# burner domains\nMailinator.com\n\n  @guerrillamail.com \n"))
+	if err != nil {
+		t.Fatalf("loadDisposableDomains: %v", err)
+	}
+
+	tests := []struct {
+		email string
+		want  bool
+	}{
+		{email: "spam@mailinator.com", want: true},
+		{email: "spam@MAILINATOR.COM", want: true},
+		{email: "spam@mailinator.com.", want: true},
+		{email: "spam@eu.mailinator.com", want: true},
+		{email: "spam@guerrillamail.com", want: true},
+		{email: "jane@example.com"},
+		{email: "jane@notmailinator.com"},
+		{email: "jane@mailinator.com.example"},
+		{email: "mailinator.com"},
+		{email: ""},
+	}
+	for _, tt := range tests {
+		if got := domains.blocked(tt.email); got != tt.want {
+			t.Errorf("blocked(%q) = %t, want %t", tt.email, got, tt.want)
+		}
+	}
+}
+
+func TestLoadDisposableDomains(t *testing.T) {
+	domains, err := loadDisposableDomains("")
+	if err != nil || domains != nil {
+		t.Errorf("loadDisposableDomains(\"\") = %v, %v, want nil, nil", domains, err)
+	}
+	if domains.blocked("spam@mailinator.com") {
+		t.Errorf("empty set blocked an address")
+	}
+	if _, err := loadDisposableDomains(filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Errorf("loadDisposableDomains(missing file) succeeded, want error")
+	}
+}
+
+func TestSubmitDisposableEmail(t *testing.T) {
+	path := writeTestFile(t, "disposable.txt", "mailinator.com\n")
+	disposable := strings.Replace(jsonSubmission, "jane@example.com", "jane@mailinator.com", 1)
+
+	t.Run("blocked", func(t *testing.T) {
+		app := newTestApp(t, func(cfg *config.Config) { cfg.DisposableDomainsPath = path })
+		form := createTestForm(t, app, "example.com", store.FormTypeSupport)
+
+		rec := serve(t, app, newSubmitRequest(form.ID, "https://example.com", "application/json", strings.NewReader(disposable)))
+		assertJSONError(t, rec, http.StatusBadRequest, "disposable email not allowed")
+		if _, total, _ := app.Store.ListSubmissions(0, 10); total != 0 {
+			t.Errorf("%d submissions stored, want 0", total)
+		}
+
+		rec = serve(t, app, newSubmitRequest(form.ID, "https://example.com", "application/json", strings.NewReader(jsonSubmission)))
+		if rec.Code != http.StatusOK {
+			t.Errorf("legitimate address: status = %d, want %d (body %q)", rec.Code, http.StatusOK, rec.Body.String())
+		}
+	})
+
+	t.Run("no list", func(t *testing.T) {
+		app := newTestApp(t, nil)
+		form := createTestForm(t, app, "example.com", store.FormTypeSupport)
+
+		rec := serve(t, app, newSubmitRequest(form.ID, "https://example.com", "application/json", strings.NewReader(disposable)))
+		if rec.Code != http.StatusOK {
+			t.Errorf("status = %d, want %d (body %q)", rec.Code, http.StatusOK, rec.Body.String())
+		}
+	})
+}
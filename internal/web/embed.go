@@ -1,47 +1,65 @@
 package web
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 
+	"ticketd/internal/config"
 	"ticketd/internal/store"
+	"ticketd/internal/validator"
 )
 
 // buildEmbedJS generates the JavaScript code for embedding a form on external websites.
 // The generated script is a self-contained IIFE that creates a form widget with:
-// - CSS loading (from the configured base URL)
-// - Form field generation based on form type (contact/support)
+// - CSS loading (the form's stylesheet, with its client's custom CSS, from the configured base URL)
+// - Form field generation based on form type (contact/support) and settings
 // - CORS-enabled form submission handling
-// - Success/error status display
+// - Success/error status display, with a link to the status page when one is issued
+// - A notice instead of the form while the form is disabled
+// - A required consent checkbox when the form asks for consent
+// - Calls to the host page's window.ticketdOnSuccess / window.ticketdOnError, if defined
+//
+// Labels and messages use the translations for lang, falling back to English. Class names
+// start with classPrefix, such as "ticketd-" for "ticketd-form".
+// When timed is set, the widget fetches a start token as it renders the form and sends
+// it along, for the minimum time-to-submit check.
 //
 // The script can be embedded using a <script> tag: <script src="https://yourserver.com/embed/{formID}.js"></script>
-func buildEmbedJS(form store.Form, client store.Client, baseURL string) (string, error) {
-	cssURL := fmt.Sprintf("%s/embed/form.css", baseURL)
+func buildEmbedJS(form store.Form, client store.Client, baseURL, lang, classPrefix string, timed bool) (string, error) {
+	cssURL := fmt.Sprintf("%s/embed/%d.css", baseURL, form.ID)
 	apiURL := fmt.Sprintf("%s/api/forms/%d/submit", baseURL, form.ID)
+	startURL := ""
+	if timed {
+		startURL = fmt.Sprintf("%s/api/forms/%d/start", baseURL, form.ID)
+	}
 	formTitle := fmt.Sprintf("%s - %s", client.Name, form.Name)
-
-	// Build form fields based on form type
-	fields := []map[string]any{
-		{"label": "Name", "name": "name", "type": "text"},
-		{"label": "Email", "name": "email", "type": "email"},
-		{"label": "Subject", "name": "subject", "type": "text"},
+	language := lookupEmbedLanguage(lang)
+	text := language.Text
+	// The submit URL carries an overridden language, so consent is recorded in the language shown
+	if language.Code != lookupEmbedLanguage(form.Language).Code {
+		apiURL += "?lang=" + language.Code
 	}
-	if form.Type == store.FormTypeSupport {
-		fields = append(fields, map[string]any{
-			"label":   "Priority",
-			"name":    "priority",
-			"type":    "select",
-			"options": []string{"low", "medium", "high"},
-		})
+	var consent map[string]string
+	if form.RequireConsent {
+		consent = map[string]string{"label": consentLabel(form, text), "privacyURL": form.PrivacyURL}
 	}
-	fields = append(fields, map[string]any{"label": "Message", "name": "message", "type": "textarea"})
 
 	payload := map[string]any{
-		"cssURL":   cssURL,
-		"apiURL":   apiURL,
-		"title":    formTitle,
-		"fields":   fields,
-		"formType": string(form.Type),
+		"formID":     form.ID,
+		"cssURL":     cssURL,
+		"apiURL":     apiURL,
+		"startURL":   startURL,
+		"title":      formTitle,
+		"fields":     embedFields(form, text),
+		"formType":   string(form.Type),
+		"lang":       language.Code,
+		"text":       text,
+		"successURL": form.SuccessURL,
+		"active":     form.Active,
+		"consent":    consent,
+		"theme":      embedTheme(form),
+		"prefix":     classPrefix,
 	}
 
 	data, err := json.Marshal(payload)
@@ -51,9 +69,29 @@ func buildEmbedJS(form store.Form, client store.Client, baseURL string) (string,
 
 	// Generate the self-contained JavaScript embed code
 	script := fmt.Sprintf(`(function(){
+  // Host pages can define callbacks, e.g. to track conversions. Both get the server's JSON
+  // response and the form ID; a missing or throwing callback doesn't affect the widget:
+  //   window.ticketdOnSuccess = function(response, formID) {}  // response: {status, reference, status_url}
+  //   window.ticketdOnError = function(response, formID) {}    // response: {error}, also for network errors
   var cfg = %s;
+  function notify(name, response) {
+    var callback = window[name];
+    if (typeof callback !== "function") {
+      return;
+    }
+    try {
+      callback(response, cfg.formID);
+    } catch (err) {
+      if (window.console) {
+        console.error("ticketd: " + name + " failed", err);
+      }
+    }
+  }
   var mount = document.createElement("div");
-  mount.className = "ticketd-embed";
+  mount.className = cfg.prefix + "embed";
+  Object.keys(cfg.theme).forEach(function(name){
+    mount.style.setProperty(name, cfg.theme[name]);
+  });
 
   // Try to find a container with data-ticketd-container attribute
   var container = document.querySelector('[data-ticketd-container]');
@@ -68,7 +106,11 @@ func buildEmbedJS(form store.Form, client store.Client, baseURL string) (string,
     }
   }
 
-  if (!document.querySelector('link[data-ticketd="true"]')) {
+  // Forms of different clients on one page each load their own stylesheet
+  var loaded = Array.prototype.some.call(document.querySelectorAll('link[data-ticketd="true"]'), function(el){
+    return el.href === cfg.cssURL;
+  });
+  if (!loaded) {
     var link = document.createElement("link");
     link.rel = "stylesheet";
     link.href = cfg.cssURL;
@@ -77,11 +119,23 @@ func buildEmbedJS(form store.Form, client store.Client, baseURL string) (string,
   }
 
   var form = document.createElement("form");
-  form.className = "ticketd-form";
+  form.className = cfg.prefix + "form";
+  form.lang = cfg.lang;
   var title = document.createElement("h3");
   title.textContent = cfg.title;
   form.appendChild(title);
 
+  // Disabled forms only show a notice, so visitors don't fill in a form that can't be sent
+  if (!cfg.active) {
+    var notice = document.createElement("div");
+    notice.className = cfg.prefix + "status";
+    notice.setAttribute("role", "status");
+    notice.textContent = cfg.text.inactive;
+    form.appendChild(notice);
+    mount.appendChild(form);
+    return;
+  }
+
   cfg.fields.forEach(function(field){
     var label = document.createElement("label");
     label.textContent = field.label;
@@ -93,8 +147,8 @@ func buildEmbedJS(form store.Form, client store.Client, baseURL string) (string,
       input = document.createElement("select");
       field.options.forEach(function(opt){
         var option = document.createElement("option");
-        option.value = opt;
-        option.textContent = opt;
+        option.value = opt.value;
+        option.textContent = opt.label;
         input.appendChild(option);
       });
     } else {
@@ -102,49 +156,109 @@ func buildEmbedJS(form store.Form, client store.Client, baseURL string) (string,
       input.type = field.type || "text";
     }
     input.name = field.name;
-    input.required = true;
+    if (field.placeholder) {
+      input.placeholder = field.placeholder;
+    }
+    input.required = !field.optional;
     form.appendChild(label);
     form.appendChild(input);
   });
 
+  if (cfg.startURL) {
+    var started = document.createElement("input");
+    started.type = "hidden";
+    started.name = "_started";
+    form.appendChild(started);
+    fetch(cfg.startURL, { mode: "cors" })
+      .then(function(res){ return res.json(); })
+      .then(function(body){ started.value = body.token || ""; })
+      .catch(function(){});
+  }
+
+  if (cfg.consent) {
+    var consentLabel = document.createElement("label");
+    consentLabel.className = cfg.prefix + "consent";
+    var consent = document.createElement("input");
+    consent.type = "checkbox";
+    consent.name = "consent";
+    consent.value = "true";
+    consent.required = true;
+    consentLabel.appendChild(consent);
+    consentLabel.appendChild(document.createTextNode(" " + cfg.consent.label));
+    if (cfg.consent.privacyURL) {
+      var privacy = document.createElement("a");
+      privacy.href = cfg.consent.privacyURL;
+      privacy.target = "_blank";
+      privacy.rel = "noopener";
+      privacy.textContent = cfg.text.privacyPolicy;
+      consentLabel.appendChild(document.createTextNode(" ("));
+      consentLabel.appendChild(privacy);
+      consentLabel.appendChild(document.createTextNode(")"));
+    }
+    form.appendChild(consentLabel);
+  }
+
   var button = document.createElement("button");
   button.type = "submit";
-  button.textContent = "Send";
+  button.textContent = cfg.text.send;
   form.appendChild(button);
 
   var status = document.createElement("div");
-  status.className = "ticketd-status";
+  status.className = cfg.prefix + "status";
   form.appendChild(status);
 
   form.addEventListener("submit", function(event){
     event.preventDefault();
-    status.textContent = "Sending...";
-    status.className = "ticketd-status";
+    status.textContent = cfg.text.sending;
+    status.className = cfg.prefix + "status";
     var payload = {};
     Array.prototype.forEach.call(form.elements, function(el){
       if (!el.name || el.type === "submit") {
         return;
       }
+      if (el.type === "checkbox") {
+        payload[el.name] = el.checked;
+        return;
+      }
       payload[el.name] = el.value;
     });
     fetch(cfg.apiURL, {
       method: "POST",
       mode: "cors",
-      headers: { "Content-Type": "application/json" },
+      headers: { "Content-Type": "application/json", "X-TicketD-Source": "js-widget" },
       body: JSON.stringify(payload)
     })
       .then(function(res){ return res.json().then(function(body){ return { ok: res.ok, body: body }; }); })
       .then(function(result){
         if (!result.ok) {
-          throw new Error(result.body && result.body.error ? result.body.error : "Failed");
+          var failure = new Error(result.body && result.body.error ? result.body.error : "Failed");
+          failure.response = result.body;
+          throw failure;
+        }
+        notify("ticketdOnSuccess", result.body);
+        if (cfg.successURL) {
+          window.location.href = cfg.successURL;
+          return;
+        }
+        status.textContent = cfg.text.success;
+        status.className = cfg.prefix + "status " + cfg.prefix + "success";
+        if (result.body && result.body.status_url) {
+          var link = document.createElement("a");
+          link.href = result.body.status_url;
+          link.target = "_blank";
+          link.rel = "noopener";
+          link.textContent = cfg.text.statusLink;
+          status.appendChild(document.createTextNode(" "));
+          status.appendChild(link);
         }
-        status.textContent = "Thanks! We'll be in touch.";
-        status.className = "ticketd-status ticketd-success";
         form.reset();
       })
       .catch(function(err){
-        status.textContent = err.message || "Failed to send.";
-        status.className = "ticketd-status ticketd-error";
+        // Server errors are in English, so show the localized message and keep the detail as a tooltip
+        status.textContent = cfg.text.error;
+        status.title = err.message || "";
+        status.className = cfg.prefix + "status " + cfg.prefix + "error";
+        notify("ticketdOnError", err.response || { error: err.message || "Failed" });
       });
   });
 
@@ -153,3 +267,70 @@ func buildEmbedJS(form store.Form, client store.Client, baseURL string) (string,
 
 	return script, nil
 }
+
+// embedFields returns the widget's fields for a form, labelled in the language of text.
+// The hosted no-JS form renders the same fields, so both stay in step.
+func embedFields(form store.Form, text embedText) []map[string]any {
+	// Build form fields based on form type
+	fields := []map[string]any{
+		{"label": text.Name, "placeholder": text.NamePlaceholder, "name": "name", "type": "text"},
+		{"label": text.Email, "placeholder": text.EmailPlaceholder, "name": "email", "type": "email"},
+		{"label": text.Subject, "placeholder": text.SubjectPlaceholder, "name": "subject", "type": "text"},
+	}
+	if form.Type == store.FormTypeSupport {
+		fields = append(fields, map[string]any{"label": text.Phone, "placeholder": text.PhonePlaceholder, "name": "phone", "type": "tel", "optional": true})
+	}
+	if hasPriorityField(form) {
+		options := []map[string]string{}
+		for _, value := range []string{validator.PriorityLow, validator.PriorityMedium, validator.PriorityHigh} {
+			options = append(options, map[string]string{"value": value, "label": text.Priorities[value]})
+		}
+		fields = append(fields, map[string]any{
+			"label":   text.Priority,
+			"name":    "priority",
+			"type":    "select",
+			"options": options,
+		})
+	}
+	if len(form.Categories) > 0 {
+		// The empty first option forces an explicit choice; category names are shown as configured
+		options := []map[string]string{{"value": "", "label": text.CategoryPlaceholder}}
+		for _, category := range form.Categories {
+			options = append(options, map[string]string{"value": category, "label": category})
+		}
+		fields = append(fields, map[string]any{
+			"label":   text.Category,
+			"name":    "category",
+			"type":    "select",
+			"options": options,
+		})
+	}
+	fields = append(fields, map[string]any{"label": text.Message, "placeholder": text.MessagePlaceholder, "name": "message", "type": "textarea"})
+	return fields
+}
+
+// embedTheme returns a form's theme as CSS custom properties for the mount element.
+func embedTheme(form store.Form) map[string]string {
+	// Values are validated on save
+	theme := map[string]string{}
+	if form.Theme.PrimaryColor != "" {
+		theme["--ticketd-primary"] = form.Theme.PrimaryColor
+	}
+	if form.Theme.BorderRadius != "" {
+		theme["--ticketd-radius"] = form.Theme.BorderRadius
+	}
+	if form.Theme.FontFamily != "" {
+		theme["--ticketd-font"] = form.Theme.FontFamily
+	}
+	return theme
+}
+
+// prefixEmbedClasses rewrites the class selectors of a stylesheet written for
+// config.DefaultEmbedClassPrefix, such as ".ticketd-form", to start with prefix instead.
+// Custom properties like --ticketd-primary keep their names.
+func prefixEmbedClasses(css []byte, prefix string) []byte {
+	if prefix == "" || prefix == config.DefaultEmbedClassPrefix {
+		return css
+	}
+	return bytes.ReplaceAll(css, []byte("."+config.DefaultEmbedClassPrefix), []byte("."+prefix))
+}
@@ -0,0 +1,170 @@
+package web
+
+import (
+	"strings"
+
+	"ticketd/internal/store"
+)
+
+// embedText holds the user-facing strings of the embed widget in one language.
+// It is serialized into the embed config, so the JSON names are read by the script.
+type embedText struct {
+	Name                string            `json:"name"`
+	NamePlaceholder     string            `json:"namePlaceholder"`
+	Email               string            `json:"email"`
+	EmailPlaceholder    string            `json:"emailPlaceholder"`
+	Phone               string            `json:"phone"`
+	PhonePlaceholder    string            `json:"phonePlaceholder"`
+	Subject             string            `json:"subject"`
+	SubjectPlaceholder  string            `json:"subjectPlaceholder"`
+	Priority            string            `json:"priority"`
+	Priorities          map[string]string `json:"priorities"` // Labels keyed by submitted value
+	Category            string            `json:"category"`
+	CategoryPlaceholder string            `json:"categoryPlaceholder"`
+	Message             string            `json:"message"`
+	MessagePlaceholder  string            `json:"messagePlaceholder"`
+	Send                string            `json:"send"`
+	Sending             string            `json:"sending"`
+	Success             string            `json:"success"`
+	Error               string            `json:"error"`
+	Inactive            string            `json:"inactive"`
+	Consent             string            `json:"consent"` // Default consent label, see consentLabel
+	PrivacyPolicy       string            `json:"privacyPolicy"`
+	StatusLink          string            `json:"statusLink"`
+	StatusTitle         string            `json:"-"` // Only used by the status page, not the widget
+	StatusLabel         string            `json:"-"`
+	Reference           string            `json:"-"`
+	Submitted           string            `json:"-"`
+	Statuses            map[string]string `json:"-"` // Labels keyed by stored status
+}
+
+// embedLanguage is a language the embed widget has translations for.
+type embedLanguage struct {
+	Code string
+	Name string // Native name, shown in the admin language picker
+	Text embedText
+}
+
+// embedLanguages lists the supported widget languages. The first entry is the fallback.
+var embedLanguages = []embedLanguage{
+	{
+		Code: "en",
+		Name: "English",
+		Text: embedText{
+			Name:                "Name",
+			NamePlaceholder:     "Your name",
+			Email:               "Email",
+			EmailPlaceholder:    "you@example.com",
+			Phone:               "Phone (optional)",
+			PhonePlaceholder:    "+1 555 123 4567",
+			Subject:             "Subject",
+			SubjectPlaceholder:  "What is this about?",
+			Priority:            "Priority",
+			Priorities:          map[string]string{"low": "Low", "medium": "Medium", "high": "High"},
+			Category:            "Category",
+			CategoryPlaceholder: "Choose a category",
+			Message:             "Message",
+			MessagePlaceholder:  "How can we help?",
+			Send:                "Send",
+			Sending:             "Sending...",
+			Success:             "Thanks! We'll be in touch.",
+			Error:               "Failed to send. Please try again.",
+			Inactive:            "This form is not currently accepting submissions.",
+			Consent:             "I agree that my details are stored and used to answer my request.",
+			PrivacyPolicy:       "Privacy policy",
+			StatusLink:          "Check the status of your request",
+			StatusTitle:         "Request status",
+			StatusLabel:         "Status",
+			Reference:           "Reference",
+			Submitted:           "Submitted",
+			Statuses:            map[string]string{"OPEN": "Received", "IN_PROGRESS": "In progress", "CLOSED": "Closed"},
+		},
+	},
+	{
+		Code: "de",
+		Name: "Deutsch",
+		Text: embedText{
+			Name:                "Name",
+			NamePlaceholder:     "Ihr Name",
+			Email:               "E-Mail",
+			EmailPlaceholder:    "sie@beispiel.de",
+			Phone:               "Telefon (optional)",
+			PhonePlaceholder:    "+49 30 1234567",
+			Subject:             "Betreff",
+			SubjectPlaceholder:  "Worum geht es?",
+			Priority:            "Priorität",
+			Priorities:          map[string]string{"low": "Niedrig", "medium": "Mittel", "high": "Hoch"},
+			Category:            "Kategorie",
+			CategoryPlaceholder: "Kategorie wählen",
+			Message:             "Nachricht",
+			MessagePlaceholder:  "Wie können wir helfen?",
+			Send:                "Senden",
+			Sending:             "Wird gesendet...",
+			Success:             "Danke! Wir melden uns bei Ihnen.",
+			Error:               "Senden fehlgeschlagen. Bitte versuchen Sie es erneut.",
+			Inactive:            "Dieses Formular nimmt derzeit keine Anfragen entgegen.",
+			Consent:             "Ich bin einverstanden, dass meine Angaben zur Bearbeitung meiner Anfrage gespeichert und verwendet werden.",
+			PrivacyPolicy:       "Datenschutzerklärung",
+			StatusLink:          "Status Ihrer Anfrage ansehen",
+			StatusTitle:         "Status der Anfrage",
+			StatusLabel:         "Status",
+			Reference:           "Referenz",
+			Submitted:           "Eingereicht",
+			Statuses:            map[string]string{"OPEN": "Eingegangen", "IN_PROGRESS": "In Bearbeitung", "CLOSED": "Abgeschlossen"},
+		},
+	},
+	{
+		Code: "fr",
+		Name: "Français",
+		Text: embedText{
+			Name:                "Nom",
+			NamePlaceholder:     "Votre nom",
+			Email:               "E-mail",
+			EmailPlaceholder:    "vous@exemple.fr",
+			Phone:               "Téléphone (facultatif)",
+			PhonePlaceholder:    "+33 1 23 45 67 89",
+			Subject:             "Objet",
+			SubjectPlaceholder:  "De quoi s'agit-il ?",
+			Priority:            "Priorité",
+			Priorities:          map[string]string{"low": "Basse", "medium": "Moyenne", "high": "Haute"},
+			Category:            "Catégorie",
+			CategoryPlaceholder: "Choisissez une catégorie",
+			Message:             "Message",
+			MessagePlaceholder:  "Comment pouvons-nous vous aider ?",
+			Send:                "Envoyer",
+			Sending:             "Envoi en cours...",
+			Success:             "Merci ! Nous vous répondrons rapidement.",
+			Error:               "L'envoi a échoué. Veuillez réessayer.",
+			Inactive:            "Ce formulaire n'accepte pas de demandes pour le moment.",
+			Consent:             "J'accepte que mes données soient enregistrées et utilisées pour traiter ma demande.",
+			PrivacyPolicy:       "Politique de confidentialité",
+			StatusLink:          "Suivre l'état de votre demande",
+			StatusTitle:         "État de la demande",
+			StatusLabel:         "État",
+			Reference:           "Référence",
+			Submitted:           "Envoyée le",
+			Statuses:            map[string]string{"OPEN": "Reçue", "IN_PROGRESS": "En cours", "CLOSED": "Clôturée"},
+		},
+	},
+}
+
+// consentLabel returns the text of a form's consent checkbox: the form's own label,
+// or the default in the language of text.
+func consentLabel(form store.Form, text embedText) string {
+	if form.ConsentLabel != "" {
+		return form.ConsentLabel
+	}
+	return text.Consent
+}
+
+// lookupEmbedLanguage returns the translations for lang, falling back to English
+// for empty or unsupported codes.
+func lookupEmbedLanguage(lang string) embedLanguage {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	for _, l := range embedLanguages {
+		if l.Code == lang {
+			return l
+		}
+	}
+	return embedLanguages[0]
+}
@@ -0,0 +1,69 @@
+package web
+
+import (
+	"sync"
+
+	"ticketd/internal/store"
+)
+
+// maxFeedSubscribers caps the open submission streams, each of which holds a connection.
+const maxFeedSubscribers = 50
+
+// feedBuffer is how many submissions a subscriber may fall behind before it misses some.
+const feedBuffer = 16
+
+// submissionFeed is an in-process pub/sub for newly created submissions.
+// Publishing never blocks: a subscriber whose buffer is full misses the submission.
+type submissionFeed struct {
+	mu          sync.Mutex
+	subscribers map[chan store.Submission]struct{}
+
+	done      chan struct{} // Closed by close to end all streams
+	closeOnce sync.Once
+}
+
+// newSubmissionFeed returns a feed without subscribers.
+func newSubmissionFeed() *submissionFeed {
+	return &submissionFeed{subscribers: map[chan store.Submission]struct{}{}, done: make(chan struct{})}
+}
+
+// subscribe registers a new subscriber and returns its channel.
+// Returns false if maxFeedSubscribers are already subscribed.
+func (f *submissionFeed) subscribe() (chan store.Submission, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(f.subscribers) >= maxFeedSubscribers {
+		return nil, false
+	}
+	ch := make(chan store.Submission, feedBuffer)
+	f.subscribers[ch] = struct{}{}
+	return ch, true
+}
+
+// unsubscribe removes a subscriber. Its channel is not closed, so a concurrent
+// publish can't send on a closed channel.
+func (f *submissionFeed) unsubscribe(ch chan store.Submission) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	delete(f.subscribers, ch)
+}
+
+// close tells all subscribers to stop, e.g. on server shutdown.
+func (f *submissionFeed) close() {
+	f.closeOnce.Do(func() { close(f.done) })
+}
+
+// publish sends a submission to every subscriber that has room for it.
+func (f *submissionFeed) publish(sub store.Submission) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for ch := range f.subscribers {
+		select {
+		case ch <- sub:
+		default:
+		}
+	}
+}
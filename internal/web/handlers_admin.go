@@ -1,38 +1,85 @@
 package web
 
 import (
+	"encoding/json"
 	"fmt"
+	"log/slog"
 	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 
+	apperrors "ticketd/internal/errors"
 	"ticketd/internal/store"
+	"ticketd/internal/validator"
 )
 
-// handleAdminSubmissions displays a paginated, filterable list of form submissions.
-// Supports filtering by status, client, form, and subject search.
+// handleAdminSubmissions displays a paginated, filterable, sortable list of form submissions.
+// Supports filtering by status, client, form, assignee, category, and subject search, and sorting by
+// created_at, status, client, or priority via the sort and dir query parameters.
 // Submissions without a status are defaulted to "OPEN".
 func (a *App) handleAdminSubmissions(w http.ResponseWriter, r *http.Request) {
 	page := parsePage(r)
-	offset := (page - 1) * pageSize
+	size := a.pageSize(r)
+	perPage := a.perPageParam(size)
+	offset := (page - 1) * size
 
 	// Parse filter parameters
-	status := r.URL.Query().Get("status")
-	clientID, _ := parseID(r.URL.Query().Get("client"))
-	formID, _ := parseID(r.URL.Query().Get("form"))
-	subjectSearch := strings.TrimSpace(r.URL.Query().Get("search"))
+	query := r.URL.Query()
+	filter := store.SubmissionFilter{
+		Status:    query.Get("status"),
+		Search:    strings.TrimSpace(query.Get("search")),
+		SortField: query.Get("sort"),
+		SortDir:   query.Get("dir"),
+	}
+	filter.ClientID, _ = parseID(query.Get("client"))
+	filter.FormID, _ = parseID(query.Get("form"))
+	filter.InvalidEmail = query.Get("email") == invalidEmailFilter
+	filter.Category = strings.TrimSpace(query.Get("category"))
+	filterArchived := query.Get("archived")
+	switch filterArchived {
+	case archivedOnlyFilter:
+		filter.ArchivedOnly = true
+	case archivedIncludeFilter:
+		filter.IncludeArchived = true
+	default:
+		filterArchived = ""
+	}
+	filterAssignee := strings.TrimSpace(query.Get("assignee"))
+	if filterAssignee == unassignedFilter {
+		filter.Unassigned = true
+	} else {
+		filter.Assignee = filterAssignee
+	}
+
+	// Searching for a ticket reference jumps straight to that ticket
+	if filter.Search != "" {
+		if submission, err := a.Store.GetSubmissionByReference(filter.Search); err == nil {
+			http.Redirect(w, r, fmt.Sprintf("/admin/submissions/%d", submission.ID), http.StatusSeeOther)
+			return
+		}
+	}
+
+	// Reject unknown sort fields up front rather than silently ignoring them
+	if err := validator.ValidateSubmissionSort(filter.SortField, filter.SortDir); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-	// Use filtering if any filters are provided
+	// Use filtering if any filters or sorting are provided
 	var subs []store.Submission
 	var total int
 	var err error
 
-	hasFilters := status != "" || clientID > 0 || formID > 0 || subjectSearch != ""
-	if hasFilters {
-		subs, total, err = a.Store.FilterSubmissions(offset, pageSize, status, clientID, formID, subjectSearch)
+	hasFilters := filter.Status != "" || filter.ClientID > 0 || filter.FormID > 0 || filter.Search != "" || filterAssignee != "" || filter.Category != "" || filter.InvalidEmail || filterArchived != ""
+	if hasFilters || filter.SortField != "" || filter.SortDir != "" {
+		subs, total, err = a.Store.FilterSubmissions(offset, size, filter)
 	} else {
-		subs, total, err = a.Store.ListSubmissions(offset, pageSize)
+		subs, total, err = a.Store.ListSubmissions(offset, size)
 	}
 
 	if err != nil {
@@ -43,12 +90,13 @@ func (a *App) handleAdminSubmissions(w http.ResponseWriter, r *http.Request) {
 	items := make([]submissionView, 0, len(subs))
 	for _, sub := range subs {
 		if sub.Status == "" {
-			sub.Status = "OPEN"
+			sub.Status = validator.StatusOpen
 		}
 		items = append(items, submissionView{
 			Submission: sub,
 			CreatedAt:  formatTime(sub.CreatedAt),
 			FormType:   string(sub.FormType),
+			IP:         a.displayIP(sub.IP),
 		})
 	}
 
@@ -59,28 +107,152 @@ func (a *App) handleAdminSubmissions(w http.ResponseWriter, r *http.Request) {
 		forms, _ := a.Store.ListForms(client.ID)
 		allForms = append(allForms, forms...)
 	}
+	users, _ := a.Store.ListAdminUsers()
+
+	// Offer every category configured on any form, once
+	categories := []string{}
+	seenCategories := map[string]bool{}
+	for _, form := range allForms {
+		for _, category := range form.Categories {
+			if !seenCategories[category] {
+				seenCategories[category] = true
+				categories = append(categories, category)
+			}
+		}
+	}
+	sort.Strings(categories)
+
+	sortField, sortDir := filter.SortField, filter.SortDir
+	if sortField == "" {
+		sortField = store.SortCreatedAt
+	}
+	if sortDir == "" {
+		sortDir = store.SortDesc
+	}
 
 	data := submissionsPage{
-		Active:        "submissions",
-		Submissions:   items,
-		Page:          page,
-		Total:         total,
-		TotalPages:    totalPages(total),
-		PrevPage:      prevPage(page),
-		NextPage:      nextPage(page, total),
-		Clients:       clients,
-		Forms:         allForms,
-		FilterStatus:  status,
-		FilterClient:  clientID,
-		FilterForm:    formID,
-		FilterSearch:  subjectSearch,
-		HasFilters:    hasFilters,
-		ResultsCount:  len(subs),
+		Active:         "submissions",
+		Submissions:    items,
+		Page:           page,
+		Total:          total,
+		TotalPages:     totalPages(total, size),
+		PrevPage:       prevPage(page),
+		NextPage:       nextPage(page, total, size),
+		Clients:        clients,
+		Forms:          allForms,
+		FilterStatus:   filter.Status,
+		FilterClient:   filter.ClientID,
+		FilterForm:     filter.FormID,
+		FilterSearch:   filter.Search,
+		FilterAssignee: filterAssignee,
+		FilterCategory: filter.Category,
+		FilterInvalid:  filter.InvalidEmail,
+		FilterArchived: filterArchived,
+		ReturnQuery:    r.URL.RawQuery,
+		PerPage:        perPage,
+		Users:          users,
+		Categories:     categories,
+		HasFilters:     hasFilters,
+		ResultsCount:   len(subs),
+		SortField:      sortField,
+		SortDir:        sortDir,
+		SortHeaders:    map[string]sortHeader{},
+	}
+	// Set by handleAdminBulkCloseSubmissions when it redirects back here
+	if closed, err := strconv.Atoi(query.Get("closed")); err == nil && closed >= 0 {
+		data.BulkClosed = strconv.Itoa(closed)
+	}
+	// Set by handleAdminEraseByEmail
+	if erased, err := strconv.Atoi(query.Get("erased")); err == nil && erased >= 0 {
+		data.Erased = strconv.Itoa(erased)
+	}
+	if data.PrevPage > 0 {
+		data.PrevURL = submissionsURL(filter, data.PrevPage, perPage)
+	}
+	if data.NextPage > 0 {
+		data.NextURL = submissionsURL(filter, data.NextPage, perPage)
+	}
+	sortLabels := map[string]string{
+		store.SortCreatedAt: "Received",
+		store.SortStatus:    "Status",
+		store.SortClient:    "Client",
+		store.SortPriority:  "Priority",
+	}
+	for field, label := range sortLabels {
+		sorted := filter
+		sorted.SortField = field
+		sorted.SortDir = store.SortAsc
+		if field == store.SortCreatedAt {
+			sorted.SortDir = store.SortDesc
+		}
+		// Clicking the active column toggles its direction
+		if field == sortField {
+			sorted.SortDir = store.SortAsc
+			if sortDir == store.SortAsc {
+				sorted.SortDir = store.SortDesc
+			}
+		}
+		data.SortHeaders[field] = sortHeader{
+			Label:  label,
+			URL:    submissionsURL(sorted, 1, perPage),
+			Active: field == sortField,
+			Dir:    sortDir,
+		}
 	}
 
 	a.renderTemplate(w, r, "submissions.html", data)
 }
 
+// submissionsURL builds a submissions list URL that preserves the given filters and sort order.
+// A zero perPage leaves the page size at the configured default.
+func submissionsURL(filter store.SubmissionFilter, page, perPage int) string {
+	values := url.Values{}
+	if page > 1 {
+		values.Set("page", strconv.Itoa(page))
+	}
+	if perPage > 0 {
+		values.Set("per_page", strconv.Itoa(perPage))
+	}
+	if filter.Status != "" {
+		values.Set("status", filter.Status)
+	}
+	if filter.ClientID > 0 {
+		values.Set("client", strconv.FormatInt(filter.ClientID, 10))
+	}
+	if filter.FormID > 0 {
+		values.Set("form", strconv.FormatInt(filter.FormID, 10))
+	}
+	if filter.Search != "" {
+		values.Set("search", filter.Search)
+	}
+	if filter.Unassigned {
+		values.Set("assignee", unassignedFilter)
+	} else if filter.Assignee != "" {
+		values.Set("assignee", filter.Assignee)
+	}
+	if filter.Category != "" {
+		values.Set("category", filter.Category)
+	}
+	if filter.InvalidEmail {
+		values.Set("email", invalidEmailFilter)
+	}
+	if filter.ArchivedOnly {
+		values.Set("archived", archivedOnlyFilter)
+	} else if filter.IncludeArchived {
+		values.Set("archived", archivedIncludeFilter)
+	}
+	if filter.SortField != "" {
+		values.Set("sort", filter.SortField)
+	}
+	if filter.SortDir != "" {
+		values.Set("dir", filter.SortDir)
+	}
+	if len(values) == 0 {
+		return "/admin/submissions"
+	}
+	return "/admin/submissions?" + values.Encode()
+}
+
 // handleAdminSubmissionView displays the details of a single submission.
 // It shows all submission fields and allows updating the status or deleting the submission.
 func (a *App) handleAdminSubmissionView(w http.ResponseWriter, r *http.Request) {
@@ -95,18 +267,50 @@ func (a *App) handleAdminSubmissionView(w http.ResponseWriter, r *http.Request)
 		return
 	}
 	if submission.Status == "" {
-		submission.Status = "OPEN"
+		submission.Status = validator.StatusOpen
+	}
+	users, _ := a.Store.ListAdminUsers()
+	notes, err := a.Store.ListSubmissionNotes(submissionID)
+	if err != nil {
+		http.Error(w, "failed to load notes", http.StatusInternalServerError)
+		return
+	}
+	noteViews := make([]noteView, 0, len(notes))
+	for _, note := range notes {
+		noteViews = append(noteViews, noteView{
+			SubmissionNote: note,
+			CreatedAt:      formatTime(note.CreatedAt),
+		})
+	}
+	history, err := a.Store.GetStatusHistory(submissionID)
+	if err != nil {
+		http.Error(w, "failed to load status history", http.StatusInternalServerError)
+		return
+	}
+	historyViews := make([]statusChangeView, 0, len(history))
+	for _, change := range history {
+		historyViews = append(historyViews, statusChangeView{
+			StatusChange: change,
+			CreatedAt:    formatTime(change.CreatedAt),
+		})
 	}
 	data := submissionPage{
 		Active:     "submissions",
 		Submission: submission,
 		CreatedAt:  formatTime(submission.CreatedAt),
+		IP:         a.displayIP(submission.IP),
+		Users:      users,
+		Notes:      noteViews,
+		History:    historyViews,
+	}
+	if !submission.ArchivedAt.IsZero() {
+		data.ArchivedAt = formatTime(submission.ArchivedAt)
 	}
 	a.renderTemplate(w, r, "submission.html", data)
 }
 
 // handleAdminUpdateSubmissionStatus updates the status of a submission.
-// Valid statuses are: OPEN, IN_PROGRESS, CLOSED (note: IN_PROGRESS not "IN PROGRESS").
+// Valid statuses are the validator.Status* constants.
 // Redirects back to the submission view page after successful update.
 func (a *App) handleAdminUpdateSubmissionStatus(w http.ResponseWriter, r *http.Request) {
 	submissionID, err := parseID(chi.URLParam(r, "submissionID"))
@@ -119,14 +323,230 @@ func (a *App) handleAdminUpdateSubmissionStatus(w http.ResponseWriter, r *http.R
 		return
 	}
 	status := strings.ToUpper(strings.TrimSpace(r.FormValue("status")))
-	if !isValidStatus(status) {
+	if err := validator.ValidateStatus(status); err != nil {
 		http.Error(w, "invalid status", http.StatusBadRequest)
 		return
 	}
-	if err := a.Store.UpdateSubmissionStatus(submissionID, status); err != nil {
+	if err := a.Store.UpdateSubmissionStatus(submissionID, status, currentActor(r)); err != nil {
 		http.Error(w, "failed to update status", http.StatusInternalServerError)
 		return
 	}
+	a.audit(r, store.AuditStatus, store.AuditTargetSubmission, submissionID, "status set to "+status)
+	http.Redirect(w, r, fmt.Sprintf("/admin/submissions/%d", submissionID), http.StatusFound)
+}
+
+// handleAdminAssignSubmission assigns a submission to an admin user, or clears the
+// assignment when the assignee is empty.
+// Redirects back to the submission view page after successful update.
+func (a *App) handleAdminAssignSubmission(w http.ResponseWriter, r *http.Request) {
+	submissionID, err := parseID(chi.URLParam(r, "submissionID"))
+	if err != nil {
+		http.Error(w, "invalid submission", http.StatusBadRequest)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+	assignee := strings.TrimSpace(r.FormValue("assignee"))
+	if assignee != "" {
+		if _, err := a.Store.GetAdminUserByUsername(assignee); err != nil {
+			http.Error(w, "unknown assignee", http.StatusBadRequest)
+			return
+		}
+	}
+	if err := a.Store.AssignSubmission(submissionID, assignee, currentActor(r)); err != nil {
+		if apperrors.IsNotFound(err) {
+			http.Error(w, "submission not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "failed to assign submission", http.StatusInternalServerError)
+		return
+	}
+	if assignee == "" {
+		a.audit(r, store.AuditAssign, store.AuditTargetSubmission, submissionID, "unassigned")
+	} else {
+		a.audit(r, store.AuditAssign, store.AuditTargetSubmission, submissionID, "assigned to "+assignee)
+	}
+	http.Redirect(w, r, fmt.Sprintf("/admin/submissions/%d", submissionID), http.StatusFound)
+}
+
+// handleAdminAddSubmissionNote adds an internal note to a submission.
+// The note is attributed to the signed-in user, or "admin" when authentication is
+// handled by an external proxy. Redirects back to the notes on the submission view page.
+func (a *App) handleAdminAddSubmissionNote(w http.ResponseWriter, r *http.Request) {
+	submissionID, err := parseID(chi.URLParam(r, "submissionID"))
+	if err != nil {
+		http.Error(w, "invalid submission", http.StatusBadRequest)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+	if _, err := a.Store.AddSubmissionNote(submissionID, currentActor(r), r.FormValue("body")); err != nil {
+		switch {
+		case apperrors.IsNotFound(err):
+			http.Error(w, "submission not found", http.StatusNotFound)
+		case apperrors.IsInvalidInput(err):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			http.Error(w, "failed to add note", http.StatusInternalServerError)
+		}
+		return
+	}
+	http.Redirect(w, r, fmt.Sprintf("/admin/submissions/%d#notes", submissionID), http.StatusFound)
+}
+
+// Actions accepted by handleAdminBulkSubmissions.
+const (
+	bulkActionSetStatus = "set-status"
+	bulkActionDelete    = "delete"
+)
+
+// handleAdminBulkSubmissions applies an action to the submissions selected in the list:
+// set-status changes their status, delete removes them permanently.
+// Redirects back to the submissions list with the filters that were active.
+func (a *App) handleAdminBulkSubmissions(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+	ids := make([]int64, 0, len(r.Form["ids"]))
+	for _, value := range r.Form["ids"] {
+		id, err := parseID(value)
+		if err != nil {
+			http.Error(w, "invalid submission", http.StatusBadRequest)
+			return
+		}
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		http.Error(w, "no submissions selected", http.StatusBadRequest)
+		return
+	}
+
+	var err error
+	switch r.FormValue("action") {
+	case bulkActionSetStatus:
+		status := strings.ToUpper(strings.TrimSpace(r.FormValue("status")))
+		if err := validator.ValidateStatus(status); err != nil {
+			http.Error(w, "invalid status", http.StatusBadRequest)
+			return
+		}
+		var updated int64
+		if updated, err = a.Store.BulkUpdateStatus(ids, status, currentActor(r)); err == nil {
+			a.audit(r, store.AuditStatus, store.AuditTargetSubmission, 0,
+				fmt.Sprintf("status set to %s on %d selected: %s", status, updated, formatIDs(ids)))
+		}
+	case bulkActionDelete:
+		var deleted int64
+		if deleted, err = a.Store.BulkDelete(ids); err == nil {
+			a.audit(r, store.AuditDelete, store.AuditTargetSubmission, 0,
+				fmt.Sprintf("deleted %d selected: %s", deleted, formatIDs(ids)))
+		}
+	default:
+		http.Error(w, "invalid action", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		if apperrors.IsInvalidInput(err) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "failed to update submissions", http.StatusInternalServerError)
+		return
+	}
+
+	// Re-encode the posted filters rather than echoing them, so only a query string can come back
+	target := "/admin/submissions"
+	if query, err := url.ParseQuery(r.FormValue("return")); err == nil {
+		query.Del("closed")
+		query.Del("erased")
+		if len(query) > 0 {
+			target += "?" + query.Encode()
+		}
+	}
+	http.Redirect(w, r, target, http.StatusFound)
+}
+
+// formatIDs renders submission IDs as a comma-separated "#1, #2" list.
+func formatIDs(ids []int64) string {
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = "#" + strconv.FormatInt(id, 10)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// maxBulkCloseDays bounds the age cutoff accepted by handleAdminBulkCloseSubmissions.
+const maxBulkCloseDays = 3650
+
+// handleAdminBulkCloseSubmissions closes open and in-progress submissions received more than
+// the posted number of days ago, recording the posted reason as a note on each.
+// Redirects back to the submissions list, which reports how many were closed.
+func (a *App) handleAdminBulkCloseSubmissions(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+	days, err := strconv.Atoi(strings.TrimSpace(r.FormValue("days")))
+	if err != nil || days < 1 || days > maxBulkCloseDays {
+		http.Error(w, fmt.Sprintf("days must be between 1 and %d", maxBulkCloseDays), http.StatusBadRequest)
+		return
+	}
+	before := time.Now().AddDate(0, 0, -days)
+	closed, err := a.Store.BulkCloseSubmissionsOlderThan(before, r.FormValue("reason"))
+	if err != nil {
+		if apperrors.IsInvalidInput(err) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "failed to close submissions", http.StatusInternalServerError)
+		return
+	}
+	a.audit(r, store.AuditBulkClose, store.AuditTargetSubmission, 0,
+		fmt.Sprintf("closed %d received more than %d days ago: %s", closed, days, strings.TrimSpace(r.FormValue("reason"))))
+	http.Redirect(w, r, fmt.Sprintf("/admin/submissions?closed=%d", closed), http.StatusFound)
+}
+
+// handleAdminArchiveSubmission archives a submission, hiding it from the default list.
+// Redirects back to the submission view page, where it can be restored.
+func (a *App) handleAdminArchiveSubmission(w http.ResponseWriter, r *http.Request) {
+	submissionID, err := parseID(chi.URLParam(r, "submissionID"))
+	if err != nil {
+		http.Error(w, "invalid submission", http.StatusBadRequest)
+		return
+	}
+	if err := a.Store.ArchiveSubmission(submissionID); err != nil {
+		if apperrors.IsNotFound(err) {
+			http.Error(w, "submission not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "failed to archive submission", http.StatusInternalServerError)
+		return
+	}
+	a.audit(r, store.AuditArchive, store.AuditTargetSubmission, submissionID, "")
+	http.Redirect(w, r, fmt.Sprintf("/admin/submissions/%d", submissionID), http.StatusFound)
+}
+
+// handleAdminRestoreSubmission returns an archived submission to the default list.
+// Redirects back to the submission view page after successful update.
+func (a *App) handleAdminRestoreSubmission(w http.ResponseWriter, r *http.Request) {
+	submissionID, err := parseID(chi.URLParam(r, "submissionID"))
+	if err != nil {
+		http.Error(w, "invalid submission", http.StatusBadRequest)
+		return
+	}
+	if err := a.Store.RestoreSubmission(submissionID); err != nil {
+		if apperrors.IsNotFound(err) {
+			http.Error(w, "submission not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "failed to restore submission", http.StatusInternalServerError)
+		return
+	}
+	a.audit(r, store.AuditRestore, store.AuditTargetSubmission, submissionID, "")
 	http.Redirect(w, r, fmt.Sprintf("/admin/submissions/%d", submissionID), http.StatusFound)
 }
 
@@ -142,46 +562,161 @@ func (a *App) handleAdminDeleteSubmission(w http.ResponseWriter, r *http.Request
 		http.Error(w, "failed to delete submission", http.StatusInternalServerError)
 		return
 	}
+	// No detail: the entry must not keep personal data that was deleted on request
+	a.audit(r, store.AuditDelete, store.AuditTargetSubmission, submissionID, "")
 	http.Redirect(w, r, "/admin/submissions", http.StatusFound)
 }
 
-// isValidStatus checks if a status string is one of the valid submission statuses.
-// Note: The validator package uses IN_PROGRESS (with underscore), not "IN PROGRESS".
-func isValidStatus(status string) bool {
-	switch status {
-	case "OPEN", "IN_PROGRESS", "CLOSED":
-		return true
-	default:
-		return false
+// streamKeepAlive is how often handleAdminSubmissionStream writes a comment to idle
+// streams, so proxies don't close them and disconnected clients are noticed.
+const streamKeepAlive = 30 * time.Second
+
+// handleAdminSubmissionStream pushes newly received submissions to the client as
+// Server-Sent Events, for live wallboards. Each one is a "submission" event whose data is
+// the submission in its JSON API form. Submissions flagged as spam are not sent.
+// The stream runs until the client disconnects or CloseStreams is called.
+// Returns 503 when too many streams are open.
+func (a *App) handleAdminSubmissionStream(w http.ResponseWriter, r *http.Request) {
+	events, ok := a.feed.subscribe()
+	if !ok {
+		http.Error(w, "too many open streams", http.StatusServiceUnavailable)
+		return
+	}
+	defer a.feed.unsubscribe(events)
+
+	// The server's write timeout is meant for ordinary responses, not a stream that stays open
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		slog.Warn("Failed to lift the write deadline for a submission stream", "error", err)
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no") // Keep nginx from buffering the stream
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write([]byte(": connected\n\n")); err != nil {
+		return
 	}
+	if err := rc.Flush(); err != nil {
+		slog.Warn("Submission stream can't be flushed", "error", err)
+		return
+	}
+
+	keepAlive := time.NewTicker(streamKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		var chunk []byte
+		select {
+		case <-r.Context().Done():
+			return
+		case <-a.feed.done:
+			return
+		case <-keepAlive.C:
+			chunk = []byte(": keep-alive\n\n")
+		case sub := <-events:
+			data, err := json.Marshal(newAPISubmission(sub))
+			if err != nil {
+				slog.Error("Failed to encode streamed submission", "submission_id", sub.ID, "error", err)
+				continue
+			}
+			chunk = []byte(fmt.Sprintf("id: %d\nevent: submission\ndata: %s\n\n", sub.ID, data))
+		}
+		if _, err := w.Write(chunk); err != nil {
+			return
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// handleAdminEraseByEmail permanently deletes every submission sent from the posted email
+// address, for right-to-be-forgotten requests. Like every admin POST it only accepts
+// same-origin requests, see requireSameOrigin.
+// Redirects back to the submissions list, which reports how many were removed.
+func (a *App) handleAdminEraseByEmail(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+	erased, err := a.Store.DeleteSubmissionsByEmail(r.FormValue("email"))
+	if err != nil {
+		if apperrors.IsInvalidInput(err) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "failed to erase submissions", http.StatusInternalServerError)
+		return
+	}
+	// Record that an erasure happened, but not whose: the address is the data being erased
+	a.audit(r, store.AuditErase, store.AuditTargetSubmission, 0, fmt.Sprintf("erased %d for an erasure request", erased))
+	http.Redirect(w, r, fmt.Sprintf("/admin/submissions?erased=%d", erased), http.StatusFound)
 }
 
+// unassignedFilter is the assignee filter value that selects submissions nobody has claimed.
+// A lone dash is not a realistic username, so it won't shadow a real user.
+const unassignedFilter = "-"
+
+// invalidEmailFilter is the email filter value that selects submissions flagged with an invalid address.
+const invalidEmailFilter = "invalid"
+
+// Archive filter values: archivedIncludeFilter lists archived submissions alongside the
+// rest, archivedOnlyFilter lists only archived ones.
+const (
+	archivedIncludeFilter = "include"
+	archivedOnlyFilter    = "only"
+)
+
 // submissionView is a view model for rendering submission list items.
 // It includes formatted timestamps and form type for display.
 type submissionView struct {
 	store.Submission
 	CreatedAt string
 	FormType  string
+	IP        string // Masked to the subnet when TICKETD_MASK_IPS is set
 }
 
 // submissionsPage is the data structure for the submissions list page.
 // It includes pagination information, filter options, and the list of submissions.
 type submissionsPage struct {
-	Active        string
-	Submissions   []submissionView
-	Page          int
-	Total         int
-	TotalPages    int
-	PrevPage      int
-	NextPage      int
-	Clients       []store.Client
-	Forms         []store.Form
-	FilterStatus  string
-	FilterClient  int64
-	FilterForm    int64
-	FilterSearch  string
-	HasFilters    bool
-	ResultsCount  int
+	Active         string
+	Submissions    []submissionView
+	Page           int
+	Total          int
+	TotalPages     int
+	PrevPage       int
+	NextPage       int
+	Clients        []store.Client
+	Forms          []store.Form
+	FilterStatus   string
+	FilterClient   int64
+	FilterForm     int64
+	FilterSearch   string
+	FilterAssignee string
+	FilterCategory string
+	FilterInvalid  bool
+	FilterArchived string // "", archivedIncludeFilter, or archivedOnlyFilter
+	BulkClosed     string // Number of tickets just closed in bulk, empty unless returning from a bulk close
+	Erased         string // Number of tickets just erased by email, empty unless returning from an erasure
+	ReturnQuery    string // Current query string, so bulk actions can return to the same view
+	PerPage        int    // Page size from per_page, 0 when using the configured default
+	Users          []store.AdminUser
+	Categories     []string // Categories of all forms, for the category filter
+	HasFilters     bool
+	ResultsCount   int
+	SortField      string
+	SortDir        string
+	SortHeaders    map[string]sortHeader
+	PrevURL        string
+	NextURL        string
+}
+
+// sortHeader describes a clickable, sortable column header in the submissions table.
+type sortHeader struct {
+	Label  string
+	URL    string
+	Active bool
+	Dir    string
 }
 
 // submissionPage is the data structure for the single submission detail page.
@@ -189,4 +724,21 @@ type submissionPage struct {
 	Active     string
 	Submission store.Submission
 	CreatedAt  string
+	ArchivedAt string // Empty unless the submission is archived
+	IP         string // Masked to the subnet when TICKETD_MASK_IPS is set
+	Users      []store.AdminUser
+	Notes      []noteView
+	History    []statusChangeView
+}
+
+// noteView is a view model for rendering an internal note with a formatted timestamp.
+type noteView struct {
+	store.SubmissionNote
+	CreatedAt string
+}
+
+// statusChangeView is a view model for rendering a status transition with a formatted timestamp.
+type statusChangeView struct {
+	store.StatusChange
+	CreatedAt string
 }
@@ -0,0 +1,427 @@
+package web
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"ticketd/internal/config"
+	"ticketd/internal/store"
+	"ticketd/internal/validator"
+)
+
+func TestAdminStatusUpdateRecordsActor(t *testing.T) {
+	tests := []struct {
+		name        string
+		disableAuth bool
+		user        string
+		want        string
+	}{
+		{name: "signed-in user", user: "alice", want: "alice"},
+		{name: "external auth", disableAuth: true, want: "admin"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApp(t, func(cfg *config.Config) { cfg.DisableAuth = tt.disableAuth })
+			form := createTestForm(t, app, "example.com", store.FormTypeSupport)
+			submission := createTestSubmission(t, app, form)
+
+			req := newFormPost(fmt.Sprintf("/admin/submissions/%d/status", submission.ID), url.Values{"status": {validator.StatusInProgress}})
+			if tt.user != "" {
+				signIn(t, app, req, tt.user)
+			}
+			if rec := serve(t, app, req); rec.Code != http.StatusFound {
+				t.Fatalf("status = %d, want %d (body %q)", rec.Code, http.StatusFound, rec.Body.String())
+			}
+
+			got, err := app.Store.GetSubmission(submission.ID)
+			if err != nil {
+				t.Fatalf("GetSubmission: %v", err)
+			}
+			if got.Status != validator.StatusInProgress || got.UpdatedBy != tt.want {
+				t.Errorf("status %q updated by %q, want %q updated by %q", got.Status, got.UpdatedBy, validator.StatusInProgress, tt.want)
+			}
+			modified, _, err := app.Store.ListSubmissionsModifiedBy(tt.want, 0, 10)
+			if err != nil {
+				t.Fatalf("ListSubmissionsModifiedBy: %v", err)
+			}
+			if len(modified) != 1 || modified[0].ID != submission.ID {
+				t.Errorf("ListSubmissionsModifiedBy(%q) = %v, want submission %d", tt.want, modified, submission.ID)
+			}
+		})
+	}
+}
+
+// statusSelect and optionValue extract the status select of the submission page and its option values.
+var (
+	statusSelect = regexp.MustCompile(`(?s)<select name="status" id="status-select"[^>]*>(.*?)</select>`)
+	optionValue  = regexp.MustCompile(`<option value="([^"]*)"`)
+)
+
+func TestAdminStatusFormValues(t *testing.T) {
+	app := newTestApp(t, nil)
+	form := createTestForm(t, app, "example.com", store.FormTypeSupport)
+	submission := createTestSubmission(t, app, form)
+
+	page := serve(t, app, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/admin/submissions/%d", submission.ID), nil))
+	if page.Code != http.StatusOK {
+		t.Fatalf("GET submission page: status = %d, want %d", page.Code, http.StatusOK)
+	}
+	selectHTML := statusSelect.FindStringSubmatch(page.Body.String())
+	if selectHTML == nil {
+		t.Fatal("submission page has no status select")
+	}
+	var values []string
+	for _, match := range optionValue.FindAllStringSubmatch(selectHTML[1], -1) {
+		values = append(values, match[1])
+	}
+	want := []string{validator.StatusOpen, validator.StatusInProgress, validator.StatusClosed, validator.StatusSpam}
+	if fmt.Sprint(values) != fmt.Sprint(want) {
+		t.Errorf("status options = %v, want %v", values, want)
+	}
+
+	// Each option must be stored exactly as posted
+	for _, value := range values {
+		if err := validator.ValidateStatus(value); err != nil {
+			t.Errorf("option %q: ValidateStatus: %v", value, err)
+		}
+		req := newFormPost(fmt.Sprintf("/admin/submissions/%d/status", submission.ID), url.Values{"status": {value}})
+		if rec := serve(t, app, req); rec.Code != http.StatusFound {
+			t.Errorf("posting option %q: status = %d, want %d (body %q)", value, rec.Code, http.StatusFound, rec.Body.String())
+			continue
+		}
+		got, err := app.Store.GetSubmission(submission.ID)
+		if err != nil {
+			t.Fatalf("GetSubmission: %v", err)
+		}
+		if got.Status != value {
+			t.Errorf("posting option %q stored status %q", value, got.Status)
+		}
+	}
+
+	req := newFormPost(fmt.Sprintf("/admin/submissions/%d/status", submission.ID), url.Values{"status": {"IN PROGRESS"}})
+	if rec := serve(t, app, req); rec.Code != http.StatusBadRequest {
+		t.Errorf("posting the legacy %q: status = %d, want %d", "IN PROGRESS", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestAdminMaskedIPs(t *testing.T) {
+	tests := []struct {
+		name     string
+		mask     bool
+		wantShow string
+		wantHide string
+	}{
+		{name: "full", wantShow: "203.0.113.42"},
+		{name: "masked", mask: true, wantShow: "203.0.113.0/24", wantHide: "203.0.113.42"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApp(t, func(cfg *config.Config) { cfg.MaskIPs = tt.mask })
+			form := createTestForm(t, app, "example.com", store.FormTypeSupport)
+			submission := createTestSubmission(t, app, form)
+
+			for _, target := range []string{"/admin/submissions", fmt.Sprintf("/admin/submissions/%d", submission.ID)} {
+				rec := serve(t, app, httptest.NewRequest(http.MethodGet, target, nil))
+				if rec.Code != http.StatusOK {
+					t.Fatalf("GET %s: status = %d, want %d", target, rec.Code, http.StatusOK)
+				}
+				body := rec.Body.String()
+				if !strings.Contains(body, tt.wantShow) {
+					t.Errorf("GET %s does not show %q", target, tt.wantShow)
+				}
+				if tt.wantHide != "" && strings.Contains(body, tt.wantHide) {
+					t.Errorf("GET %s shows the full address %q", target, tt.wantHide)
+				}
+			}
+
+			// The API, which exports and the live stream share, always has the full address
+			var list apiSubmissionList
+			getJSON(t, app, "/api/v1/submissions", http.StatusOK, &list)
+			if len(list.Submissions) != 1 || list.Submissions[0].IP != "203.0.113.42" {
+				t.Errorf("API submissions = %+v, want one with IP 203.0.113.42", list.Submissions)
+			}
+		})
+	}
+}
+
+func TestAdminEraseByEmail(t *testing.T) {
+	app := newTestApp(t, nil)
+	form := createTestForm(t, app, "example.com", store.FormTypeSupport)
+	createTestSubmission(t, app, form)
+	createTestSubmission(t, app, form)
+	other, err := app.Store.CreateSubmission(form.ID, store.SubmissionInput{Name: "John Doe", Email: "john@example.com", Subject: "Help", Message: "Hi"})
+	if err != nil {
+		t.Fatalf("CreateSubmission: %v", err)
+	}
+
+	tests := []struct {
+		email    string
+		status   int
+		location string
+	}{
+		{email: "nobody@example.com", status: http.StatusFound, location: "/admin/submissions?erased=0"},
+		{email: " JANE@example.com ", status: http.StatusFound, location: "/admin/submissions?erased=2"},
+		{email: "", status: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		rec := serve(t, app, newFormPost("/admin/gdpr/erase", url.Values{"email": {tt.email}}))
+		if rec.Code != tt.status {
+			t.Fatalf("erase %q: status = %d, want %d (body %q)", tt.email, rec.Code, tt.status, rec.Body.String())
+		}
+		if location := rec.Header().Get("Location"); location != tt.location {
+			t.Errorf("erase %q: Location = %q, want %q", tt.email, location, tt.location)
+		}
+	}
+
+	submissions, total, err := app.Store.ListSubmissions(0, 10)
+	if err != nil {
+		t.Fatalf("ListSubmissions: %v", err)
+	}
+	if total != 1 || submissions[0].ID != other.ID {
+		t.Errorf("remaining submissions = %v, want only submission %d", submissions, other.ID)
+	}
+
+	entries, _, err := app.Store.ListAuditEntries(0, 10)
+	if err != nil {
+		t.Fatalf("ListAuditEntries: %v", err)
+	}
+	erasures := 0
+	for _, entry := range entries {
+		if entry.Action != store.AuditErase {
+			continue
+		}
+		erasures++
+		if strings.Contains(strings.ToLower(entry.Detail), "jane") {
+			t.Errorf("audit detail %q records the erased address", entry.Detail)
+		}
+	}
+	if erasures != 2 {
+		t.Errorf("%d erasure audit entries, want 2", erasures)
+	}
+
+	// Without a session nothing is erased
+	app = newTestApp(t, func(cfg *config.Config) { cfg.DisableAuth = false })
+	form = createTestForm(t, app, "example.com", store.FormTypeSupport)
+	createTestSubmission(t, app, form)
+	serve(t, app, newFormPost("/admin/gdpr/erase", url.Values{"email": {"jane@example.com"}}))
+	if _, total, _ := app.Store.ListSubmissions(0, 10); total != 1 {
+		t.Errorf("signed-out erase left %d submissions, want 1", total)
+	}
+}
+
+func TestAdminEraseByEmailCrossOrigin(t *testing.T) {
+	app := newTestApp(t, func(cfg *config.Config) { cfg.DisableAuth = false })
+	form := createTestForm(t, app, "example.com", store.FormTypeSupport)
+	createTestSubmission(t, app, form)
+
+	// A signed-in admin's browser sends the session cookie along with forged requests
+	forged := []struct {
+		name   string
+		header string
+		value  string
+	}{
+		{name: "cross-site", header: "Sec-Fetch-Site", value: "cross-site"},
+		{name: "sibling subdomain", header: "Sec-Fetch-Site", value: "same-site"},
+		{name: "foreign origin", header: "Origin", value: "https://evil.example"},
+	}
+	for _, tt := range forged {
+		req := newFormPost("/admin/gdpr/erase", url.Values{"email": {"jane@example.com"}})
+		req.Header.Set(tt.header, tt.value)
+		signIn(t, app, req, "alice")
+		rec := serve(t, app, req)
+		if rec.Code != http.StatusForbidden {
+			t.Errorf("%s: status = %d, want %d", tt.name, rec.Code, http.StatusForbidden)
+		}
+		if _, total, _ := app.Store.ListSubmissions(0, 10); total != 1 {
+			t.Fatalf("%s: forged erase left %d submissions, want 1", tt.name, total)
+		}
+	}
+
+	req := newFormPost("/admin/gdpr/erase", url.Values{"email": {"jane@example.com"}})
+	req.Header.Set("Sec-Fetch-Site", "same-origin")
+	signIn(t, app, req, "alice")
+	if rec := serve(t, app, req); rec.Code != http.StatusFound {
+		t.Fatalf("same-origin erase: status = %d, want %d (body %q)", rec.Code, http.StatusFound, rec.Body.String())
+	}
+	if _, total, _ := app.Store.ListSubmissions(0, 10); total != 0 {
+		t.Errorf("same-origin erase left %d submissions, want 0", total)
+	}
+}
+
+func TestAdminSubmissionStream(t *testing.T) {
+	app := newTestApp(t, nil)
+	form := createTestForm(t, app, "example.com", store.FormTypeSupport)
+	server := httptest.NewServer(app.Router())
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/admin/submissions/stream", nil)
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+	resp, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatalf("GET stream: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("status = %d, Content-Type = %q, want %d, text/event-stream", resp.StatusCode, resp.Header.Get("Content-Type"), http.StatusOK)
+	}
+	stream := bufio.NewReader(resp.Body)
+	// readEvent returns the lines of the next event, without the blank line ending it
+	readEvent := func() []string {
+		t.Helper()
+		var lines []string
+		for {
+			line, err := stream.ReadString('\n')
+			if err != nil {
+				t.Fatalf("read stream: %v", err)
+			}
+			if line = strings.TrimSuffix(line, "\n"); line == "" {
+				return lines
+			}
+			lines = append(lines, line)
+		}
+	}
+	if got := readEvent(); fmt.Sprint(got) != "[: connected]" {
+		t.Fatalf("first event = %q, want the connected comment", got)
+	}
+
+	rec := serve(t, app, newSubmitRequest(form.ID, "https://example.com", "application/json", strings.NewReader(jsonSubmission)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("submit: status = %d, want %d (body %q)", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	submission := lastSubmission(t, app)
+
+	event := readEvent()
+	if len(event) != 3 || event[0] != fmt.Sprintf("id: %d", submission.ID) || event[1] != "event: submission" || !strings.HasPrefix(event[2], "data: ") {
+		t.Fatalf("event = %q, want submission %d", event, submission.ID)
+	}
+	var got apiSubmission
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(event[2], "data: ")), &got); err != nil {
+		t.Fatalf("decode event data: %v", err)
+	}
+	if got.ID != submission.ID || got.FormID != form.ID || got.Subject != submission.Subject {
+		t.Errorf("streamed submission = %+v, want submission %d to form %d", got, submission.ID, form.ID)
+	}
+
+	// Disconnecting unsubscribes the stream
+	cancel()
+	deadline := time.Now().Add(5 * time.Second)
+	for subscribers(app) > 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d subscribers left after disconnect", subscribers(app))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestAdminSubmissionStreamLimit(t *testing.T) {
+	app := newTestApp(t, nil)
+	for i := 0; i < maxFeedSubscribers; i++ {
+		if _, ok := app.feed.subscribe(); !ok {
+			t.Fatalf("subscribe %d refused", i)
+		}
+	}
+	rec := serve(t, app, httptest.NewRequest(http.MethodGet, "/admin/submissions/stream", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+}
+
+// subscribers returns how many streams are subscribed to app's submission feed.
+func subscribers(app *App) int {
+	app.feed.mu.Lock()
+	defer app.feed.mu.Unlock()
+	return len(app.feed.subscribers)
+}
+
+func TestAdminBulkSubmissions(t *testing.T) {
+	app := newTestApp(t, nil)
+	form := createTestForm(t, app, "example.com", store.FormTypeSupport)
+	first := createTestSubmission(t, app, form)
+	second := createTestSubmission(t, app, form)
+	third := createTestSubmission(t, app, form)
+	ids := func(subs ...store.Submission) []string {
+		var values []string
+		for _, sub := range subs {
+			values = append(values, fmt.Sprint(sub.ID))
+		}
+		return values
+	}
+
+	rec := serve(t, app, newFormPost("/admin/submissions/bulk", url.Values{
+		"ids":    ids(first, second),
+		"action": {"set-status"},
+		"status": {"in_progress"},
+		"return": {"status=OPEN&closed=3&page=2"},
+	}))
+	if rec.Code != http.StatusFound {
+		t.Fatalf("set-status: status = %d, want %d (body %q)", rec.Code, http.StatusFound, rec.Body.String())
+	}
+	if location := rec.Header().Get("Location"); location != "/admin/submissions?page=2&status=OPEN" {
+		t.Errorf("set-status: Location = %q, want the list with the filters minus the flash", location)
+	}
+	for _, sub := range []store.Submission{first, second} {
+		if got, _ := app.Store.GetSubmission(sub.ID); got.Status != validator.StatusInProgress {
+			t.Errorf("submission %d: status = %q, want %q", sub.ID, got.Status, validator.StatusInProgress)
+		}
+	}
+	if got, _ := app.Store.GetSubmission(third.ID); got.Status != validator.StatusOpen {
+		t.Errorf("unselected submission: status = %q, want %q", got.Status, validator.StatusOpen)
+	}
+
+	rec = serve(t, app, newFormPost("/admin/submissions/bulk", url.Values{"ids": ids(second, third), "action": {"delete"}}))
+	if rec.Code != http.StatusFound || rec.Header().Get("Location") != "/admin/submissions" {
+		t.Fatalf("delete: status = %d, Location = %q, want a redirect to the list", rec.Code, rec.Header().Get("Location"))
+	}
+	submissions, total, err := app.Store.ListSubmissions(0, 10)
+	if err != nil {
+		t.Fatalf("ListSubmissions: %v", err)
+	}
+	if total != 1 || submissions[0].ID != first.ID {
+		t.Errorf("remaining submissions = %v, want only submission %d", submissions, first.ID)
+	}
+
+	entries, _, err := app.Store.ListAuditEntries(0, 10)
+	if err != nil {
+		t.Fatalf("ListAuditEntries: %v", err)
+	}
+	var actions []string
+	for _, entry := range entries {
+		actions = append(actions, string(entry.Action))
+	}
+	if !slices.Contains(actions, string(store.AuditStatus)) || !slices.Contains(actions, string(store.AuditDelete)) {
+		t.Errorf("audit actions = %v, want a status change and a delete", actions)
+	}
+
+	invalid := []struct {
+		name   string
+		values url.Values
+	}{
+		{name: "nothing selected", values: url.Values{"action": {"delete"}}},
+		{name: "bad ID", values: url.Values{"ids": {"abc"}, "action": {"delete"}}},
+		{name: "unknown action", values: url.Values{"ids": ids(first), "action": {"archive"}}},
+		{name: "invalid status", values: url.Values{"ids": ids(first), "action": {"set-status"}, "status": {"DONE"}}},
+	}
+	for _, tt := range invalid {
+		rec := serve(t, app, newFormPost("/admin/submissions/bulk", tt.values))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want %d", tt.name, rec.Code, http.StatusBadRequest)
+		}
+	}
+	if _, total, _ := app.Store.ListSubmissions(0, 10); total != 1 {
+		t.Errorf("rejected requests left %d submissions, want 1", total)
+	}
+}
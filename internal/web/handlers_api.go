@@ -0,0 +1,184 @@
+package web
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	apperrors "ticketd/internal/errors"
+	"ticketd/internal/store"
+	"ticketd/internal/validator"
+)
+
+// handleAPIClientForms returns a client's forms as JSON for management UIs.
+// Results are paginated with the page and per_page query parameters (TICKETD_PAGE_SIZE items per page by default).
+// Returns 404 if the client doesn't exist.
+func (a *App) handleAPIClientForms(w http.ResponseWriter, r *http.Request) {
+	clientID, err := parseID(chi.URLParam(r, "clientID"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid client"})
+		return
+	}
+	if _, err := a.Store.GetClient(clientID); err != nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "client not found"})
+		return
+	}
+
+	page := parsePage(r)
+	size := a.pageSize(r)
+	forms, total, err := a.Store.ListFormsPaginated(clientID, (page-1)*size, size)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to load forms"})
+		return
+	}
+
+	items := make([]apiForm, 0, len(forms))
+	for _, f := range forms {
+		items = append(items, newAPIForm(f))
+	}
+	writeJSON(w, http.StatusOK, apiFormList{
+		Forms:      items,
+		Page:       page,
+		PageSize:   size,
+		Total:      total,
+		TotalPages: totalPages(total, size),
+	})
+}
+
+// apiForm is the JSON representation of a form.
+type apiForm struct {
+	ID         int64    `json:"id"`
+	ClientID   int64    `json:"client_id"`
+	Name       string   `json:"name"`
+	Type       string   `json:"type"`
+	Categories []string `json:"categories"`
+	Active     bool     `json:"active"`
+	CreatedAt  string   `json:"created_at"`
+}
+
+// newAPIForm converts a store form to its JSON representation.
+// Timestamps are formatted as RFC 3339 in UTC.
+func newAPIForm(f store.Form) apiForm {
+	categories := f.Categories
+	if categories == nil {
+		categories = []string{}
+	}
+	return apiForm{
+		ID:         f.ID,
+		ClientID:   f.ClientID,
+		Name:       f.Name,
+		Type:       string(f.Type),
+		Categories: categories,
+		Active:     f.Active,
+		CreatedAt:  f.CreatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+// apiFormList is the JSON response for a page of forms.
+type apiFormList struct {
+	Forms      []apiForm `json:"forms"`
+	Page       int       `json:"page"`
+	PageSize   int       `json:"page_size"`
+	Total      int       `json:"total"`
+	TotalPages int       `json:"total_pages"`
+}
+
+// handleAPISubmissions returns unarchived submissions as JSON, newest first, for consumers
+// paging through all of them. Pages are chained with the after query parameter: pass the
+// next_cursor of one response to get the following page. next_cursor is omitted on the
+// last page. per_page sets the page size as elsewhere in the API.
+func (a *App) handleAPISubmissions(w http.ResponseWriter, r *http.Request) {
+	size := a.pageSize(r)
+	submissions, next, err := a.Store.ListSubmissionsAfter(r.URL.Query().Get("after"), size)
+	if err != nil {
+		if apperrors.IsInvalidInput(err) {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid cursor"})
+			return
+		}
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to load submissions"})
+		return
+	}
+
+	items := make([]apiSubmission, 0, len(submissions))
+	for _, sub := range submissions {
+		items = append(items, newAPISubmission(sub))
+	}
+	writeJSON(w, http.StatusOK, apiSubmissionList{
+		Submissions: items,
+		PageSize:    size,
+		NextCursor:  next,
+	})
+}
+
+// apiSubmission is the JSON representation of a submission.
+type apiSubmission struct {
+	ID         int64  `json:"id"`
+	Reference  string `json:"reference"`
+	ClientID   int64  `json:"client_id"`
+	Client     string `json:"client"`
+	FormID     int64  `json:"form_id"`
+	Form       string `json:"form"`
+	FormType   string `json:"form_type"`
+	Status     string `json:"status"`
+	Name       string `json:"name"`
+	Email      string `json:"email"`
+	Phone      string `json:"phone"`
+	PhoneE164  string `json:"phone_e164"`
+	Subject    string `json:"subject"`
+	Message    string `json:"message"`
+	Priority   string `json:"priority"`
+	Category   string `json:"category"`
+	Assignee   string `json:"assignee"`
+	Source     string `json:"source"`
+	Consent    string `json:"consent,omitempty"`
+	IP         string `json:"ip"`
+	CreatedAt  string `json:"created_at"`
+	ArchivedAt string `json:"archived_at,omitempty"`
+}
+
+// newAPISubmission converts a store submission to its JSON representation.
+// Empty statuses are reported as OPEN. The IP is the stored one, never masked like in
+// the admin UI, so exports and API consumers keep it for abuse investigations.
+// Timestamps are formatted as RFC 3339 in UTC.
+func newAPISubmission(sub store.Submission) apiSubmission {
+	status := sub.Status
+	if status == "" {
+		status = validator.StatusOpen
+	}
+	archived := ""
+	if !sub.ArchivedAt.IsZero() {
+		archived = sub.ArchivedAt.UTC().Format(time.RFC3339)
+	}
+	return apiSubmission{
+		ID:         sub.ID,
+		Reference:  sub.Reference,
+		ClientID:   sub.ClientID,
+		Client:     sub.Client,
+		FormID:     sub.FormID,
+		Form:       sub.Form,
+		FormType:   string(sub.FormType),
+		Status:     status,
+		Name:       sub.Name,
+		Email:      sub.Email,
+		Phone:      sub.Phone,
+		PhoneE164:  sub.PhoneE164,
+		Subject:    sub.Subject,
+		Message:    sub.Message,
+		Priority:   sub.Priority,
+		Category:   sub.Category,
+		Assignee:   sub.Assignee,
+		Source:     sub.Source,
+		Consent:    sub.Consent,
+		IP:         sub.IP,
+		CreatedAt:  sub.CreatedAt.UTC().Format(time.RFC3339),
+		ArchivedAt: archived,
+	}
+}
+
+// apiSubmissionList is the JSON response for a page of submissions.
+type apiSubmissionList struct {
+	Submissions []apiSubmission `json:"submissions"`
+	PageSize    int             `json:"page_size"`
+	NextCursor  string          `json:"next_cursor,omitempty"`
+}
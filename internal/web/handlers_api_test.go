@@ -0,0 +1,126 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+
+	"ticketd/internal/config"
+	"ticketd/internal/store"
+)
+
+// getJSON serves a GET request for target and decodes the JSON response into v,
+// failing the test unless the status is want.
+func getJSON(t *testing.T, app *App, target string, want int, v any) {
+	t.Helper()
+	rec := serve(t, app, httptest.NewRequest(http.MethodGet, target, nil))
+	if rec.Code != want {
+		t.Fatalf("GET %s: status = %d, want %d (body %q)", target, rec.Code, want, rec.Body.String())
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+		t.Fatalf("GET %s: decode %q: %v", target, rec.Body.String(), err)
+	}
+}
+
+func TestAPIClientFormsShape(t *testing.T) {
+	app := newTestApp(t, nil)
+	form := createTestForm(t, app, "example.com", store.FormTypeSupport)
+
+	var body map[string]any
+	getJSON(t, app, fmt.Sprintf("/api/v1/clients/%d/forms", form.ClientID), http.StatusOK, &body)
+	for key, want := range map[string]any{"page": 1.0, "page_size": 20.0, "total": 1.0, "total_pages": 1.0} {
+		if body[key] != want {
+			t.Errorf("%s = %v, want %v", key, body[key], want)
+		}
+	}
+	forms, ok := body["forms"].([]any)
+	if !ok || len(forms) != 1 {
+		t.Fatalf("forms = %v, want one form", body["forms"])
+	}
+	item := forms[0].(map[string]any)
+	var keys []string
+	for key := range item {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	if got, want := fmt.Sprint(keys), "[active categories client_id created_at id name type]"; got != want {
+		t.Errorf("form keys = %s, want %s", got, want)
+	}
+	if item["id"] != float64(form.ID) || item["client_id"] != float64(form.ClientID) || item["type"] != "support" || item["active"] != true {
+		t.Errorf("form = %v, want id %d, client_id %d, type support, active", item, form.ID, form.ClientID)
+	}
+	if categories, ok := item["categories"].([]any); !ok || len(categories) != 0 {
+		t.Errorf("categories = %v, want an empty list", item["categories"])
+	}
+}
+
+func TestAPIClientFormsPagination(t *testing.T) {
+	app := newTestApp(t, nil)
+	client, err := app.Store.CreateClient("Client", "example.com")
+	if err != nil {
+		t.Fatalf("CreateClient: %v", err)
+	}
+	for i := range 3 {
+		if _, err := app.Store.CreateForm(client.ID, store.FormInput{Name: fmt.Sprintf("Form %d", i), Type: store.FormTypeContact}); err != nil {
+			t.Fatalf("CreateForm: %v", err)
+		}
+	}
+
+	tests := []struct {
+		query          string
+		wantForms      int
+		wantPage       int
+		wantPageSize   int
+		wantTotalPages int
+	}{
+		{query: "?per_page=2", wantForms: 2, wantPage: 1, wantPageSize: 2, wantTotalPages: 2},
+		{query: "?per_page=2&page=2", wantForms: 1, wantPage: 2, wantPageSize: 2, wantTotalPages: 2},
+		{query: "?per_page=2&page=3", wantForms: 0, wantPage: 3, wantPageSize: 2, wantTotalPages: 2},
+		{query: "?page=0", wantForms: 3, wantPage: 1, wantPageSize: 20, wantTotalPages: 1},
+		{query: "?per_page=1000", wantForms: 3, wantPage: 1, wantPageSize: config.MaxPageSize, wantTotalPages: 1},
+	}
+	seen := map[int64]bool{}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			var list apiFormList
+			getJSON(t, app, fmt.Sprintf("/api/v1/clients/%d/forms%s", client.ID, tt.query), http.StatusOK, &list)
+			if len(list.Forms) != tt.wantForms || list.Page != tt.wantPage || list.PageSize != tt.wantPageSize ||
+				list.Total != 3 || list.TotalPages != tt.wantTotalPages {
+				t.Errorf("got %d forms, page %d, page_size %d, total %d, total_pages %d; want %d, %d, %d, 3, %d",
+					len(list.Forms), list.Page, list.PageSize, list.Total, list.TotalPages,
+					tt.wantForms, tt.wantPage, tt.wantPageSize, tt.wantTotalPages)
+			}
+			if tt.wantPageSize == 2 {
+				for _, form := range list.Forms {
+					if seen[form.ID] {
+						t.Errorf("form %d returned on more than one page", form.ID)
+					}
+					seen[form.ID] = true
+				}
+			}
+		})
+	}
+	if len(seen) != 3 {
+		t.Errorf("pages of 2 returned %d distinct forms, want 3", len(seen))
+	}
+}
+
+func TestAPIClientFormsErrors(t *testing.T) {
+	app := newTestApp(t, nil)
+
+	var body map[string]string
+	getJSON(t, app, "/api/v1/clients/999/forms", http.StatusNotFound, &body)
+	if body["error"] != "client not found" {
+		t.Errorf("unknown client: error = %q, want %q", body["error"], "client not found")
+	}
+	getJSON(t, app, "/api/v1/clients/abc/forms", http.StatusBadRequest, &body)
+	if body["error"] != "invalid client" {
+		t.Errorf("invalid client ID: error = %q, want %q", body["error"], "invalid client")
+	}
+
+	authApp := newTestApp(t, func(cfg *config.Config) { cfg.DisableAuth = false })
+	getJSON(t, authApp, "/api/v1/clients/1/forms", http.StatusUnauthorized, &body)
+}
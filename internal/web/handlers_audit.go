@@ -0,0 +1,71 @@
+package web
+
+import (
+	"log/slog"
+	"net/http"
+
+	"ticketd/internal/store"
+)
+
+// audit records an admin action in the audit log.
+// Failures are logged and otherwise ignored so that auditing never blocks the action itself.
+func (a *App) audit(r *http.Request, action, targetType string, targetID int64, detail string) {
+	entry := store.AuditEntry{
+		Actor:      currentActor(r),
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Detail:     detail,
+	}
+	if err := a.Store.AddAuditEntry(entry); err != nil {
+		slog.Error("Failed to write audit entry", "action", action, "target_type", targetType, "target_id", targetID, "error", err)
+	}
+}
+
+// handleAdminAudit displays a paginated, read-only list of recent admin actions, newest first.
+func (a *App) handleAdminAudit(w http.ResponseWriter, r *http.Request) {
+	page := parsePage(r)
+	size := a.pageSize(r)
+	offset := (page - 1) * size
+
+	entries, total, err := a.Store.ListAuditEntries(offset, size)
+	if err != nil {
+		http.Error(w, "failed to load audit log", http.StatusInternalServerError)
+		return
+	}
+
+	views := make([]auditEntryView, 0, len(entries))
+	for _, entry := range entries {
+		views = append(views, auditEntryView{AuditEntry: entry, CreatedAt: formatTime(entry.CreatedAt)})
+	}
+
+	data := auditPage{
+		Active:     "audit",
+		Entries:    views,
+		Page:       page,
+		Total:      total,
+		TotalPages: totalPages(total, size),
+		PrevPage:   prevPage(page),
+		NextPage:   nextPage(page, total, size),
+		PerPage:    a.perPageParam(size),
+	}
+	a.renderTemplate(w, r, "audit.html", data)
+}
+
+// auditEntryView is a view model for rendering an audit entry with a formatted timestamp.
+type auditEntryView struct {
+	store.AuditEntry
+	CreatedAt string
+}
+
+// auditPage is the data structure for the audit log page.
+type auditPage struct {
+	Active     string
+	Entries    []auditEntryView
+	Page       int
+	Total      int
+	TotalPages int
+	PrevPage   int
+	NextPage   int
+	PerPage    int // Page size from per_page, 0 when using the configured default
+}
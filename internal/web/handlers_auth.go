@@ -0,0 +1,85 @@
+package web
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// handleLoginPage displays the admin login form.
+// Users that already have a valid session are sent straight to the admin area.
+func (a *App) handleLoginPage(w http.ResponseWriter, r *http.Request) {
+	next := safeRedirectTarget(r.URL.Query().Get("next"), "/admin")
+	if a.Cfg.DisableAuth {
+		http.Redirect(w, r, next, http.StatusFound)
+		return
+	}
+	if _, ok := a.sessionUser(r); ok {
+		http.Redirect(w, r, next, http.StatusFound)
+		return
+	}
+	a.renderTemplate(w, r, "login.html", loginPage{Next: next})
+}
+
+// handleLogin validates the submitted credentials and starts a session.
+// On success it sets a signed, HttpOnly session cookie and redirects to the
+// requested page. On failure it re-renders the login form with an error.
+func (a *App) handleLogin(w http.ResponseWriter, r *http.Request) {
+	if a.Cfg.DisableAuth {
+		http.Redirect(w, r, "/admin", http.StatusFound)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+	username := strings.TrimSpace(r.FormValue("username"))
+	password := r.FormValue("password")
+	next := safeRedirectTarget(r.FormValue("next"), "/admin")
+
+	if !a.checkCredentials(username, password) {
+		slog.Warn("Failed admin login", "username", username, "remote_addr", r.RemoteAddr)
+		data := loginPage{
+			Next:     next,
+			Username: username,
+			Error:    "Invalid username or password.",
+		}
+		a.renderTemplateStatus(w, r, http.StatusUnauthorized, "login.html", data)
+		return
+	}
+
+	http.SetCookie(w, a.newSessionCookie(r, username))
+	http.Redirect(w, r, next, http.StatusSeeOther)
+}
+
+// handleLogout ends the current session and returns to the login page.
+func (a *App) handleLogout(w http.ResponseWriter, r *http.Request) {
+	http.SetCookie(w, clearSessionCookie())
+	http.Redirect(w, r, "/admin/login", http.StatusSeeOther)
+}
+
+// checkCredentials reports whether the username and password match an admin user.
+// When the user doesn't exist, a dummy bcrypt comparison is still performed so the
+// response time doesn't reveal which usernames are valid.
+func (a *App) checkCredentials(username, password string) bool {
+	user, err := a.Store.GetAdminUserByUsername(username)
+	if err != nil {
+		_ = bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(password))
+		return false
+	}
+	return bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) == nil
+}
+
+// dummyPasswordHash is compared against when a login names an unknown user.
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("ticketd-dummy-password"), bcrypt.DefaultCost)
+
+// loginPage is the data structure for the login page.
+// Active is left empty so the layout hides the admin navigation.
+type loginPage struct {
+	Active   string
+	Next     string
+	Username string
+	Error    string
+}
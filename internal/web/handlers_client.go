@@ -1,11 +1,17 @@
 package web
 
 import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 
+	apperrors "ticketd/internal/errors"
 	"ticketd/internal/store"
 )
 
@@ -13,17 +19,39 @@ import (
 // Each client represents an organization that can create forms.
 func (a *App) handleAdminClients(w http.ResponseWriter, r *http.Request) {
 	page := parsePage(r)
-	offset := (page - 1) * pageSize
+	size := a.pageSize(r)
+	offset := (page - 1) * size
 
-	clients, total, err := a.Store.ListClients(offset, pageSize)
+	clients, total, err := a.Store.ListClientsWithCounts(offset, size)
 	if err != nil {
 		http.Error(w, "failed to load clients", http.StatusInternalServerError)
 		return
 	}
 
+	// Like the sparklines, open ticket ages are extra information the list can do without
+	openAges, err := a.Store.OldestOpenSubmissionAgeByClient()
+	if err != nil {
+		slog.Warn("Failed to load oldest open submission ages", "error", err)
+	}
+
 	views := make([]clientView, 0, len(clients))
 	for _, c := range clients {
-		views = append(views, clientView{Client: c, CreatedAt: formatTime(c.CreatedAt)})
+		view := clientView{Client: c.Client, CreatedAt: formatTime(c.CreatedAt), SubmissionCount: c.SubmissionCount}
+		if age, ok := openAges[c.ID]; ok {
+			view.OldestOpen = formatAge(age)
+			view.OldestOpenOverdue = age >= openAgeWarning
+		}
+		// The sparkline is decoration; a failure shouldn't hide the client list
+		counts, err := a.Store.ClientSubmissionSparkline(c.ID, sparklineDays)
+		if err != nil {
+			slog.Warn("Failed to load client sparkline", "client_id", c.ID, "error", err)
+		} else {
+			view.Sparkline = sparklinePoints(counts)
+			for _, count := range counts {
+				view.RecentCount += count
+			}
+		}
+		views = append(views, view)
 	}
 
 	data := clientsPage{
@@ -31,9 +59,10 @@ func (a *App) handleAdminClients(w http.ResponseWriter, r *http.Request) {
 		Clients:    views,
 		Page:       page,
 		Total:      total,
-		TotalPages: totalPages(total),
+		TotalPages: totalPages(total, size),
 		PrevPage:   prevPage(page),
-		NextPage:   nextPage(page, total),
+		NextPage:   nextPage(page, total, size),
+		PerPage:    a.perPageParam(size),
 	}
 
 	a.renderTemplate(w, r, "clients.html", data)
@@ -53,15 +82,21 @@ func (a *App) handleAdminCreateClient(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "name and allowed domain required", http.StatusBadRequest)
 		return
 	}
-	if _, err := a.Store.CreateClient(name, domain); err != nil {
+	client, err := a.Store.CreateClient(name, domain)
+	if err != nil {
+		if apperrors.IsInvalidInput(err) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		http.Error(w, "failed to create client", http.StatusInternalServerError)
 		return
 	}
+	a.audit(r, store.AuditCreate, store.AuditTargetClient, client.ID, fmt.Sprintf("%s (%s)", client.Name, client.AllowedDomain))
 	http.Redirect(w, r, "/admin/clients", http.StatusFound)
 }
 
 // handleAdminEditClient displays the edit form for a specific client.
-// Shows the current values for the client's name and allowed domain.
+// Shows the current values for the client's name, allowed domain, and custom CSS.
 func (a *App) handleAdminEditClient(w http.ResponseWriter, r *http.Request) {
 	clientID, err := parseID(chi.URLParam(r, "clientID"))
 	if err != nil {
@@ -80,7 +115,7 @@ func (a *App) handleAdminEditClient(w http.ResponseWriter, r *http.Request) {
 	a.renderTemplate(w, r, "client_edit.html", data)
 }
 
-// handleAdminUpdateClient updates an existing client's name and allowed domain.
+// handleAdminUpdateClient updates an existing client's name, allowed domain, and custom CSS.
 // Redirects back to the clients list after successful update.
 func (a *App) handleAdminUpdateClient(w http.ResponseWriter, r *http.Request) {
 	clientID, err := parseID(chi.URLParam(r, "clientID"))
@@ -98,10 +133,15 @@ func (a *App) handleAdminUpdateClient(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "name and allowed domain required", http.StatusBadRequest)
 		return
 	}
-	if err := a.Store.UpdateClient(clientID, name, domain); err != nil {
+	if err := a.Store.UpdateClient(clientID, name, domain, r.FormValue("custom_css")); err != nil {
+		if apperrors.IsInvalidInput(err) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		http.Error(w, "failed to update client", http.StatusInternalServerError)
 		return
 	}
+	a.audit(r, store.AuditUpdate, store.AuditTargetClient, clientID, fmt.Sprintf("%s (%s)", name, domain))
 	http.Redirect(w, r, "/admin/clients", http.StatusFound)
 }
 
@@ -113,19 +153,188 @@ func (a *App) handleAdminDeleteClient(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Look up the name first so the audit entry still says what was deleted
+	var detail string
+	if client, err := a.Store.GetClient(clientID); err == nil {
+		detail = client.Name
+	}
 	if err := a.Store.DeleteClient(clientID); err != nil {
 		http.Error(w, "failed to delete client", http.StatusInternalServerError)
 		return
 	}
+	a.audit(r, store.AuditDelete, store.AuditTargetClient, clientID, detail)
 
 	http.Redirect(w, r, "/admin/clients", http.StatusFound)
 }
 
+// exportPageSize is how many submissions handleAdminExportClient loads per query.
+const exportPageSize = 500
+
+// handleAdminExportClient downloads a client, its forms, and all of its submissions,
+// archived ones included, as one JSON document for data portability requests.
+// Submissions are written page by page, so large clients are never held in memory at once.
+// Returns 404 if the client doesn't exist.
+func (a *App) handleAdminExportClient(w http.ResponseWriter, r *http.Request) {
+	clientID, err := parseID(chi.URLParam(r, "clientID"))
+	if err != nil {
+		http.Error(w, "invalid client", http.StatusBadRequest)
+		return
+	}
+	client, err := a.Store.GetClient(clientID)
+	if err != nil {
+		if apperrors.IsNotFound(err) {
+			http.Error(w, "client not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "failed to load client", http.StatusInternalServerError)
+		return
+	}
+	forms, err := a.Store.ListForms(clientID)
+	if err != nil {
+		http.Error(w, "failed to load forms", http.StatusInternalServerError)
+		return
+	}
+
+	// Everything but the submissions is small, so it is encoded up front, before the status is sent
+	formItems := make([]apiForm, 0, len(forms))
+	for _, f := range forms {
+		formItems = append(formItems, newAPIForm(f))
+	}
+	head, err := json.Marshal(clientExport{
+		GeneratedAt: time.Now().UTC().Format(time.RFC3339),
+		Client: apiClient{
+			ID:            client.ID,
+			Name:          client.Name,
+			AllowedDomain: client.AllowedDomain,
+			CreatedAt:     client.CreatedAt.UTC().Format(time.RFC3339),
+		},
+		Forms: formItems,
+	})
+	if err != nil {
+		http.Error(w, "failed to encode export", http.StatusInternalServerError)
+		return
+	}
+
+	// A large export can take longer than the server's write timeout allows
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil {
+		slog.Warn("Failed to lift the write deadline for a client export", "error", err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="client-%d-export.json"`, clientID))
+	w.WriteHeader(http.StatusOK)
+
+	// Splice the submissions array into the envelope in place of its closing brace
+	_, _ = w.Write(head[:len(head)-1])
+	_, _ = w.Write([]byte(`,"submissions":[`))
+	var afterID int64
+	first := true
+	for {
+		submissions, err := a.Store.ListClientSubmissions(clientID, afterID, exportPageSize)
+		if err != nil {
+			// The status is already sent; stopping leaves truncated JSON that no parser accepts
+			slog.Error("Client export failed", "client_id", clientID, "after_id", afterID, "error", err)
+			return
+		}
+		if len(submissions) == 0 {
+			break
+		}
+		for _, sub := range submissions {
+			item, err := json.Marshal(newAPISubmission(sub))
+			if err != nil {
+				slog.Error("Client export failed", "client_id", clientID, "submission_id", sub.ID, "error", err)
+				return
+			}
+			if !first {
+				_, _ = w.Write([]byte(","))
+			}
+			first = false
+			_, _ = w.Write(item)
+		}
+		afterID = submissions[len(submissions)-1].ID
+	}
+	_, _ = w.Write([]byte("]}\n"))
+}
+
+// clientExport is the JSON envelope written by handleAdminExportClient.
+// The submissions array is streamed after these fields.
+type clientExport struct {
+	GeneratedAt string    `json:"generated_at"`
+	Client      apiClient `json:"client"`
+	Forms       []apiForm `json:"forms"`
+}
+
+// apiClient is the JSON representation of a client.
+type apiClient struct {
+	ID            int64  `json:"id"`
+	Name          string `json:"name"`
+	AllowedDomain string `json:"allowed_domain"`
+	CreatedAt     string `json:"created_at"`
+}
+
 // clientView is a view model for rendering client information.
 // It includes a formatted timestamp for display in templates.
 type clientView struct {
 	store.Client
-	CreatedAt string
+	CreatedAt       string
+	SubmissionCount int
+	Sparkline       string // SVG polyline points of daily submissions, empty if unavailable
+	RecentCount     int    // Submissions in the sparkline's range
+
+	OldestOpen        string // Age of the oldest open submission, empty if there is none
+	OldestOpenOverdue bool   // Whether that submission has waited at least openAgeWarning
+}
+
+// openAgeWarning is how long an open submission may wait before the clients list flags it.
+const openAgeWarning = 48 * time.Hour
+
+// formatAge formats a duration as its two largest units, e.g. "3d 4h" or "2h 15m".
+// Ages under a minute are shown as "<1m".
+func formatAge(age time.Duration) string {
+	days := int(age / (24 * time.Hour))
+	hours := int(age % (24 * time.Hour) / time.Hour)
+	minutes := int(age % time.Hour / time.Minute)
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd %dh", days, hours)
+	case hours > 0:
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	case minutes > 0:
+		return fmt.Sprintf("%dm", minutes)
+	default:
+		return "<1m"
+	}
+}
+
+// Sparkline size: sparklineDays daily points drawn in a sparklineWidth x sparklineHeight SVG.
+const (
+	sparklineDays   = 30
+	sparklineWidth  = 120
+	sparklineHeight = 24
+)
+
+// sparklinePoints converts daily counts into SVG polyline points scaled to the
+// sparkline size, with the busiest day touching the top edge.
+func sparklinePoints(counts []int) string {
+	if len(counts) == 0 {
+		return ""
+	}
+	peak := 1
+	for _, count := range counts {
+		peak = max(peak, count)
+	}
+	step := 0.0
+	if len(counts) > 1 {
+		step = float64(sparklineWidth) / float64(len(counts)-1)
+	}
+	// Keep a pixel of margin so the stroke isn't clipped at the edges
+	usable := float64(sparklineHeight - 2)
+	points := make([]string, len(counts))
+	for i, count := range counts {
+		y := 1 + usable - usable*float64(count)/float64(peak)
+		points[i] = strconv.FormatFloat(float64(i)*step, 'f', 1, 64) + "," + strconv.FormatFloat(y, 'f', 1, 64)
+	}
+	return strings.Join(points, " ")
 }
 
 // clientsPage is the data structure for the clients list page.
@@ -138,6 +347,7 @@ type clientsPage struct {
 	TotalPages int
 	PrevPage   int
 	NextPage   int
+	PerPage    int // Page size from per_page, 0 when using the configured default
 }
 
 // clientEditPage is the data structure for the client edit page.
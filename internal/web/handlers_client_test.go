@@ -0,0 +1,66 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"ticketd/internal/store"
+)
+
+func TestAdminExportClient(t *testing.T) {
+	app := newTestApp(t, nil)
+	form := createTestForm(t, app, "example.com", store.FormTypeSupport)
+	other := createTestForm(t, app, "other.example", store.FormTypeSupport)
+	var want []int64
+	for i := 0; i < 3; i++ {
+		want = append(want, createTestSubmission(t, app, form).ID)
+	}
+	createTestSubmission(t, app, other)
+
+	// A write timeout that has already expired when the handler starts: the export only
+	// gets through if the handler lifts the deadline before writing
+	server := httptest.NewUnstartedServer(app.Router())
+	server.Config.WriteTimeout = time.Nanosecond
+	server.Start()
+	defer server.Close()
+
+	resp, err := server.Client().Get(fmt.Sprintf("%s/admin/clients/%d/export.json", server.URL, form.ClientID))
+	if err != nil {
+		t.Fatalf("GET export: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+
+	var export struct {
+		GeneratedAt string          `json:"generated_at"`
+		Client      apiClient       `json:"client"`
+		Forms       []apiForm       `json:"forms"`
+		Submissions []apiSubmission `json:"submissions"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&export); err != nil {
+		t.Fatalf("decode export: %v", err)
+	}
+	if _, err := time.Parse(time.RFC3339, export.GeneratedAt); err != nil {
+		t.Errorf("generated_at %q: %v", export.GeneratedAt, err)
+	}
+	if export.Client.ID != form.ClientID || len(export.Forms) != 1 || export.Forms[0].ID != form.ID {
+		t.Errorf("export has client %d and forms %v, want client %d and form %d", export.Client.ID, export.Forms, form.ClientID, form.ID)
+	}
+	var got []int64
+	for _, sub := range export.Submissions {
+		got = append(got, sub.ID)
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("exported submissions = %v, want %v", got, want)
+	}
+
+	if rec := serve(t, app, httptest.NewRequest(http.MethodGet, "/admin/clients/9999/export.json", nil)); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown client: status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,119 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"ticketd/internal/store"
+	"ticketd/internal/validator"
+)
+
+// dashboardMessageBuckets are the message length bucket lower bounds shown on the dashboard.
+var dashboardMessageBuckets = []int{0, 100, 500, 1000, 5000}
+
+// dashboardTopSubjects is how many recurring subjects the dashboard lists.
+const dashboardTopSubjects = 5
+
+// dashboardBusiestForms is how many trending forms the dashboard lists.
+const dashboardBusiestForms = 5
+
+// handleAdminDashboard displays an overview of submission volume.
+// Counts are aggregated by the store; this handler only orders them for display.
+func (a *App) handleAdminDashboard(w http.ResponseWriter, r *http.Request) {
+	stats, err := a.Store.SubmissionStats()
+	if err != nil {
+		http.Error(w, "failed to load statistics", http.StatusInternalServerError)
+		return
+	}
+	weekAgo := time.Now().AddDate(0, 0, -7)
+	subjects, err := a.Store.TopSubjects(dashboardTopSubjects, weekAgo, time.Time{})
+	if err != nil {
+		http.Error(w, "failed to load statistics", http.StatusInternalServerError)
+		return
+	}
+	lengths, err := a.Store.MessageLengthDistribution(dashboardMessageBuckets)
+	if err != nil {
+		http.Error(w, "failed to load statistics", http.StatusInternalServerError)
+		return
+	}
+	sources, err := a.Store.CountSubmissionsBySource()
+	if err != nil {
+		http.Error(w, "failed to load statistics", http.StatusInternalServerError)
+		return
+	}
+	busiest, err := a.Store.BusiestForms(weekAgo, dashboardBusiestForms)
+	if err != nil {
+		http.Error(w, "failed to load statistics", http.StatusInternalServerError)
+		return
+	}
+
+	data := dashboardPage{
+		Active:      "dashboard",
+		Stats:       stats,
+		TopSubjects: subjects,
+	}
+	for _, status := range []string{validator.StatusOpen, validator.StatusInProgress, validator.StatusClosed, validator.StatusSpam} {
+		data.Statuses = append(data.Statuses, statCount{
+			Label: status,
+			Count: stats.ByStatus[status],
+			URL:   submissionsURL(store.SubmissionFilter{Status: status}, 1, 0),
+		})
+	}
+	for _, formType := range []store.FormType{store.FormTypeSupport, store.FormTypeContact} {
+		data.FormTypes = append(data.FormTypes, statCount{
+			Label: string(formType),
+			Count: stats.ByFormType[formType],
+		})
+	}
+	for _, source := range []string{store.SourceWidget, store.SourceAPIJSON, store.SourceFormPost} {
+		data.Sources = append(data.Sources, statCount{Label: source, Count: sources[source]})
+	}
+	// Only show the legacy bucket when there are submissions from before sources were recorded
+	if unknown := sources["unknown"]; unknown > 0 {
+		data.Sources = append(data.Sources, statCount{Label: "unknown", Count: unknown})
+	}
+	for i, bound := range dashboardMessageBuckets {
+		label := fmt.Sprintf("%d+", bound)
+		if i+1 < len(dashboardMessageBuckets) {
+			label = fmt.Sprintf("%d–%d", bound, dashboardMessageBuckets[i+1]-1)
+		}
+		data.MessageLengths = append(data.MessageLengths, statCount{Label: label, Count: lengths[bound]})
+	}
+	for _, activity := range busiest {
+		data.BusiestForms = append(data.BusiestForms, statCount{
+			Label: activity.Client + " / " + activity.Form,
+			Count: activity.Count,
+			URL:   submissionsURL(store.SubmissionFilter{FormID: activity.FormID}, 1, 0),
+		})
+	}
+	for _, client := range stats.TopClients {
+		data.TopClients = append(data.TopClients, statCount{
+			Label: client.Client,
+			Count: client.Count,
+			URL:   submissionsURL(store.SubmissionFilter{ClientID: client.ClientID}, 1, 0),
+		})
+	}
+
+	a.renderTemplate(w, r, "dashboard.html", data)
+}
+
+// statCount is a labelled count on the dashboard, optionally linking to the matching submissions.
+type statCount struct {
+	Label string
+	Count int
+	URL   string
+}
+
+// dashboardPage is the data structure for the admin dashboard.
+type dashboardPage struct {
+	Active         string
+	Stats          store.SubmissionStats
+	Statuses       []statCount
+	FormTypes      []statCount
+	Sources        []statCount
+	TopClients     []statCount
+	BusiestForms   []statCount
+	TopSubjects    []store.SubjectCount
+	MessageLengths []statCount
+}
@@ -2,12 +2,19 @@ package web
 
 import (
 	"fmt"
+	"html/template"
+	"log"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 
+	apperrors "ticketd/internal/errors"
 	"ticketd/internal/store"
+	"ticketd/internal/validator"
 )
 
 // handleAdminForms displays all forms for a specific client.
@@ -32,7 +39,7 @@ func (a *App) handleAdminForms(w http.ResponseWriter, r *http.Request) {
 
 	views := make([]formView, 0, len(forms))
 	for _, f := range forms {
-		views = append(views, formView{Form: f, CreatedAt: formatTime(f.CreatedAt)})
+		views = append(views, formView{Form: f, CreatedAt: formatTime(f.CreatedAt), EmbedQuery: a.embedQuery(f.ID)})
 	}
 
 	baseURL, note := a.baseURLForAdmin(r)
@@ -42,10 +49,76 @@ func (a *App) handleAdminForms(w http.ResponseWriter, r *http.Request) {
 		Forms:       views,
 		BaseURL:     baseURL,
 		BaseURLNote: note,
+		SignEmbeds:  a.Cfg.SignEmbeds,
+		EmbedExpiry: formatTime(time.Now().Add(a.Cfg.EmbedTokenTTL)),
+		Languages:   embedLanguages,
 	}
 	a.renderTemplate(w, r, "forms.html", data)
 }
 
+// handleAdminAllForms displays a paginated list of forms across all clients.
+// The list can be narrowed to a single form type with the type query parameter.
+func (a *App) handleAdminAllForms(w http.ResponseWriter, r *http.Request) {
+	page := parsePage(r)
+	size := a.pageSize(r)
+	offset := (page - 1) * size
+
+	formType := store.FormType(strings.TrimSpace(r.URL.Query().Get("type")))
+	if formType != "" {
+		if err := validator.ValidateFormType(formType); err != nil {
+			http.Error(w, "invalid form type", http.StatusBadRequest)
+			return
+		}
+	}
+
+	forms, total, err := a.Store.ListFormsByType(formType, offset, size)
+	if err != nil {
+		http.Error(w, "failed to load forms", http.StatusInternalServerError)
+		return
+	}
+
+	views := make([]formView, 0, len(forms))
+	for _, f := range forms {
+		views = append(views, formView{Form: f, CreatedAt: formatTime(f.CreatedAt)})
+	}
+
+	data := allFormsPage{
+		Active:     "forms",
+		Forms:      views,
+		FilterType: string(formType),
+		Page:       page,
+		Total:      total,
+		TotalPages: totalPages(total, size),
+		PerPage:    a.perPageParam(size),
+	}
+	if prev := prevPage(page); prev > 0 {
+		data.PrevURL = allFormsURL(formType, prev, data.PerPage)
+	}
+	if next := nextPage(page, total, size); next > 0 {
+		data.NextURL = allFormsURL(formType, next, data.PerPage)
+	}
+	a.renderTemplate(w, r, "all_forms.html", data)
+}
+
+// allFormsURL builds a global forms list URL that keeps the type filter.
+// A zero perPage leaves the page size at the configured default.
+func allFormsURL(formType store.FormType, page, perPage int) string {
+	values := url.Values{}
+	if formType != "" {
+		values.Set("type", string(formType))
+	}
+	if page > 1 {
+		values.Set("page", strconv.Itoa(page))
+	}
+	if perPage > 0 {
+		values.Set("per_page", strconv.Itoa(perPage))
+	}
+	if len(values) == 0 {
+		return "/admin/forms"
+	}
+	return "/admin/forms?" + values.Encode()
+}
+
 // handleAdminCreateForm creates a new form for a client.
 // Forms can be of type "contact" or "support", which determines the required fields.
 // Redirects back to the forms list after successful creation.
@@ -59,17 +132,25 @@ func (a *App) handleAdminCreateForm(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "invalid payload", http.StatusBadRequest)
 		return
 	}
-	name := strings.TrimSpace(r.FormValue("name"))
-	typeValue := strings.TrimSpace(r.FormValue("type"))
-	formType := store.FormType(typeValue)
-	if name == "" {
+	input := formInputFromRequest(r)
+	if input.Name == "" {
 		http.Error(w, "name required", http.StatusBadRequest)
 		return
 	}
-	if _, err := a.Store.CreateForm(clientID, name, formType); err != nil {
+	form, err := a.Store.CreateForm(clientID, input)
+	if err != nil {
+		if apperrors.IsInvalidInput(err) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if apperrors.IsRateLimited(err) {
+			http.Error(w, "too many forms created recently for this client, try again later", http.StatusTooManyRequests)
+			return
+		}
 		http.Error(w, "failed to create form", http.StatusInternalServerError)
 		return
 	}
+	a.audit(r, store.AuditCreate, store.AuditTargetForm, form.ID, fmt.Sprintf("%s (%s) for client %d", form.Name, form.Type, clientID))
 	http.Redirect(w, r, fmt.Sprintf("/admin/clients/%d/forms", clientID), http.StatusFound)
 }
 
@@ -99,9 +180,10 @@ func (a *App) handleAdminEditFormPage(w http.ResponseWriter, r *http.Request) {
 	}
 
 	data := formEditPage{
-		Active:   "clients",
-		ClientID: clientID,
-		Form:     form,
+		Active:    "clients",
+		ClientID:  clientID,
+		Form:      form,
+		Languages: embedLanguages,
 	}
 	a.renderTemplate(w, r, "form_edit.html", data)
 }
@@ -124,11 +206,8 @@ func (a *App) handleAdminUpdateForm(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	name := strings.TrimSpace(r.FormValue("name"))
-	typeValue := strings.TrimSpace(r.FormValue("type"))
-	formType := store.FormType(typeValue)
-
-	if name == "" {
+	input := formInputFromRequest(r)
+	if input.Name == "" {
 		http.Error(w, "name required", http.StatusBadRequest)
 		return
 	}
@@ -144,10 +223,223 @@ func (a *App) handleAdminUpdateForm(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := a.Store.UpdateForm(formID, name, formType); err != nil {
+	if err := a.Store.UpdateForm(formID, input); err != nil {
+		if apperrors.IsInvalidInput(err) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		http.Error(w, "failed to update form", http.StatusInternalServerError)
 		return
 	}
+	a.audit(r, store.AuditUpdate, store.AuditTargetForm, formID, fmt.Sprintf("%s for client %d", input.Name, clientID))
+
+	http.Redirect(w, r, fmt.Sprintf("/admin/clients/%d/forms", clientID), http.StatusFound)
+}
+
+// defaultFormStatsDays is the default reporting window of the form stats page.
+const defaultFormStatsDays = 90
+
+// formPreviewTemplate is a bare page that loads a form's real embed script, for
+// showing it in the sandboxed preview iframe on the forms page.
+var formPreviewTemplate = template.Must(template.New("preview").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <title>Preview: {{.Name}}</title>
+  <style>body { margin: 0; padding: 1rem; font-family: system-ui, sans-serif; background: #fff; }</style>
+</head>
+<body>
+  <script src="{{.ScriptURL}}"></script>
+</body>
+</html>
+`))
+
+// handleAdminFormPreview serves a page that embeds the form exactly as a client's site would.
+// The forms page shows it in a sandboxed iframe without same-origin access, so submissions
+// from the preview carry a null origin and are rejected by the origin check.
+func (a *App) handleAdminFormPreview(w http.ResponseWriter, r *http.Request) {
+	formID, err := parseID(chi.URLParam(r, "formID"))
+	if err != nil {
+		http.Error(w, "invalid form", http.StatusBadRequest)
+		return
+	}
+	form, err := a.Store.GetForm(formID)
+	if err != nil {
+		http.Error(w, "form not found", http.StatusNotFound)
+		return
+	}
+
+	data := struct {
+		Name      string
+		ScriptURL string
+	}{
+		Name:      form.Name,
+		ScriptURL: fmt.Sprintf("/embed/%d.js%s", form.ID, a.embedQuery(form.ID)),
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := formPreviewTemplate.Execute(w, data); err != nil {
+		log.Printf("template error (preview): %v", err)
+	}
+}
+
+// handleAdminFormStats displays when a form's submissions arrive, by weekday in the configured timezone.
+// The window defaults to the last 90 days and can be changed with the days query parameter (1-365).
+func (a *App) handleAdminFormStats(w http.ResponseWriter, r *http.Request) {
+	clientID, err := parseID(chi.URLParam(r, "clientID"))
+	if err != nil {
+		http.Error(w, "invalid client", http.StatusBadRequest)
+		return
+	}
+	formID, err := parseID(chi.URLParam(r, "formID"))
+	if err != nil {
+		http.Error(w, "invalid form", http.StatusBadRequest)
+		return
+	}
+
+	form, err := a.Store.GetForm(formID)
+	if err != nil || form.ClientID != clientID {
+		http.Error(w, "form not found", http.StatusNotFound)
+		return
+	}
+	client, err := a.Store.GetClient(clientID)
+	if err != nil {
+		http.Error(w, "client not found", http.StatusNotFound)
+		return
+	}
+
+	days := defaultFormStatsDays
+	if raw := r.URL.Query().Get("days"); raw != "" {
+		days, err = strconv.Atoi(raw)
+		if err != nil || days < 1 || days > 365 {
+			http.Error(w, "days must be between 1 and 365", http.StatusBadRequest)
+			return
+		}
+	}
+
+	now := time.Now().In(a.location)
+	from := now.AddDate(0, 0, -days)
+	counts, err := a.Store.FormSubmissionsByWeekday(formID, from, time.Time{})
+	if err != nil {
+		http.Error(w, "failed to load statistics", http.StatusInternalServerError)
+		return
+	}
+
+	data := formStatsPage{
+		Active:   "clients",
+		Client:   client,
+		Form:     form,
+		Days:     days,
+		From:     formatTime(from),
+		Timezone: a.location.String(),
+
+		WindowOptions: []int{7, 30, 90, 365},
+	}
+	// List Monday first; counts are indexed by time.Weekday, which starts on Sunday
+	for i := 1; i <= 7; i++ {
+		day := time.Weekday(i % 7)
+		data.Weekdays = append(data.Weekdays, statCount{Label: day.String(), Count: counts[day]})
+		data.Total += counts[day]
+		if counts[day] > data.Max {
+			data.Max = counts[day]
+		}
+	}
+	a.renderTemplate(w, r, "form_stats.html", data)
+}
+
+// formInputFromRequest reads form settings from a parsed create or edit request.
+// The unique_email and priority_field checkboxes are only present in the request when they are ticked.
+func formInputFromRequest(r *http.Request) store.FormInput {
+	return store.FormInput{
+		Name:          strings.TrimSpace(r.FormValue("name")),
+		Type:          store.FormType(strings.TrimSpace(r.FormValue("type"))),
+		UniqueEmail:   r.FormValue("unique_email") != "",
+		PriorityField: r.FormValue("priority_field") != "",
+		Language:      strings.TrimSpace(r.FormValue("language")),
+		SuccessURL:    strings.TrimSpace(r.FormValue("success_url")),
+		Theme: store.FormTheme{
+			PrimaryColor: strings.TrimSpace(r.FormValue("theme_primary")),
+			BorderRadius: strings.TrimSpace(r.FormValue("theme_radius")),
+			FontFamily:   strings.TrimSpace(r.FormValue("theme_font")),
+		},
+		// One per line; blank lines are dropped by the store
+		Categories:     strings.Split(strings.ReplaceAll(r.FormValue("categories"), "\r\n", "\n"), "\n"),
+		RequireConsent: r.FormValue("require_consent") != "",
+		ConsentLabel:   strings.TrimSpace(r.FormValue("consent_label")),
+		PrivacyURL:     strings.TrimSpace(r.FormValue("privacy_url")),
+	}
+}
+
+// handleAdminCloneForm creates a copy of a form under the same client, for setting up
+// similar forms quickly. Submissions stay with the original.
+func (a *App) handleAdminCloneForm(w http.ResponseWriter, r *http.Request) {
+	clientID, err := parseID(chi.URLParam(r, "clientID"))
+	if err != nil {
+		http.Error(w, "invalid client", http.StatusBadRequest)
+		return
+	}
+	formID, err := parseID(chi.URLParam(r, "formID"))
+	if err != nil {
+		http.Error(w, "invalid form", http.StatusBadRequest)
+		return
+	}
+
+	// Verify form belongs to the client
+	form, err := a.Store.GetForm(formID)
+	if err != nil || form.ClientID != clientID {
+		http.Error(w, "form not found", http.StatusNotFound)
+		return
+	}
+
+	clone, err := a.Store.CloneForm(formID)
+	if err != nil {
+		if apperrors.IsInvalidInput(err) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if apperrors.IsRateLimited(err) {
+			http.Error(w, "too many forms created recently for this client, try again later", http.StatusTooManyRequests)
+			return
+		}
+		http.Error(w, "failed to duplicate form", http.StatusInternalServerError)
+		return
+	}
+	a.audit(r, store.AuditCreate, store.AuditTargetForm, clone.ID, fmt.Sprintf("%s (%s) for client %d, copied from form %d", clone.Name, clone.Type, clientID, formID))
+
+	http.Redirect(w, r, fmt.Sprintf("/admin/clients/%d/forms", clientID), http.StatusFound)
+}
+
+// handleAdminSetFormActive starts or stops a form accepting submissions without deleting
+// it, e.g. during maintenance. The active form value is "true" or "false".
+func (a *App) handleAdminSetFormActive(w http.ResponseWriter, r *http.Request) {
+	clientID, err := parseID(chi.URLParam(r, "clientID"))
+	if err != nil {
+		http.Error(w, "invalid client", http.StatusBadRequest)
+		return
+	}
+	formID, err := parseID(chi.URLParam(r, "formID"))
+	if err != nil {
+		http.Error(w, "invalid form", http.StatusBadRequest)
+		return
+	}
+
+	// Verify form belongs to the client
+	form, err := a.Store.GetForm(formID)
+	if err != nil || form.ClientID != clientID {
+		http.Error(w, "form not found", http.StatusNotFound)
+		return
+	}
+
+	active := r.FormValue("active") == "true"
+	if err := a.Store.SetFormActive(formID, active); err != nil {
+		http.Error(w, "failed to update form", http.StatusInternalServerError)
+		return
+	}
+	change := "disabled"
+	if active {
+		change = "enabled"
+	}
+	a.audit(r, store.AuditUpdate, store.AuditTargetForm, formID, fmt.Sprintf("%s for client %d %s", form.Name, clientID, change))
 
 	http.Redirect(w, r, fmt.Sprintf("/admin/clients/%d/forms", clientID), http.StatusFound)
 }
@@ -180,6 +472,7 @@ func (a *App) handleAdminDeleteForm(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "failed to delete form", http.StatusInternalServerError)
 		return
 	}
+	a.audit(r, store.AuditDelete, store.AuditTargetForm, formID, fmt.Sprintf("%s for client %d", form.Name, clientID))
 
 	http.Redirect(w, r, fmt.Sprintf("/admin/clients/%d/forms", clientID), http.StatusFound)
 }
@@ -188,7 +481,8 @@ func (a *App) handleAdminDeleteForm(w http.ResponseWriter, r *http.Request) {
 // It includes a formatted timestamp for display in templates.
 type formView struct {
 	store.Form
-	CreatedAt string
+	CreatedAt  string
+	EmbedQuery string // Signature query string for the embed URL, empty unless embed signing is enabled
 }
 
 // formsPage is the data structure for the forms list page.
@@ -199,11 +493,43 @@ type formsPage struct {
 	Forms       []formView
 	BaseURL     string
 	BaseURLNote string
+	SignEmbeds  bool
+	EmbedExpiry string
+	Languages   []embedLanguage
 }
 
-// formEditPage is the data structure for the form edit page.
-type formEditPage struct {
+// allFormsPage is the data structure for the global forms list page.
+type allFormsPage struct {
+	Active     string
+	Forms      []formView
+	FilterType string
+	Page       int
+	Total      int
+	TotalPages int
+	PrevURL    string
+	NextURL    string
+	PerPage    int // Page size from per_page, 0 when using the configured default
+}
+
+// formStatsPage is the data structure for the per-form statistics page.
+type formStatsPage struct {
 	Active   string
-	ClientID int64
+	Client   store.Client
 	Form     store.Form
+	Days     int
+	From     string
+	Timezone string
+	Weekdays []statCount
+	Total    int
+	Max      int // Largest weekday count, used to scale the bars
+
+	WindowOptions []int // Preset values for the days selector
+}
+
+// formEditPage is the data structure for the form edit page.
+type formEditPage struct {
+	Active    string
+	ClientID  int64
+	Form      store.Form
+	Languages []embedLanguage
 }
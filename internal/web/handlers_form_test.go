@@ -0,0 +1,110 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"ticketd/internal/store"
+)
+
+func TestAdminSetFormActive(t *testing.T) {
+	app := newTestApp(t, nil)
+	form := createTestForm(t, app, "example.com", store.FormTypeSupport)
+	other := createTestForm(t, app, "other.example", store.FormTypeSupport)
+	target := fmt.Sprintf("/admin/clients/%d/forms/%d/active", form.ClientID, form.ID)
+
+	for _, active := range []bool{false, true} {
+		rec := serve(t, app, newFormPost(target, url.Values{"active": {fmt.Sprint(active)}}))
+		if rec.Code != http.StatusFound {
+			t.Fatalf("active=%t: status = %d, want %d", active, rec.Code, http.StatusFound)
+		}
+		got, err := app.Store.GetForm(form.ID)
+		if err != nil {
+			t.Fatalf("GetForm: %v", err)
+		}
+		if got.Active != active {
+			t.Errorf("after active=%t the form has active %t", active, got.Active)
+		}
+	}
+
+	// A form can only be toggled through its own client
+	rec := serve(t, app, newFormPost(fmt.Sprintf("/admin/clients/%d/forms/%d/active", other.ClientID, form.ID), url.Values{"active": {"false"}}))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("other client: status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+	if got, _ := app.Store.GetForm(form.ID); !got.Active {
+		t.Errorf("form was disabled through another client")
+	}
+}
+
+func TestAdminCloneForm(t *testing.T) {
+	app := newTestApp(t, nil)
+	form := createTestForm(t, app, "example.com", store.FormTypeSupport)
+	createTestSubmission(t, app, form)
+	other := createTestForm(t, app, "other.example", store.FormTypeSupport)
+
+	rec := serve(t, app, newFormPost(fmt.Sprintf("/admin/clients/%d/forms/%d/clone", form.ClientID, form.ID), nil))
+	if rec.Code != http.StatusFound {
+		t.Fatalf("status = %d, want %d (body %q)", rec.Code, http.StatusFound, rec.Body.String())
+	}
+	if want := fmt.Sprintf("/admin/clients/%d/forms", form.ClientID); rec.Header().Get("Location") != want {
+		t.Errorf("Location = %q, want %q", rec.Header().Get("Location"), want)
+	}
+	forms, err := app.Store.ListForms(form.ClientID)
+	if err != nil {
+		t.Fatalf("ListForms: %v", err)
+	}
+	if len(forms) != 2 {
+		t.Fatalf("client has %d forms, want 2", len(forms))
+	}
+	for _, f := range forms {
+		if f.ID != form.ID && (f.Name != form.Name+" (copy)" || f.Type != form.Type) {
+			t.Errorf("copy is %q (%s), want %q (%s)", f.Name, f.Type, form.Name+" (copy)", form.Type)
+		}
+	}
+
+	// A form can only be copied through its own client
+	rec = serve(t, app, newFormPost(fmt.Sprintf("/admin/clients/%d/forms/%d/clone", other.ClientID, form.ID), nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("other client: status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestAdminFormPriorityField(t *testing.T) {
+	app := newTestApp(t, nil)
+	client, err := app.Store.CreateClient("Example", "example.com")
+	if err != nil {
+		t.Fatalf("CreateClient: %v", err)
+	}
+
+	rec := serve(t, app, newFormPost(fmt.Sprintf("/admin/clients/%d/forms", client.ID), url.Values{
+		"name":           {"Contact"},
+		"type":           {string(store.FormTypeContact)},
+		"priority_field": {"on"},
+	}))
+	if rec.Code != http.StatusFound {
+		t.Fatalf("create: status = %d, want %d (body %q)", rec.Code, http.StatusFound, rec.Body.String())
+	}
+	forms, err := app.Store.ListForms(client.ID)
+	if err != nil || len(forms) != 1 {
+		t.Fatalf("ListForms = %v, %v, want one form", forms, err)
+	}
+	form := forms[0]
+	if !form.PriorityField {
+		t.Errorf("created form has no priority field")
+	}
+
+	// An unticked checkbox is left out of the request
+	rec = serve(t, app, newFormPost(fmt.Sprintf("/admin/clients/%d/forms/%d/edit", client.ID, form.ID), url.Values{
+		"name": {"Contact"},
+		"type": {string(store.FormTypeContact)},
+	}))
+	if rec.Code != http.StatusFound {
+		t.Fatalf("edit: status = %d, want %d (body %q)", rec.Code, http.StatusFound, rec.Body.String())
+	}
+	if got, _ := app.Store.GetForm(form.ID); got.PriorityField {
+		t.Errorf("priority field still enabled after unticking it")
+	}
+}
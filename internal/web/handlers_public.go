@@ -1,25 +1,82 @@
 package web
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
 	"net/http"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 )
 
+// readyTimeout bounds how long handleReady waits for the database.
+const readyTimeout = 2 * time.Second
+
+// handleReady is the readiness probe: it reports 200 only while the database answers.
+// Unlike /health, which just shows the process is up, it returns 503 with a JSON error
+// when the database can't be reached within readyTimeout, so traffic is routed elsewhere.
+func (a *App) handleReady(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), readyTimeout)
+	defer cancel()
+	if err := a.Store.Ping(ctx); err != nil {
+		slog.Warn("Readiness check failed", "error", err)
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "database unavailable"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ready"})
+}
+
 // handleFormCSS serves the CSS stylesheet for embedded forms.
 // If a custom CSS path is configured and the file exists, it serves that.
 // Otherwise, it serves the default embedded CSS.
 func (a *App) handleFormCSS(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "text/css; charset=utf-8")
+	_, _ = w.Write(a.formCSS())
+}
+
+// handleFormClientCSS serves the stylesheet for one form: the global stylesheet of
+// handleFormCSS followed by the custom CSS of the form's client, if it has any.
+func (a *App) handleFormClientCSS(w http.ResponseWriter, r *http.Request) {
+	formID, err := parseID(chi.URLParam(r, "formID"))
+	if err != nil {
+		http.Error(w, "invalid form", http.StatusBadRequest)
+		return
+	}
+	form, err := a.Store.GetForm(formID)
+	if err != nil {
+		http.Error(w, "form not found", http.StatusNotFound)
+		return
+	}
+	client, err := a.Store.GetClient(form.ClientID)
+	if err != nil {
+		http.Error(w, "client not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/css; charset=utf-8")
+	_, _ = w.Write(a.formCSS())
+	if client.CustomCSS != "" {
+		// Custom CSS is validated on save, and comes last so it overrides the global rules
+		_, _ = fmt.Fprintf(w, "\n/* Client %d */\n%s\n", client.ID, client.CustomCSS)
+	}
+}
+
+// formCSS returns the global embed stylesheet: the configured custom CSS file if it
+// can be read, otherwise the default CSS. Like the default CSS, a custom file written
+// for the default class names is rewritten for the configured class prefix.
+func (a *App) formCSS() []byte {
 	if a.Cfg.CustomCSSPath != "" {
 		data, err := os.ReadFile(a.Cfg.CustomCSSPath)
 		if err == nil {
-			_, _ = w.Write(data)
-			return
+			return prefixEmbedClasses(data, a.Cfg.EmbedClassPrefix)
 		}
 	}
-	_, _ = w.Write(a.DefaultCSS)
+	return a.DefaultCSS
 }
 
 // handleEmbedJS generates and serves the JavaScript embed code for a specific form.
@@ -31,6 +88,13 @@ func (a *App) handleEmbedJS(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "invalid form", http.StatusBadRequest)
 		return
 	}
+	if a.Cfg.SignEmbeds {
+		query := r.URL.Query()
+		if !a.validEmbedSignature(formID, query.Get("exp"), query.Get("sig")) {
+			http.Error(w, "invalid or expired embed link", http.StatusForbidden)
+			return
+		}
+	}
 	form, err := a.Store.GetForm(formID)
 	if err != nil {
 		http.Error(w, "form not found", http.StatusNotFound)
@@ -43,12 +107,40 @@ func (a *App) handleEmbedJS(w http.ResponseWriter, r *http.Request) {
 	}
 
 	baseURL := a.publicBaseURL(r)
-	js, err := buildEmbedJS(form, client, baseURL)
+	// A lang query parameter overrides the form's language, e.g. for multilingual sites
+	lang := form.Language
+	if override := r.URL.Query().Get("lang"); override != "" {
+		lang = override
+	}
+	js, err := buildEmbedJS(form, client, baseURL, lang, a.Cfg.EmbedClassPrefix, a.Cfg.MinSubmitTime > 0)
 	if err != nil {
 		http.Error(w, "script error", http.StatusInternalServerError)
 		return
 	}
 
+	// The ETag is a hash of the script itself, so any change to the form, client, language,
+	// or base URL yields a new tag and browsers pick up the new script once revalidating
+	sum := sha256.Sum256([]byte(js))
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(a.Cfg.EmbedCacheTTL.Seconds())))
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/javascript; charset=utf-8")
 	_, _ = w.Write([]byte(js))
 }
+
+// etagMatches reports whether an If-None-Match header lists etag or is "*".
+// Weak tags (W/"...") match their strong counterpart, as If-None-Match uses weak comparison.
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,153 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"ticketd/internal/config"
+	"ticketd/internal/store"
+)
+
+// embedPath returns the embed script path of a form.
+func embedPath(formID int64) string {
+	return "/embed/" + strconv.FormatInt(formID, 10) + ".js"
+}
+
+func TestHandleEmbedJSSigned(t *testing.T) {
+	app := newTestApp(t, func(cfg *config.Config) {
+		cfg.SessionSecret = strings.Repeat("s", 32)
+		cfg.SignEmbeds = true
+		cfg.EmbedTokenTTL = time.Hour
+	})
+	form := createTestForm(t, app, "example.com", store.FormTypeSupport)
+	other := createTestForm(t, app, "other.example", store.FormTypeSupport)
+	exp, sig := signedEmbedParams(t, app, form.ID)
+	past := strconv.FormatInt(time.Now().Add(-time.Minute).Unix(), 10)
+
+	tests := []struct {
+		name   string
+		target string
+		want   int
+	}{
+		{name: "valid", target: embedPath(form.ID) + app.embedQuery(form.ID), want: http.StatusOK},
+		{name: "unsigned", target: embedPath(form.ID), want: http.StatusForbidden},
+		{name: "tampered signature", target: embedPath(form.ID) + "?exp=" + exp + "&sig=" + tamper(sig), want: http.StatusForbidden},
+		{name: "expired", target: embedPath(form.ID) + "?exp=" + past + "&sig=" + app.signSession(embedPayload(form.ID, past)), want: http.StatusForbidden},
+		{name: "signature for another form", target: embedPath(other.ID) + "?exp=" + exp + "&sig=" + sig, want: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(t, app, httptest.NewRequest(http.MethodGet, tt.target, nil))
+			if rec.Code != tt.want {
+				t.Errorf("GET %s: status = %d, want %d (body %q)", tt.target, rec.Code, tt.want, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandleEmbedJSNotModified(t *testing.T) {
+	app := newTestApp(t, func(cfg *config.Config) { cfg.EmbedCacheTTL = 5 * time.Minute })
+	form := createTestForm(t, app, "example.com", store.FormTypeSupport)
+
+	first := serve(t, app, httptest.NewRequest(http.MethodGet, embedPath(form.ID), nil))
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || etag == "" || first.Body.Len() == 0 {
+		t.Fatalf("status = %d, ETag = %q, body %d bytes, want 200 with an ETag and script", first.Code, etag, first.Body.Len())
+	}
+	if got := first.Header().Get("Cache-Control"); got != "public, max-age=300" {
+		t.Errorf("Cache-Control = %q, want %q", got, "public, max-age=300")
+	}
+
+	tests := []struct {
+		name        string
+		ifNoneMatch string
+		want        int
+	}{
+		{name: "matching tag", ifNoneMatch: etag, want: http.StatusNotModified},
+		{name: "weak tag", ifNoneMatch: "W/" + etag, want: http.StatusNotModified},
+		{name: "one of several tags", ifNoneMatch: `"stale", ` + etag, want: http.StatusNotModified},
+		{name: "wildcard", ifNoneMatch: "*", want: http.StatusNotModified},
+		{name: "stale tag", ifNoneMatch: `"stale"`, want: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, embedPath(form.ID), nil)
+			req.Header.Set("If-None-Match", tt.ifNoneMatch)
+			rec := serve(t, app, req)
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.want)
+			}
+			if rec.Header().Get("ETag") != etag {
+				t.Errorf("ETag = %q, want %q", rec.Header().Get("ETag"), etag)
+			}
+			if tt.want == http.StatusNotModified && rec.Body.Len() != 0 {
+				t.Errorf("304 response has a %d byte body", rec.Body.Len())
+			}
+		})
+	}
+
+	// Updating the form changes the script, so the old tag no longer matches
+	if err := app.Store.UpdateForm(form.ID, store.FormInput{Name: "Renamed", Type: form.Type, PriorityField: true}); err != nil {
+		t.Fatalf("UpdateForm: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodGet, embedPath(form.ID), nil)
+	req.Header.Set("If-None-Match", etag)
+	rec := serve(t, app, req)
+	if rec.Code != http.StatusOK || rec.Header().Get("ETag") == etag {
+		t.Errorf("after update: status = %d, ETag = %q, want 200 with a new ETag", rec.Code, rec.Header().Get("ETag"))
+	}
+}
+
+func TestFormCSSClassPrefix(t *testing.T) {
+	customCSS := writeTestFile(t, "custom.css", ".ticketd-form { color: red; --ticketd-primary: #000; }\n.acme-note { margin: 0; }\n")
+
+	tests := []struct {
+		name       string
+		prefix     string
+		customPath string
+		want       []string
+		notWant    []string
+	}{
+		{name: "default CSS", prefix: config.DefaultEmbedClassPrefix, want: []string{".ticketd-form {"}},
+		{name: "default CSS with prefix", prefix: "acme-", want: []string{".acme-form {", "var(--ticketd-"}, notWant: []string{".ticketd-"}},
+		{name: "custom file", prefix: config.DefaultEmbedClassPrefix, customPath: customCSS, want: []string{".ticketd-form { color: red;"}},
+		{
+			name:       "custom file with prefix",
+			prefix:     "acme-",
+			customPath: customCSS,
+			want:       []string{".acme-form { color: red; --ticketd-primary: #000; }", ".acme-note {"},
+			notWant:    []string{".ticketd-"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApp(t, func(cfg *config.Config) {
+				cfg.EmbedClassPrefix = tt.prefix
+				cfg.CustomCSSPath = tt.customPath
+			})
+			form := createTestForm(t, app, "example.com", store.FormTypeSupport)
+
+			for _, path := range []string{"/embed/form.css", "/embed/" + strconv.FormatInt(form.ID, 10) + ".css"} {
+				rec := serve(t, app, httptest.NewRequest(http.MethodGet, path, nil))
+				if rec.Code != http.StatusOK {
+					t.Fatalf("%s: status = %d, want %d", path, rec.Code, http.StatusOK)
+				}
+				body := rec.Body.String()
+				for _, want := range tt.want {
+					if !strings.Contains(body, want) {
+						t.Errorf("%s: stylesheet lacks %q", path, want)
+					}
+				}
+				for _, notWant := range tt.notWant {
+					if strings.Contains(body, notWant) {
+						t.Errorf("%s: stylesheet still contains %q", path, notWant)
+					}
+				}
+			}
+		})
+	}
+}
@@ -2,20 +2,29 @@ package web
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
+	"net/netip"
 	"net/url"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 
+	"ticketd/internal/config"
+	apperrors "ticketd/internal/errors"
 	"ticketd/internal/store"
+	"ticketd/internal/validator"
 )
 
 // handleSubmitOptions handles CORS preflight requests for form submissions.
 // It checks if the origin is allowed based on the client's allowed domain.
-// Returns 403 Forbidden if the origin is not allowed, or 204 No Content with CORS headers if allowed.
+// Returns 403 Forbidden if the origin is not allowed, or 204 No Content with CORS headers if allowed,
+// including Access-Control-Max-Age so browsers can skip the preflight for later submissions.
 func (a *App) handleSubmitOptions(w http.ResponseWriter, r *http.Request) {
 	if debugEnabled() {
 		log.Printf("preflight form_id=%s origin=%q referer=%q", chi.URLParam(r, "formID"), r.Header.Get("Origin"), r.Header.Get("Referer"))
@@ -33,25 +42,49 @@ func (a *App) handleSubmitOptions(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Vary", "Origin")
 	}
 	w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, "+widgetSourceHeader)
+	if maxAge := int(a.Cfg.CORSMaxAge.Seconds()); maxAge > 0 {
+		w.Header().Set("Access-Control-Max-Age", strconv.Itoa(maxAge))
+	}
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// widgetSourceHeader is sent by the embed script so its JSON posts can be told apart from direct API calls.
+const widgetSourceHeader = "X-TicketD-Source"
+
+// maxMultipartMemory is how much of a multipart submission is held in memory before spilling to disk.
+const maxMultipartMemory = 1 << 20
+
 // handleSubmit processes form submissions from embedded forms.
 // It validates the origin, parses the submission data (JSON or form-encoded),
 // validates the input, stores the submission, and returns a JSON response.
 // Supports both application/json and application/x-www-form-urlencoded content types.
+// Plain HTML form posts (see wantsHTML) are redirected to the success URL or the hosted
+// thank-you page instead, and shown the hosted form again if the submission is rejected.
 func (a *App) handleSubmit(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	outcome := outcomeRejected
+	var formType store.FormType
+	defer func() {
+		a.metrics.observeSubmit(string(formType), outcome, time.Since(start))
+	}()
+
 	if debugEnabled() {
 		log.Printf("submit start form_id=%s origin=%q referer=%q content_type=%q", chi.URLParam(r, "formID"), r.Header.Get("Origin"), r.Header.Get("Referer"), r.Header.Get("Content-Type"))
 	}
+	htmlForm := wantsHTML(r)
 	allowed, origin := a.checkAllowedOrigin(r)
+	// The hosted no-JS form posts from this server's own origin, which needs no CORS headers
+	if _, host := requestOrigin(r); !allowed && htmlForm && host == (&url.URL{Host: r.Host}).Hostname() {
+		allowed, origin = true, ""
+	}
 	if !allowed {
 		// Get more details for better error message
 		formID, _ := parseID(chi.URLParam(r, "formID"))
 		form, err := a.Store.GetForm(formID)
 		var allowedDomain string
 		if err == nil {
+			formType = form.Type
 			if client, err := a.Store.GetClient(form.ClientID); err == nil {
 				allowedDomain = client.AllowedDomain
 			}
@@ -66,6 +99,7 @@ func (a *App) handleSubmit(w http.ResponseWriter, r *http.Request) {
 		if allowedDomain != "" {
 			errorMsg = fmt.Sprintf("domain not allowed - configure client allowed domain to match your site (currently set to: %s)", allowedDomain)
 		}
+		outcome = outcomeForbidden
 		writeJSON(w, http.StatusForbidden, map[string]string{"error": errorMsg})
 		return
 	}
@@ -84,81 +118,269 @@ func (a *App) handleSubmit(w http.ResponseWriter, r *http.Request) {
 		writeJSON(w, http.StatusNotFound, map[string]string{"error": "form not found"})
 		return
 	}
+	formType = form.Type
+	if !form.Active {
+		a.submitFailed(w, r, form, store.SubmissionInput{}, http.StatusForbidden, errFormInactive)
+		return
+	}
+
+	// Bound the body before anything reads it, so oversized posts can't exhaust memory
+	if r.ContentLength > a.Cfg.MaxBodyBytes {
+		a.submitFailed(w, r, form, store.SubmissionInput{}, http.StatusRequestEntityTooLarge, errBodyTooLarge)
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, a.Cfg.MaxBodyBytes)
+
+	// Catch empty bodies before parsing, which would otherwise surface as "message is required"
+	if isEmptyBody(r) {
+		a.submitFailed(w, r, form, store.SubmissionInput{}, http.StatusBadRequest, "empty request body")
+		return
+	}
 
 	input := store.SubmissionInput{
 		IP:        r.RemoteAddr,
 		UserAgent: r.UserAgent(),
 	}
 
+	var startToken string // See formStartField
+	var consent string    // "true" once the submitter ticked the consent checkbox
 	contentType := r.Header.Get("Content-Type")
 	if strings.Contains(contentType, "application/json") {
+		input.Source = store.SourceAPIJSON
+		if r.Header.Get(widgetSourceHeader) == store.SourceWidget {
+			input.Source = store.SourceWidget
+		}
 		var payload struct {
 			Name     string `json:"name"`
 			Email    string `json:"email"`
+			Phone    string `json:"phone"`
 			Subject  string `json:"subject"`
 			Message  string `json:"message"`
 			Priority string `json:"priority"`
+			Category string `json:"category"`
+			Started  string `json:"_started"`
+			Consent  any    `json:"consent"` // true or "true"
 		}
 		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			if isBodyTooLarge(err) {
+				writeJSON(w, http.StatusRequestEntityTooLarge, map[string]string{"error": errBodyTooLarge})
+				return
+			}
 			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid json"})
 			return
 		}
 		input.Name = strings.TrimSpace(payload.Name)
 		input.Email = strings.TrimSpace(payload.Email)
+		input.Phone = strings.TrimSpace(payload.Phone)
 		input.Subject = strings.TrimSpace(payload.Subject)
 		input.Message = strings.TrimSpace(payload.Message)
 		input.Priority = strings.TrimSpace(payload.Priority)
+		input.Category = strings.TrimSpace(payload.Category)
+		startToken = payload.Started
+		consent = fmt.Sprint(payload.Consent)
 		if debugEnabled() {
 			log.Printf("submit json form_id=%d name=%q email=%q subject=%q priority=%q message_len=%d", form.ID, input.Name, input.Email, input.Subject, input.Priority, len(input.Message))
 		}
 	} else {
-		if err := r.ParseForm(); err != nil {
-			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid payload"})
+		input.Source = store.SourceFormPost
+		// ParseForm leaves multipart bodies unread, so those need their own parser
+		parse := r.ParseForm
+		if strings.HasPrefix(contentType, "multipart/form-data") {
+			parse = func() error { return r.ParseMultipartForm(maxMultipartMemory) }
+		}
+		if err := parse(); err != nil {
+			if isBodyTooLarge(err) {
+				a.submitFailed(w, r, form, input, http.StatusRequestEntityTooLarge, errBodyTooLarge)
+				return
+			}
+			a.submitFailed(w, r, form, input, http.StatusBadRequest, "invalid payload")
 			return
 		}
 		input.Name = strings.TrimSpace(formValue(r, "name"))
 		input.Email = strings.TrimSpace(formValue(r, "email"))
+		input.Phone = strings.TrimSpace(formValue(r, "phone"))
 		input.Subject = strings.TrimSpace(formValue(r, "subject"))
 		input.Message = strings.TrimSpace(formValue(r, "message"))
 		input.Priority = strings.TrimSpace(formValue(r, "priority"))
+		input.Category = strings.TrimSpace(formValue(r, "category"))
+		startToken = formValue(r, formStartField)
+		consent = formValue(r, "consent")
 		if debugEnabled() {
 			log.Printf("submit form form_id=%d name=%q email=%q subject=%q priority=%q message_len=%d content_type=%q", form.ID, input.Name, input.Email, input.Subject, input.Priority, len(input.Message), contentType)
 		}
 	}
 
-	if err := validateSubmission(form.Type, &input); err != nil {
-		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+	if err := validateSubmission(form, &input); err != nil {
+		a.submitFailed(w, r, form, input, http.StatusBadRequest, err.Error())
+		return
+	}
+	// The consent text is recorded in the language the form was shown in
+	if form.RequireConsent {
+		if consent != "true" {
+			a.submitFailed(w, r, form, input, http.StatusBadRequest, "consent is required")
+			return
+		}
+		lang := form.Language
+		if override := r.URL.Query().Get("lang"); override != "" {
+			lang = override
+		}
+		input.Consent = consentLabel(form, lookupEmbedLanguage(lang).Text)
+	}
+	if a.disposable.blocked(input.Email) {
+		a.submitFailed(w, r, form, input, http.StatusBadRequest, "disposable email not allowed")
+		return
+	}
+
+	// Blocklisted phrases get a generic error so spammers can't probe which phrase matched
+	if phrase := a.spam.match(input.Subject, input.Message); phrase != "" {
+		if debugEnabled() {
+			log.Printf("submit spam form_id=%d phrase=%q action=%s", form.ID, phrase, a.Cfg.SpamAction)
+		}
+		if a.Cfg.SpamAction != config.SpamActionFlag {
+			outcome = outcomeSpam
+			a.submitFailed(w, r, form, input, http.StatusBadRequest, "submission rejected")
+			return
+		}
+		input.Spam = true
+	}
+
+	// Submissions sent too soon after the form was shown, or without a valid start token,
+	// are answered like a success and dropped, as only bots are that fast
+	if a.Cfg.MinSubmitTime > 0 {
+		started, ok := a.formStartedAt(form.ID, startToken)
+		if !ok || time.Since(started) < a.Cfg.MinSubmitTime {
+			if debugEnabled() {
+				log.Printf("submit too fast form_id=%d valid_token=%t", form.ID, ok)
+			}
+			outcome = outcomeSpam
+			a.submitReceived(w, r, form, store.Submission{})
+			return
+		}
+	}
+
+	// Repeats are answered like a success, so bots don't learn they were caught
+	if a.dedup.duplicate(form.ID, input.Email, input.Message) {
+		if debugEnabled() {
+			log.Printf("submit duplicate form_id=%d email=%q", form.ID, input.Email)
+		}
+		outcome = outcomeDuplicate
+		a.submitReceived(w, r, form, store.Submission{})
 		return
 	}
 
-	if _, err := a.Store.CreateSubmission(form.ID, input); err != nil {
-		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to save"})
+	submission, err := a.Store.CreateSubmission(form.ID, input)
+	if err != nil {
+		a.dedup.forget(form.ID, input.Email, input.Message)
+		switch {
+		case apperrors.IsConflict(err):
+			a.submitFailed(w, r, form, input, http.StatusConflict, "this email address has already submitted this form")
+		case apperrors.IsInvalidInput(err):
+			a.submitFailed(w, r, form, input, http.StatusBadRequest, err.Error())
+		default:
+			outcome = outcomeError
+			a.submitFailed(w, r, form, input, http.StatusInternalServerError, "failed to save")
+		}
+		return
+	}
+	outcome = outcomeAccepted
+	if input.Spam {
+		outcome = outcomeSpam
+	} else {
+		a.feed.publish(submission)
+	}
+
+	a.submitReceived(w, r, form, submission)
+}
+
+// submitReceived answers an accepted submission with JSON, including its reference and
+// status page when it has them, or for plain HTML form posts with a redirect to the
+// success URL or the hosted thank-you page.
+func (a *App) submitReceived(w http.ResponseWriter, r *http.Request, form store.Form, submission store.Submission) {
+	if wantsHTML(r) {
+		target := form.SuccessURL
+		if target == "" {
+			target = fmt.Sprintf("/forms/%d/thanks%s", form.ID, langQuery(r))
+		}
+		http.Redirect(w, r, target, http.StatusSeeOther)
+		return
+	}
+	response := map[string]string{"status": "received"}
+	if submission.Reference != "" {
+		response["reference"] = submission.Reference
+	}
+	if submission.StatusToken != "" {
+		response["status_token"] = submission.StatusToken
+		response["status_url"] = fmt.Sprintf("%s/status/%s", a.publicBaseURL(r), submission.StatusToken)
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// errBodyTooLarge is the error for submissions over TICKETD_MAX_BODY_BYTES.
+const errBodyTooLarge = "request body too large"
+
+// isBodyTooLarge reports whether err comes from reading past the http.MaxBytesReader limit.
+func isBodyTooLarge(err error) bool {
+	var maxBytesErr *http.MaxBytesError
+	return errors.As(err, &maxBytesErr)
+}
+
+// errFormInactive is the error for submissions to a form that was disabled in the admin UI.
+const errFormInactive = "this form is not currently accepting submissions"
+
+// formStartField is the submission field carrying the token from handleFormStart,
+// named so it can't clash with form fields.
+const formStartField = "_started"
+
+// handleFormStart issues a signed token recording when a form was shown, for the
+// minimum time-to-submit check. The embed widget fetches one when it renders the form;
+// direct API integrations need to do the same while TICKETD_MIN_SUBMIT_TIME is set.
+func (a *App) handleFormStart(w http.ResponseWriter, r *http.Request) {
+	allowed, origin := a.checkAllowedOrigin(r)
+	if !allowed {
+		writeJSON(w, http.StatusForbidden, map[string]string{"error": "forbidden domain"})
 		return
 	}
+	if origin != "" {
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		w.Header().Set("Vary", "Origin")
+	}
+	formID, err := parseID(chi.URLParam(r, "formID"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid form"})
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, map[string]string{"token": a.newFormStartToken(formID)})
+}
 
-	writeJSON(w, http.StatusOK, map[string]string{"status": "received"})
+// submitFailed rejects a submission with a JSON error, or for plain HTML form posts by
+// showing the hosted form again with the error and the submitted values.
+func (a *App) submitFailed(w http.ResponseWriter, r *http.Request, form store.Form, input store.SubmissionInput, status int, msg string) {
+	if !wantsHTML(r) {
+		writeJSON(w, status, map[string]string{"error": msg})
+		return
+	}
+	a.renderHostedForm(w, r, status, form, input, msg)
 }
 
 // checkAllowedOrigin validates if the request origin is allowed to submit to this form.
 // It checks the Origin header first, then falls back to the Referer header.
 // Returns true and the origin if allowed, or false and empty string if not allowed.
 // The origin is matched against the client's allowed domain (exact match or subdomain).
+// With RequireHTTPSOrigins set, origins must also use https unless they are loopback hosts.
 func (a *App) checkAllowedOrigin(r *http.Request) (bool, string) {
 	origin := r.Header.Get("Origin")
-	referer := r.Header.Get("Referer")
-	var host string
-	if origin != "" {
-		if parsed, err := url.Parse(origin); err == nil {
-			host = parsed.Hostname()
-		}
-	} else if referer != "" {
-		if parsed, err := url.Parse(referer); err == nil {
-			host = parsed.Hostname()
-		}
-	}
+	scheme, host := requestOrigin(r)
 	if host == "" {
 		return false, ""
 	}
+	// Loopback origins may keep using http for local development
+	if a.Cfg.RequireHTTPSOrigins && scheme != "https" && !isLoopbackHost(host) {
+		if debugEnabled() {
+			log.Printf("origin blocked scheme=%q host=%q: https required", scheme, host)
+		}
+		return false, ""
+	}
 
 	formID, err := parseID(chi.URLParam(r, "formID"))
 	if err != nil {
@@ -172,18 +394,61 @@ func (a *App) checkAllowedOrigin(r *http.Request) (bool, string) {
 	if err != nil {
 		return false, ""
 	}
-	if !domainAllowed(host, client.AllowedDomain) {
+	if !domainAllowed(host, client.AllowedDomain) && !(a.Cfg.DevAllowPrivateOrigins && isPrivateHost(host)) {
 		return false, ""
 	}
 	return true, origin
 }
 
+// requestOrigin returns the lower-cased scheme and the host name of the request's Origin
+// header, or of its Referer if there is no Origin. Both are empty if neither is usable.
+func requestOrigin(r *http.Request) (scheme, host string) {
+	source := r.Header.Get("Origin")
+	if source == "" {
+		source = r.Header.Get("Referer")
+	}
+	if source == "" {
+		return "", ""
+	}
+	parsed, err := url.Parse(source)
+	if err != nil {
+		return "", ""
+	}
+	return strings.ToLower(parsed.Scheme), parsed.Hostname()
+}
+
+// isLoopbackHost reports whether host is localhost or a loopback IP address,
+// such as 127.0.0.1, ::1, or an IPv4-mapped loopback address.
+func isLoopbackHost(host string) bool {
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	addr, err := netip.ParseAddr(host)
+	return err == nil && addr.Unmap().IsLoopback()
+}
+
+// isPrivateHost reports whether host is localhost or a loopback, private, link-local,
+// or unspecified IP address (e.g. 127.0.0.1, ::1, 0.0.0.0, 192.168.1.20).
+// Public IPs and domain names other than localhost never match.
+func isPrivateHost(host string) bool {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsUnspecified()
+}
+
 // domainAllowed checks if a host matches or is a subdomain of the allowed domain.
 // For example, if allowed is "example.com", it will match "example.com" and "www.example.com".
 // Special handling for localhost: "localhost" will match "localhost:3000", "localhost:8080", etc.
 func domainAllowed(host, allowed string) bool {
-	host = strings.ToLower(strings.TrimSpace(host))
-	allowed = strings.ToLower(strings.TrimSpace(allowed))
+	// Compare internationalized domains in punycode, whichever form either side uses
+	host = validator.DomainToASCII(host)
+	allowed = validator.DomainToASCII(allowed)
 	if host == "" || allowed == "" {
 		return false
 	}
@@ -214,11 +479,12 @@ func domainAllowed(host, allowed string) bool {
 	return strings.HasSuffix(host, "."+allowed)
 }
 
-// validateSubmission validates form submission input based on the form type.
+// validateSubmission validates form submission input based on the form type and settings.
 // All forms require name, email, subject, and message.
-// Support forms additionally require priority.
+// Forms with a priority field default a missing priority to medium and accept only
+// low, medium, or high (in any case), stored lower-cased.
 // Basic email format validation is performed if email is provided.
-func validateSubmission(formType store.FormType, input *store.SubmissionInput) error {
+func validateSubmission(form store.Form, input *store.SubmissionInput) error {
 	// All form types require these fields
 	if input.Name == "" {
 		return fmt.Errorf("name is required")
@@ -234,16 +500,22 @@ func validateSubmission(formType store.FormType, input *store.SubmissionInput) e
 	}
 
 	// Additional validation based on form type
-	switch formType {
-	case store.FormTypeSupport:
-		if input.Priority == "" {
-			input.Priority = "medium"
-		}
-	case store.FormTypeContact:
-		// Contact forms already validated above
+	switch form.Type {
+	case store.FormTypeSupport, store.FormTypeContact:
+		// Both types share the fields validated above
 	default:
 		return fmt.Errorf("invalid form type")
 	}
+	// Forms without a priority field don't show one, so they don't check it either
+	if hasPriorityField(form) {
+		if input.Priority == "" {
+			input.Priority = validator.PriorityMedium
+		}
+		if validator.ValidatePriority(input.Priority) != nil {
+			return fmt.Errorf("invalid priority: must be low, medium, or high")
+		}
+		input.Priority = strings.ToLower(input.Priority)
+	}
 
 	if input.Email != "" && !strings.Contains(input.Email, "@") {
 		return fmt.Errorf("invalid email")
@@ -264,3 +536,9 @@ func formValue(r *http.Request, key string) string {
 	}
 	return ""
 }
+
+// hasPriorityField reports whether a form asks for a priority: support forms always do,
+// contact forms when the priority field is enabled.
+func hasPriorityField(form store.Form) bool {
+	return form.Type == store.FormTypeSupport || form.PriorityField
+}
@@ -0,0 +1,697 @@
+package web
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"ticketd/internal/config"
+	apperrors "ticketd/internal/errors"
+	"ticketd/internal/store"
+	"ticketd/internal/validator"
+)
+
+func TestSubmitEmptyBody(t *testing.T) {
+	app := newTestApp(t, nil)
+	form := createTestForm(t, app, "example.com", store.FormTypeSupport)
+
+	tests := []struct {
+		name          string
+		contentType   string
+		unknownLength bool // Sent chunked, so ContentLength is -1
+	}{
+		{name: "json", contentType: "application/json"},
+		{name: "form-urlencoded", contentType: "application/x-www-form-urlencoded"},
+		{name: "multipart", contentType: "multipart/form-data; boundary=xyz"},
+		{name: "no content type"},
+		{name: "json chunked", contentType: "application/json", unknownLength: true},
+		{name: "form-urlencoded chunked", contentType: "application/x-www-form-urlencoded", unknownLength: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := newSubmitRequest(form.ID, "https://example.com", tt.contentType, strings.NewReader(""))
+			if tt.unknownLength {
+				req.Body = io.NopCloser(strings.NewReader(""))
+				req.ContentLength = -1
+			}
+			rec := serve(t, app, req)
+			assertJSONError(t, rec, http.StatusBadRequest, "empty request body")
+		})
+	}
+
+	// A body that only looks empty by length must still be parsed
+	req := newSubmitRequest(form.ID, "https://example.com", "application/json", strings.NewReader(jsonSubmission))
+	req.ContentLength = -1
+	if rec := serve(t, app, req); rec.Code != http.StatusOK {
+		t.Errorf("chunked JSON submission: status = %d, want %d (body %q)", rec.Code, http.StatusOK, rec.Body.String())
+	}
+}
+
+// lastSubmission returns the submission with the highest ID. Submissions created within
+// the same second have no defined order by creation time.
+func lastSubmission(t *testing.T, app *App) store.Submission {
+	t.Helper()
+	submissions, _, err := app.Store.ListSubmissions(0, config.MaxPageSize)
+	if err != nil {
+		t.Fatalf("ListSubmissions: %v", err)
+	}
+	if len(submissions) == 0 {
+		t.Fatal("no submission stored")
+	}
+	last := submissions[0]
+	for _, submission := range submissions[1:] {
+		if submission.ID > last.ID {
+			last = submission
+		}
+	}
+	return last
+}
+
+// multipartSubmission returns a valid multipart submission body and its content type.
+func multipartSubmission(t *testing.T) (*bytes.Buffer, string) {
+	t.Helper()
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for _, field := range [][2]string{{"name", "Jane Doe"}, {"email", "jane@example.com"}, {"subject", "Help"}, {"message", "Something is broken."}} {
+		if err := writer.WriteField(field[0], field[1]); err != nil {
+			t.Fatalf("WriteField: %v", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("close multipart writer: %v", err)
+	}
+	return &body, writer.FormDataContentType()
+}
+
+// urlencodedSubmission is a valid URL-encoded submission body.
+const urlencodedSubmission = "name=Jane+Doe&email=jane%40example.com&subject=Help&message=Something+is+broken."
+
+func TestSubmitRecordsSource(t *testing.T) {
+	app := newTestApp(t, nil)
+	form := createTestForm(t, app, "example.com", store.FormTypeSupport)
+
+	multipartBody, multipartType := multipartSubmission(t)
+	tests := []struct {
+		name        string
+		contentType string
+		body        io.Reader
+		widget      bool
+		want        string
+	}{
+		{name: "embed widget", contentType: "application/json", body: strings.NewReader(jsonSubmission), widget: true, want: store.SourceWidget},
+		{name: "api json", contentType: "application/json", body: strings.NewReader(jsonSubmission), want: store.SourceAPIJSON},
+		{name: "json with charset", contentType: "application/json; charset=utf-8", body: strings.NewReader(jsonSubmission), want: store.SourceAPIJSON},
+		{name: "form-urlencoded", contentType: "application/x-www-form-urlencoded", body: strings.NewReader(urlencodedSubmission), want: store.SourceFormPost},
+		{name: "multipart", contentType: multipartType, body: multipartBody, want: store.SourceFormPost},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := newSubmitRequest(form.ID, "https://example.com", tt.contentType, tt.body)
+			if tt.widget {
+				req.Header.Set(widgetSourceHeader, store.SourceWidget)
+			}
+			rec := serve(t, app, req)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d (body %q)", rec.Code, http.StatusOK, rec.Body.String())
+			}
+			if got := lastSubmission(t, app).Source; got != tt.want {
+				t.Errorf("source = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	counts, err := app.Store.CountSubmissionsBySource()
+	if err != nil {
+		t.Fatalf("CountSubmissionsBySource: %v", err)
+	}
+	want := map[string]int{store.SourceWidget: 1, store.SourceAPIJSON: 2, store.SourceFormPost: 2}
+	if fmt.Sprint(counts) != fmt.Sprint(want) {
+		t.Errorf("CountSubmissionsBySource = %v, want %v", counts, want)
+	}
+}
+
+func TestGetClientByDomainMatchesDomainAllowed(t *testing.T) {
+	app := newTestApp(t, nil)
+	var clients []store.Client
+	for _, domain := range []string{"example.com", "shop.example.com", "café.com", "localhost:3000"} {
+		client, err := app.Store.CreateClient("Client "+domain, domain)
+		if err != nil {
+			t.Fatalf("CreateClient(%q): %v", domain, err)
+		}
+		clients = append(clients, client)
+	}
+
+	tests := []struct {
+		host string
+		want string // Allowed domain of the expected client, empty for none
+	}{
+		{host: "example.com", want: "example.com"},
+		{host: "WWW.Example.com", want: "example.com"},
+		{host: "shop.example.com", want: "shop.example.com"},
+		{host: "eu.shop.example.com", want: "shop.example.com"},
+		{host: "xn--caf-dma.com", want: "xn--caf-dma.com"},
+		{host: "café.com", want: "xn--caf-dma.com"},
+		{host: "menu.café.com", want: "xn--caf-dma.com"},
+		{host: "localhost", want: "localhost:3000"},
+		{host: "localhost:5173", want: "localhost:3000"},
+		{host: "127.0.0.1", want: "localhost:3000"},
+		{host: "notexample.com"},
+		{host: "example.com.evil.test"},
+		{host: "com"},
+		{host: "cafe.com"},
+		{host: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			var allowed []string
+			for _, client := range clients {
+				if domainAllowed(tt.host, client.AllowedDomain) {
+					allowed = append(allowed, client.AllowedDomain)
+				}
+			}
+
+			got, err := app.Store.GetClientByDomain(tt.host)
+			if tt.want == "" {
+				if !apperrors.IsNotFound(err) {
+					t.Errorf("GetClientByDomain = %q, %v, want not found", got.AllowedDomain, err)
+				}
+				if len(allowed) > 0 {
+					t.Errorf("domainAllowed accepts %q for clients %q", tt.host, allowed)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetClientByDomain: %v", err)
+			}
+			if got.AllowedDomain != tt.want {
+				t.Errorf("GetClientByDomain = %q, want %q", got.AllowedDomain, tt.want)
+			}
+			if !domainAllowed(tt.host, got.AllowedDomain) {
+				t.Errorf("domainAllowed(%q, %q) = false for the client GetClientByDomain returned", tt.host, got.AllowedDomain)
+			}
+		})
+	}
+}
+
+func TestDomainAllowed(t *testing.T) {
+	tests := []struct {
+		host    string
+		allowed string
+		want    bool
+	}{
+		{host: "example.com", allowed: "example.com", want: true},
+		{host: "www.example.com", allowed: "example.com", want: true},
+		{host: "Example.COM", allowed: "example.com", want: true},
+		{host: "notexample.com", allowed: "example.com"},
+		{host: "example.com", allowed: "www.example.com"},
+		{host: "localhost", allowed: "localhost:3000", want: true},
+		{host: "127.0.0.1", allowed: "localhost", want: true},
+		{host: "xn--caf-dma.com", allowed: "café.com", want: true},
+		{host: "café.com", allowed: "xn--caf-dma.com", want: true},
+		{host: "menu.xn--caf-dma.com", allowed: "café.com", want: true},
+		{host: "CAFÉ.com", allowed: "café.com", want: true},
+		{host: "cafe.com", allowed: "café.com"},
+		{host: "xn--caf-dma.com", allowed: "cafe.com"},
+		{host: "", allowed: "example.com"},
+		{host: "example.com", allowed: ""},
+	}
+	for _, tt := range tests {
+		if got := domainAllowed(tt.host, tt.allowed); got != tt.want {
+			t.Errorf("domainAllowed(%q, %q) = %t, want %t", tt.host, tt.allowed, got, tt.want)
+		}
+	}
+}
+
+func TestSubmitIDNOrigin(t *testing.T) {
+	app := newTestApp(t, nil)
+	form := createTestForm(t, app, "café.com", store.FormTypeSupport)
+
+	tests := []struct {
+		origin string
+		want   int
+	}{
+		{origin: "https://xn--caf-dma.com", want: http.StatusOK},
+		{origin: "https://www.xn--caf-dma.com", want: http.StatusOK},
+		{origin: "https://cafe.com", want: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		rec := serve(t, app, newSubmitRequest(form.ID, tt.origin, "application/json", strings.NewReader(jsonSubmission)))
+		if rec.Code != tt.want {
+			t.Errorf("origin %s: status = %d, want %d (body %q)", tt.origin, rec.Code, tt.want, rec.Body.String())
+		}
+	}
+}
+
+func TestIsLoopbackHost(t *testing.T) {
+	tests := []struct {
+		host string
+		want bool
+	}{
+		{host: "localhost", want: true},
+		{host: "LocalHost", want: true},
+		{host: "127.0.0.1", want: true},
+		{host: "127.0.0.2", want: true},
+		{host: "::1", want: true},
+		{host: "::ffff:127.0.0.1", want: true},
+		{host: "[::1]"},
+		{host: "localhost.example.com"},
+		{host: "192.168.1.20"},
+		{host: "example.com"},
+		{host: ""},
+	}
+	for _, tt := range tests {
+		if got := isLoopbackHost(tt.host); got != tt.want {
+			t.Errorf("isLoopbackHost(%q) = %t, want %t", tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestIsPrivateHost(t *testing.T) {
+	tests := []struct {
+		host string
+		want bool
+	}{
+		{host: "localhost", want: true},
+		{host: " LOCALHOST ", want: true},
+		{host: "127.0.0.1", want: true},
+		{host: "::1", want: true},
+		{host: "0.0.0.0", want: true},
+		{host: "10.1.2.3", want: true},
+		{host: "172.16.0.1", want: true},
+		{host: "192.168.1.20", want: true},
+		{host: "169.254.10.1", want: true},
+		{host: "fd00::1", want: true},
+		{host: "fe80::1", want: true},
+		{host: "::ffff:192.168.1.20", want: true},
+		{host: "172.32.0.1"},
+		{host: "203.0.113.9"},
+		{host: "2001:db8::1"},
+		{host: "example.com"},
+		{host: "localhost.example.com"},
+		{host: "192.168.1.20.example.com"},
+		{host: ""},
+	}
+	for _, tt := range tests {
+		if got := isPrivateHost(tt.host); got != tt.want {
+			t.Errorf("isPrivateHost(%q) = %t, want %t", tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestSubmitDevAllowPrivateOrigins(t *testing.T) {
+	tests := []struct {
+		name   string
+		origin string
+		dev    bool
+		want   int
+	}{
+		{name: "allowed domain", origin: "https://example.com", want: http.StatusOK},
+		{name: "LAN origin", origin: "http://192.168.1.20:5173", want: http.StatusForbidden},
+		{name: "LAN origin in dev", origin: "http://192.168.1.20:5173", dev: true, want: http.StatusOK},
+		{name: "loopback origin in dev", origin: "http://127.0.0.1:3000", dev: true, want: http.StatusOK},
+		{name: "IPv6 loopback origin in dev", origin: "http://[::1]:3000", dev: true, want: http.StatusOK},
+		{name: "public IP in dev", origin: "http://203.0.113.9", dev: true, want: http.StatusForbidden},
+		{name: "other domain in dev", origin: "https://evil.example.net", dev: true, want: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApp(t, func(cfg *config.Config) { cfg.DevAllowPrivateOrigins = tt.dev })
+			form := createTestForm(t, app, "example.com", store.FormTypeSupport)
+
+			rec := serve(t, app, newSubmitRequest(form.ID, tt.origin, "application/json", strings.NewReader(jsonSubmission)))
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d (body %q)", rec.Code, tt.want, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestSubmitRequireHTTPSOrigins(t *testing.T) {
+	tests := []struct {
+		name         string
+		domain       string
+		origin       string
+		requireHTTPS bool
+		want         int
+	}{
+		{name: "http rejected", domain: "example.com", origin: "http://example.com", requireHTTPS: true, want: http.StatusForbidden},
+		{name: "https allowed", domain: "example.com", origin: "https://example.com", requireHTTPS: true, want: http.StatusOK},
+		{name: "http subdomain rejected", domain: "example.com", origin: "http://www.example.com", requireHTTPS: true, want: http.StatusForbidden},
+		{name: "http allowed when not required", domain: "example.com", origin: "http://example.com", want: http.StatusOK},
+		{name: "http localhost", domain: "localhost", origin: "http://localhost:3000", requireHTTPS: true, want: http.StatusOK},
+		{name: "http 127.0.0.1", domain: "localhost", origin: "http://127.0.0.1:3000", requireHTTPS: true, want: http.StatusOK},
+		{name: "http IPv6 loopback", domain: "localhost", origin: "http://[::1]:3000", requireHTTPS: true, want: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApp(t, func(cfg *config.Config) {
+				cfg.RequireHTTPSOrigins = tt.requireHTTPS
+				// domainAllowed doesn't treat ::1 as localhost, so let private origins through
+				cfg.DevAllowPrivateOrigins = true
+			})
+			form := createTestForm(t, app, tt.domain, store.FormTypeSupport)
+
+			rec := serve(t, app, newSubmitRequest(form.ID, tt.origin, "application/json", strings.NewReader(jsonSubmission)))
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d (body %q)", rec.Code, tt.want, rec.Body.String())
+			}
+			if tt.want == http.StatusForbidden {
+				if _, total, _ := app.Store.ListSubmissions(0, 10); total != 0 {
+					t.Errorf("%d submissions stored, want 0", total)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateSubmissionPriority(t *testing.T) {
+	support := store.Form{Type: store.FormTypeSupport}
+	contact := store.Form{Type: store.FormTypeContact}
+	contactWithPriority := store.Form{Type: store.FormTypeContact, PriorityField: true}
+
+	tests := []struct {
+		name     string
+		form     store.Form
+		priority string
+		want     string
+		wantErr  bool
+	}{
+		{name: "support default", form: support, want: validator.PriorityMedium},
+		{name: "support low", form: support, priority: "low", want: validator.PriorityLow},
+		{name: "support upper case", form: support, priority: "HIGH", want: validator.PriorityHigh},
+		{name: "support junk", form: support, priority: "URGENT!!!", wantErr: true},
+		{name: "contact ignores priority", form: contact, priority: "URGENT!!!", want: "URGENT!!!"},
+		{name: "contact without priority", form: contact},
+		{name: "contact with priority field default", form: contactWithPriority, want: validator.PriorityMedium},
+		{name: "contact with priority field", form: contactWithPriority, priority: "High", want: validator.PriorityHigh},
+		{name: "contact with priority field junk", form: contactWithPriority, priority: "asap", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := store.SubmissionInput{Name: "Jane Doe", Email: "jane@example.com", Subject: "Help", Message: "Hi", Priority: tt.priority}
+			err := validateSubmission(tt.form, &input)
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "invalid priority") {
+					t.Errorf("error = %v, want invalid priority", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("validateSubmission: %v", err)
+			}
+			if input.Priority != tt.want {
+				t.Errorf("priority = %q, want %q", input.Priority, tt.want)
+			}
+		})
+	}
+}
+
+func TestSubmitInvalidPriority(t *testing.T) {
+	app := newTestApp(t, nil)
+	form := createTestForm(t, app, "example.com", store.FormTypeSupport)
+
+	body := `{"name":"Jane Doe","email":"jane@example.com","subject":"Help","message":"Hi","priority":"URGENT!!!"}`
+	rec := serve(t, app, newSubmitRequest(form.ID, "https://example.com", "application/json", strings.NewReader(body)))
+	assertJSONError(t, rec, http.StatusBadRequest, "invalid priority: must be low, medium, or high")
+
+	body = `{"name":"Jane Doe","email":"jane@example.com","subject":"Help","message":"Hi","priority":"High"}`
+	rec = serve(t, app, newSubmitRequest(form.ID, "https://example.com", "application/json", strings.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d (body %q)", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	if priority := lastSubmission(t, app).Priority; priority != validator.PriorityHigh {
+		t.Errorf("stored priority = %q, want %q", priority, validator.PriorityHigh)
+	}
+}
+
+func TestSubmitMinSubmitTime(t *testing.T) {
+	app := newTestApp(t, func(cfg *config.Config) {
+		cfg.SessionSecret = strings.Repeat("s", 32)
+		cfg.MinSubmitTime = 2 * time.Second
+	})
+	form := createTestForm(t, app, "example.com", store.FormTypeSupport)
+
+	tests := []struct {
+		name   string
+		token  string
+		stored bool
+	}{
+		{name: "normal", token: formStartTokenAt(app, form.ID, time.Now().Add(-3*time.Second)), stored: true},
+		{name: "just over the threshold", token: formStartTokenAt(app, form.ID, time.Now().Add(-2100*time.Millisecond)), stored: true},
+		{name: "fast", token: app.newFormStartToken(form.ID)},
+		{name: "just under the threshold", token: formStartTokenAt(app, form.ID, time.Now().Add(-1500*time.Millisecond))},
+		{name: "missing token"},
+		{name: "token for another form", token: formStartTokenAt(app, form.ID+1, time.Now().Add(-3*time.Second))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, before, _ := app.Store.ListSubmissions(0, 1)
+			body := fmt.Sprintf(`{"name":"Jane Doe","email":"jane@example.com","subject":"Help","message":%q,"_started":%q}`, tt.name, tt.token)
+			rec := serve(t, app, newSubmitRequest(form.ID, "https://example.com", "application/json", strings.NewReader(body)))
+			// Too-fast submissions look accepted, so bots don't learn they were caught
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d (body %q)", rec.Code, http.StatusOK, rec.Body.String())
+			}
+			_, after, _ := app.Store.ListSubmissions(0, 1)
+			if stored := after > before; stored != tt.stored {
+				t.Errorf("stored = %t, want %t", stored, tt.stored)
+			}
+		})
+	}
+}
+
+func TestSubmitInactiveForm(t *testing.T) {
+	app := newTestApp(t, nil)
+	form := createTestForm(t, app, "example.com", store.FormTypeSupport)
+	if !form.Active {
+		t.Fatalf("new form is inactive")
+	}
+	if err := app.Store.SetFormActive(form.ID, false); err != nil {
+		t.Fatalf("SetFormActive: %v", err)
+	}
+
+	rec := serve(t, app, newSubmitRequest(form.ID, "https://example.com", "application/json", strings.NewReader(jsonSubmission)))
+	assertJSONError(t, rec, http.StatusForbidden, errFormInactive)
+	if _, total, _ := app.Store.ListSubmissions(0, 10); total != 0 {
+		t.Errorf("%d submissions stored, want 0", total)
+	}
+
+	// The embed still loads, but renders a notice instead of the form
+	rec = serve(t, app, httptest.NewRequest(http.MethodGet, embedPath(form.ID), nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"active":false`) {
+		t.Errorf("embed of inactive form: status = %d, want 200 with active false", rec.Code)
+	}
+
+	if err := app.Store.SetFormActive(form.ID, true); err != nil {
+		t.Fatalf("SetFormActive: %v", err)
+	}
+	rec = serve(t, app, newSubmitRequest(form.ID, "https://example.com", "application/json", strings.NewReader(jsonSubmission)))
+	if rec.Code != http.StatusOK {
+		t.Errorf("reactivated form: status = %d, want %d (body %q)", rec.Code, http.StatusOK, rec.Body.String())
+	}
+}
+
+func TestSubmitConsent(t *testing.T) {
+	app := newTestApp(t, nil)
+	client, err := app.Store.CreateClient("Client example.com", "example.com")
+	if err != nil {
+		t.Fatalf("CreateClient: %v", err)
+	}
+	const label = "I agree to the processing of my data"
+	form, err := app.Store.CreateForm(client.ID, store.FormInput{Name: "Support", Type: store.FormTypeSupport, RequireConsent: true, ConsentLabel: label, PrivacyURL: "https://example.com/privacy"})
+	if err != nil {
+		t.Fatalf("CreateForm: %v", err)
+	}
+	plain, err := app.Store.CreateForm(client.ID, store.FormInput{Name: "Contact", Type: store.FormTypeContact})
+	if err != nil {
+		t.Fatalf("CreateForm: %v", err)
+	}
+
+	const fields = `"name":"Jane Doe","email":"jane@example.com","subject":"Help","message":"Hi"`
+	tests := []struct {
+		name        string
+		form        store.Form
+		contentType string
+		body        string
+		wantConsent string // Recorded consent, or empty if the submission is rejected
+	}{
+		{name: "json true", form: form, contentType: "application/json", body: `{` + fields + `,"consent":true}`, wantConsent: label},
+		{name: "json string", form: form, contentType: "application/json", body: `{` + fields + `,"consent":"true"}`, wantConsent: label},
+		{name: "form post", form: form, contentType: "application/x-www-form-urlencoded", body: urlencodedSubmission + "&consent=true", wantConsent: label},
+		{name: "json false", form: form, contentType: "application/json", body: `{` + fields + `,"consent":false}`},
+		{name: "json missing", form: form, contentType: "application/json", body: `{` + fields + `}`},
+		{name: "form post missing", form: form, contentType: "application/x-www-form-urlencoded", body: urlencodedSubmission},
+		{name: "form post other value", form: form, contentType: "application/x-www-form-urlencoded", body: urlencodedSubmission + "&consent=yes"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, before, _ := app.Store.ListSubmissions(0, 1)
+			rec := serve(t, app, newSubmitRequest(tt.form.ID, "https://example.com", tt.contentType, strings.NewReader(tt.body)))
+			if tt.wantConsent == "" {
+				assertJSONError(t, rec, http.StatusBadRequest, "consent is required")
+				if _, after, _ := app.Store.ListSubmissions(0, 1); after != before {
+					t.Errorf("rejected submission was stored")
+				}
+				return
+			}
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d (body %q)", rec.Code, http.StatusOK, rec.Body.String())
+			}
+			if got := lastSubmission(t, app).Consent; got != tt.wantConsent {
+				t.Errorf("recorded consent = %q, want %q", got, tt.wantConsent)
+			}
+		})
+	}
+
+	// Forms that don't ask for consent record none, whatever is sent
+	rec := serve(t, app, newSubmitRequest(plain.ID, "https://example.com", "application/json", strings.NewReader(`{`+fields+`,"consent":true}`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("form without consent: status = %d, want %d (body %q)", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	if got := lastSubmission(t, app).Consent; got != "" {
+		t.Errorf("form without consent recorded %q", got)
+	}
+}
+
+func TestSubmitOversizedBody(t *testing.T) {
+	app := newTestApp(t, func(cfg *config.Config) { cfg.MaxBodyBytes = config.MinMaxBodyBytes })
+	form := createTestForm(t, app, "example.com", store.FormTypeSupport)
+	padding := strings.Repeat("x", int(config.MinMaxBodyBytes))
+
+	var multipartBody bytes.Buffer
+	writer := multipart.NewWriter(&multipartBody)
+	if err := writer.WriteField("message", padding); err != nil {
+		t.Fatalf("WriteField: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("close multipart writer: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		contentType   string
+		body          string
+		unknownLength bool // Hide the length, as with chunked uploads, so only the body reader can stop it
+	}{
+		{name: "json", contentType: "application/json", body: `{"name":"Jane Doe","message":"` + padding + `"}`},
+		{name: "json without length", contentType: "application/json", body: `{"name":"Jane Doe","message":"` + padding + `"}`, unknownLength: true},
+		{name: "form post without length", contentType: "application/x-www-form-urlencoded", body: urlencodedSubmission + "&padding=" + padding, unknownLength: true},
+		{name: "multipart without length", contentType: writer.FormDataContentType(), body: multipartBody.String(), unknownLength: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body io.Reader = strings.NewReader(tt.body)
+			if tt.unknownLength {
+				body = io.MultiReader(body)
+			}
+			req := newSubmitRequest(form.ID, "https://example.com", tt.contentType, body)
+			if tt.unknownLength && req.ContentLength > 0 {
+				t.Fatalf("ContentLength = %d, want unknown", req.ContentLength)
+			}
+			rec := serve(t, app, req)
+			assertJSONError(t, rec, http.StatusRequestEntityTooLarge, errBodyTooLarge)
+		})
+	}
+	if _, total, _ := app.Store.ListSubmissions(0, 10); total != 0 {
+		t.Errorf("%d submissions stored, want 0", total)
+	}
+
+	rec := serve(t, app, newSubmitRequest(form.ID, "https://example.com", "application/json", strings.NewReader(jsonSubmission)))
+	if rec.Code != http.StatusOK {
+		t.Errorf("small body: status = %d, want %d (body %q)", rec.Code, http.StatusOK, rec.Body.String())
+	}
+}
+
+func TestSubmitPreflight(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxAge     time.Duration
+		origin     string
+		wantStatus int
+		wantMaxAge string
+	}{
+		{name: "allowed", maxAge: 10 * time.Minute, origin: "https://www.example.com", wantStatus: http.StatusNoContent, wantMaxAge: "600"},
+		{name: "custom max age", maxAge: time.Hour, origin: "https://example.com", wantStatus: http.StatusNoContent, wantMaxAge: "3600"},
+		{name: "max age off", origin: "https://example.com", wantStatus: http.StatusNoContent},
+		{name: "forbidden origin", maxAge: 10 * time.Minute, origin: "https://evil.example", wantStatus: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApp(t, func(cfg *config.Config) { cfg.CORSMaxAge = tt.maxAge })
+			form := createTestForm(t, app, "example.com", store.FormTypeSupport)
+
+			req := httptest.NewRequest(http.MethodOptions, fmt.Sprintf("/api/forms/%d/submit", form.ID), nil)
+			req.Header.Set("Origin", tt.origin)
+			req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+			rec := serve(t, app, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if got := rec.Header().Get("Access-Control-Max-Age"); got != tt.wantMaxAge {
+				t.Errorf("Access-Control-Max-Age = %q, want %q", got, tt.wantMaxAge)
+			}
+			if tt.wantStatus != http.StatusNoContent {
+				return
+			}
+			if got := rec.Header().Get("Access-Control-Allow-Origin"); got != tt.origin {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, tt.origin)
+			}
+			if got := rec.Header().Get("Vary"); got != "Origin" {
+				t.Errorf("Vary = %q, want %q", got, "Origin")
+			}
+		})
+	}
+}
+
+func TestContactFormPriorityField(t *testing.T) {
+	app := newTestApp(t, nil)
+	client, err := app.Store.CreateClient("Example", "example.com")
+	if err != nil {
+		t.Fatalf("CreateClient: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		input        store.FormInput
+		wantField    bool
+		wantPriority string
+	}{
+		{name: "contact", input: store.FormInput{Name: "Contact", Type: store.FormTypeContact}},
+		{name: "contact with priority field", input: store.FormInput{Name: "Contact with priority", Type: store.FormTypeContact, PriorityField: true}, wantField: true, wantPriority: validator.PriorityMedium},
+		{name: "support", input: store.FormInput{Name: "Support", Type: store.FormTypeSupport}, wantField: true, wantPriority: validator.PriorityMedium},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			form, err := app.Store.CreateForm(client.ID, tt.input)
+			if err != nil {
+				t.Fatalf("CreateForm: %v", err)
+			}
+			if form, err = app.Store.GetForm(form.ID); err != nil || form.PriorityField != tt.input.PriorityField {
+				t.Fatalf("GetForm = %+v, %v, want priority field %t", form, err, tt.input.PriorityField)
+			}
+
+			js, err := buildEmbedJS(form, client, "https://ticketd.example", "en", config.DefaultEmbedClassPrefix, false)
+			if err != nil {
+				t.Fatalf("buildEmbedJS: %v", err)
+			}
+			if got := strings.Contains(js, `"name":"priority"`); got != tt.wantField {
+				t.Errorf("embed has a priority field: %t, want %t", got, tt.wantField)
+			}
+
+			rec := serve(t, app, newSubmitRequest(form.ID, "https://example.com", "application/json", strings.NewReader(jsonSubmission)))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d (body %q)", rec.Code, http.StatusOK, rec.Body.String())
+			}
+			if priority := lastSubmission(t, app).Priority; priority != tt.wantPriority {
+				t.Errorf("stored priority = %q, want %q", priority, tt.wantPriority)
+			}
+		})
+	}
+}
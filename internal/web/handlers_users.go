@@ -0,0 +1,121 @@
+package web
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"golang.org/x/crypto/bcrypt"
+
+	apperrors "ticketd/internal/errors"
+	"ticketd/internal/store"
+	"ticketd/internal/validator"
+)
+
+// handleAdminUsers displays all admin users and a form to add new ones.
+func (a *App) handleAdminUsers(w http.ResponseWriter, r *http.Request) {
+	users, err := a.Store.ListAdminUsers()
+	if err != nil {
+		http.Error(w, "failed to load users", http.StatusInternalServerError)
+		return
+	}
+
+	views := make([]adminUserView, 0, len(users))
+	for _, u := range users {
+		views = append(views, adminUserView{
+			AdminUser: u,
+			CreatedAt: formatTime(u.CreatedAt),
+			IsCurrent: u.Username == currentUser(r),
+		})
+	}
+
+	data := usersPage{
+		Active: "users",
+		Users:  views,
+	}
+	a.renderTemplate(w, r, "users.html", data)
+}
+
+// handleAdminCreateUser creates a new admin user with a bcrypt-hashed password.
+// Redirects back to the users list after successful creation.
+func (a *App) handleAdminCreateUser(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+	username := strings.TrimSpace(r.FormValue("username"))
+	password := r.FormValue("password")
+	if err := validator.ValidateUsername(username); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := validator.ValidatePassword(password); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		http.Error(w, "failed to create user", http.StatusInternalServerError)
+		return
+	}
+	if _, err := a.Store.CreateAdminUser(username, string(hash)); err != nil {
+		if apperrors.IsConflict(err) {
+			http.Error(w, "username already exists", http.StatusConflict)
+			return
+		}
+		http.Error(w, "failed to create user", http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, "/admin/users", http.StatusFound)
+}
+
+// handleAdminDeleteUser deletes an admin user.
+// The currently signed-in user and the last remaining user cannot be deleted,
+// so the dashboard can never be locked out.
+func (a *App) handleAdminDeleteUser(w http.ResponseWriter, r *http.Request) {
+	userID, err := parseID(chi.URLParam(r, "userID"))
+	if err != nil {
+		http.Error(w, "invalid user", http.StatusBadRequest)
+		return
+	}
+
+	users, err := a.Store.ListAdminUsers()
+	if err != nil {
+		http.Error(w, "failed to load users", http.StatusInternalServerError)
+		return
+	}
+	if len(users) <= 1 {
+		http.Error(w, "cannot delete the last admin user", http.StatusBadRequest)
+		return
+	}
+	for _, u := range users {
+		if u.ID == userID && u.Username == currentUser(r) {
+			http.Error(w, "cannot delete the signed-in user", http.StatusBadRequest)
+			return
+		}
+	}
+
+	if err := a.Store.DeleteAdminUser(userID); err != nil {
+		if apperrors.IsNotFound(err) {
+			http.Error(w, "user not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "failed to delete user", http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, "/admin/users", http.StatusFound)
+}
+
+// adminUserView is a view model for rendering admin user information.
+type adminUserView struct {
+	store.AdminUser
+	CreatedAt string
+	IsCurrent bool
+}
+
+// usersPage is the data structure for the admin users page.
+type usersPage struct {
+	Active string
+	Users  []adminUserView
+}
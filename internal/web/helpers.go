@@ -2,25 +2,30 @@ package web
 
 import (
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"strconv"
 	"strings"
 	"time"
+
+	"ticketd/internal/validator"
 )
 
 // publicBaseURL returns the base URL for public-facing endpoints.
 // If TICKETD_PUBLIC_BASE_URL is configured, it uses that.
 // Otherwise, it infers the URL from the request (scheme + host).
+// When TicketD serves TLS itself the scheme is always https, since there is
+// no proxy whose X-Forwarded-Proto header could be trusted. Otherwise the
+// header is only present here when it came from a trusted proxy, see realIP.
 func (a *App) publicBaseURL(r *http.Request) string {
 	if a.Cfg.PublicBaseURL != "" {
 		return strings.TrimRight(a.Cfg.PublicBaseURL, "/")
 	}
 	scheme := "http"
-	if r.TLS != nil {
+	if a.Cfg.TLSEnabled() || r.TLS != nil {
 		scheme = "https"
-	}
-	if forwarded := r.Header.Get("X-Forwarded-Proto"); forwarded != "" {
+	} else if forwarded := r.Header.Get("X-Forwarded-Proto"); forwarded != "" {
 		scheme = forwarded
 	}
 	return fmt.Sprintf("%s://%s", scheme, r.Host)
@@ -71,3 +76,39 @@ func formatTime(value time.Time) string {
 	}
 	return value.Format("2006-01-02 15:04")
 }
+
+// displayIP returns the IP address to show in the admin UI. With TICKETD_MASK_IPS
+// only the subnet is shown, as kept by validator.AnonymizeIP: /24 for IPv4 and /48 for
+// IPv6, also for legacy "ip:port" values. Values that aren't IP addresses are hidden
+// entirely when masking. The stored address is never changed.
+func (a *App) displayIP(ip string) string {
+	if !a.Cfg.MaskIPs || ip == "" {
+		return ip
+	}
+	network, ok := validator.IPNetwork(ip)
+	if !ok {
+		return "hidden"
+	}
+	return network.String()
+}
+
+// isEmptyBody reports whether the request has no body at all.
+// Chunked requests don't declare a length, so one byte is peeked and put back.
+func isEmptyBody(r *http.Request) bool {
+	if r.Body == nil || r.Body == http.NoBody || r.ContentLength == 0 {
+		return true
+	}
+	if r.ContentLength > 0 {
+		return false
+	}
+	var first [1]byte
+	n, _ := io.ReadFull(r.Body, first[:])
+	if n == 0 {
+		return true
+	}
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(strings.NewReader(string(first[:n])), r.Body), r.Body}
+	return false
+}
@@ -0,0 +1,33 @@
+package web
+
+import (
+	"testing"
+
+	"ticketd/internal/config"
+)
+
+func TestDisplayIP(t *testing.T) {
+	tests := []struct {
+		ip         string
+		wantMasked string
+	}{
+		{ip: "203.0.113.42", wantMasked: "203.0.113.0/24"},
+		{ip: "203.0.113.42:51234", wantMasked: "203.0.113.0/24"}, // Legacy rows stored with a port
+		{ip: " 203.0.113.42 ", wantMasked: "203.0.113.0/24"},
+		{ip: "::ffff:203.0.113.42", wantMasked: "203.0.113.0/24"},
+		{ip: "2001:db8:1:2::42", wantMasked: "2001:db8:1::/48"},
+		{ip: "[2001:db8:1:2::42]:51234", wantMasked: "2001:db8:1::/48"},
+		{ip: "not-an-ip", wantMasked: "hidden"},
+		{ip: "", wantMasked: ""},
+	}
+	full := newTestApp(t, nil)
+	masked := newTestApp(t, func(cfg *config.Config) { cfg.MaskIPs = true })
+	for _, tt := range tests {
+		if got := full.displayIP(tt.ip); got != tt.ip {
+			t.Errorf("displayIP(%q) without masking = %q, want it unchanged", tt.ip, got)
+		}
+		if got := masked.displayIP(tt.ip); got != tt.wantMasked {
+			t.Errorf("displayIP(%q) with masking = %q, want %q", tt.ip, got, tt.wantMasked)
+		}
+	}
+}
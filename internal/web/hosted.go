@@ -0,0 +1,314 @@
+package web
+
+import (
+	"fmt"
+	"html/template"
+	"log"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	apperrors "ticketd/internal/errors"
+	"ticketd/internal/store"
+	"ticketd/internal/validator"
+)
+
+// hostedFormTemplate renders a form as plain HTML for browsers without JavaScript.
+// It uses the embed stylesheet and markup, so it looks like the widget.
+var hostedFormTemplate = template.Must(template.New("hosted").Parse(`<!DOCTYPE html>
+<html lang="{{.Lang}}">
+<head>
+  <meta charset="utf-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <title>{{.Title}}</title>
+  <link rel="stylesheet" href="/embed/{{.FormID}}.css">
+  <style>body { margin: 0; padding: 1rem; }</style>
+</head>
+<body>
+  <div class="{{.Prefix}}embed"{{if .Style}} style="{{.Style}}"{{end}}>
+    {{if .Done}}
+    <div class="{{.Prefix}}form">
+      <h3>{{.Title}}</h3>
+      <div class="{{.Prefix}}status {{.Prefix}}success" role="status">{{.Text.Success}}</div>
+    </div>
+    {{else if .Inactive}}
+    <div class="{{.Prefix}}form">
+      <h3>{{.Title}}</h3>
+      <div class="{{.Prefix}}status" role="status">{{.Text.Inactive}}</div>
+    </div>
+    {{else}}
+    <form class="{{.Prefix}}form" method="post" action="{{.Action}}">
+      <h3>{{.Title}}</h3>
+      {{range .Fields}}
+      <label for="ticketd-{{.name}}">{{.label}}</label>
+      {{if eq .type "textarea"}}
+      <textarea id="ticketd-{{.name}}" name="{{.name}}" rows="4"{{with .placeholder}} placeholder="{{.}}"{{end}}{{if not .optional}} required{{end}}>{{index $.Values .name}}</textarea>
+      {{else if eq .type "select"}}
+      {{$value := index $.Values .name}}
+      <select id="ticketd-{{.name}}" name="{{.name}}"{{if not .optional}} required{{end}}>
+        {{range .options}}
+        <option value="{{.value}}"{{if eq .value $value}} selected{{end}}>{{.label}}</option>
+        {{end}}
+      </select>
+      {{else}}
+      <input id="ticketd-{{.name}}" type="{{.type}}" name="{{.name}}" value="{{index $.Values .name}}"{{with .placeholder}} placeholder="{{.}}"{{end}}{{if not .optional}} required{{end}}>
+      {{end}}
+      {{end}}
+      {{if .Consent}}
+      <label class="{{.Prefix}}consent"><input type="checkbox" name="consent" value="true" required> {{.Consent}}{{with .PrivacyURL}} (<a href="{{.}}" target="_blank" rel="noopener">{{$.Text.PrivacyPolicy}}</a>){{end}}</label>
+      {{end}}
+      {{with .StartToken}}<input type="hidden" name="_started" value="{{.}}">{{end}}
+      <button type="submit">{{.Text.Send}}</button>
+      {{if .Error}}<div class="{{.Prefix}}status {{.Prefix}}error" role="alert">{{.Text.Error}} ({{.Error}})</div>{{end}}
+    </form>
+    {{end}}
+  </div>
+</body>
+</html>
+`))
+
+// hostedFormPage is the data for hostedFormTemplate.
+type hostedFormPage struct {
+	FormID     int64
+	Prefix     string // Start of the class names, see config.Config.EmbedClassPrefix
+	Lang       string
+	Title      string
+	Style      template.CSS // Theme custom properties, validated when the form is saved
+	Text       embedText
+	Fields     []map[string]any
+	Values     map[string]string // Submitted values, to refill the form after an error
+	Action     string
+	Error      string
+	StartToken string // Signed time the form was shown, set while the minimum time-to-submit check is on
+	Consent    string // Label of the required consent checkbox, empty if the form doesn't ask
+	PrivacyURL string
+	Done       bool // Show the thank-you message instead of the form
+	Inactive   bool // Show a notice instead of the form, which is disabled
+}
+
+// handleHostedForm serves a form as a plain HTML page that works without JavaScript.
+// Like the embed script, it needs a signed URL when embed signing is enabled.
+func (a *App) handleHostedForm(w http.ResponseWriter, r *http.Request) {
+	formID, err := parseID(chi.URLParam(r, "formID"))
+	if err != nil {
+		http.Error(w, "invalid form", http.StatusBadRequest)
+		return
+	}
+	if a.Cfg.SignEmbeds {
+		query := r.URL.Query()
+		if !a.validEmbedSignature(formID, query.Get("exp"), query.Get("sig")) {
+			http.Error(w, "invalid or expired form link", http.StatusForbidden)
+			return
+		}
+	}
+	form, err := a.Store.GetForm(formID)
+	if err != nil {
+		http.Error(w, "form not found", http.StatusNotFound)
+		return
+	}
+	a.renderHostedForm(w, r, http.StatusOK, form, store.SubmissionInput{}, "")
+}
+
+// handleHostedFormThanks shows the thank-you message after a plain HTML submission.
+func (a *App) handleHostedFormThanks(w http.ResponseWriter, r *http.Request) {
+	formID, err := parseID(chi.URLParam(r, "formID"))
+	if err != nil {
+		http.Error(w, "invalid form", http.StatusBadRequest)
+		return
+	}
+	form, err := a.Store.GetForm(formID)
+	if err != nil {
+		http.Error(w, "form not found", http.StatusNotFound)
+		return
+	}
+	page := a.hostedFormPage(r, form)
+	page.Done = true
+	a.writeHostedForm(w, http.StatusOK, page)
+}
+
+// renderHostedForm renders the hosted form with the given submitted values and error message.
+func (a *App) renderHostedForm(w http.ResponseWriter, r *http.Request, status int, form store.Form, input store.SubmissionInput, errMsg string) {
+	page := a.hostedFormPage(r, form)
+	page.Error = errMsg
+	page.Values = map[string]string{
+		"name":     input.Name,
+		"email":    input.Email,
+		"phone":    input.Phone,
+		"subject":  input.Subject,
+		"message":  input.Message,
+		"priority": input.Priority,
+		"category": input.Category,
+	}
+	a.writeHostedForm(w, status, page)
+}
+
+// hostedFormPage builds the page data for a form. A lang query parameter overrides
+// the form's language, as for the embed script, and is carried over to the form action.
+func (a *App) hostedFormPage(r *http.Request, form store.Form) hostedFormPage {
+	lang := form.Language
+	if override := r.URL.Query().Get("lang"); override != "" {
+		lang = override
+	}
+	language := lookupEmbedLanguage(lang)
+
+	title := form.Name
+	if client, err := a.Store.GetClient(form.ClientID); err == nil {
+		title = fmt.Sprintf("%s - %s", client.Name, form.Name)
+	}
+
+	theme := embedTheme(form)
+	names := make([]string, 0, len(theme))
+	for name := range theme {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	declarations := make([]string, 0, len(names))
+	for _, name := range names {
+		declarations = append(declarations, name+": "+theme[name])
+	}
+
+	page := hostedFormPage{
+		FormID:   form.ID,
+		Prefix:   a.Cfg.EmbedClassPrefix,
+		Lang:     language.Code,
+		Title:    title,
+		Style:    template.CSS(strings.Join(declarations, "; ")),
+		Text:     language.Text,
+		Fields:   embedFields(form, language.Text),
+		Values:   map[string]string{},
+		Action:   fmt.Sprintf("/api/forms/%d/submit%s", form.ID, langQuery(r)),
+		Inactive: !form.Active,
+	}
+	if form.RequireConsent {
+		page.Consent = consentLabel(form, language.Text)
+		page.PrivacyURL = form.PrivacyURL
+	}
+	if a.Cfg.MinSubmitTime > 0 {
+		page.StartToken = a.newFormStartToken(form.ID)
+	}
+	return page
+}
+
+// writeHostedForm renders a hosted form page with the given status code.
+func (a *App) writeHostedForm(w http.ResponseWriter, status int, page hostedFormPage) {
+	var buf strings.Builder
+	if err := hostedFormTemplate.Execute(&buf, page); err != nil {
+		log.Printf("template error (hosted form): %v", err)
+		http.Error(w, "template error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	_, _ = w.Write([]byte(buf.String()))
+}
+
+// statusPageTemplate renders the public status page of a submission. It deliberately
+// shows only the reference, status, and date, never notes, the assignee, or the message.
+var statusPageTemplate = template.Must(template.New("status").Parse(`<!DOCTYPE html>
+<html lang="{{.Lang}}">
+<head>
+  <meta charset="utf-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <meta name="robots" content="noindex">
+  <title>{{.Text.StatusTitle}} - {{.Title}}</title>
+  <link rel="stylesheet" href="/embed/form.css">
+  <style>body { margin: 0; padding: 1rem; }</style>
+</head>
+<body>
+  <div class="{{.Prefix}}embed">
+    <div class="{{.Prefix}}form">
+      <h3>{{.Title}}</h3>
+      <p>{{.Text.Reference}}: <strong>{{.Reference}}</strong></p>
+      <p>{{.Text.StatusLabel}}: <strong>{{.Status}}</strong></p>
+      <p>{{.Text.Submitted}}: {{.CreatedAt.Format "2006-01-02"}}</p>
+    </div>
+  </div>
+</body>
+</html>
+`))
+
+// statusPage is the data for statusPageTemplate.
+type statusPage struct {
+	Prefix    string // Start of the class names, see config.Config.EmbedClassPrefix
+	Lang      string
+	Title     string
+	Text      embedText
+	Reference string
+	Status    string
+	CreatedAt time.Time
+}
+
+// handleStatusPage shows a submitter the status of their submission, looked up by the
+// token returned when it was created. It is in the form's language unless overridden by lang.
+func (a *App) handleStatusPage(w http.ResponseWriter, r *http.Request) {
+	submission, err := a.Store.GetSubmissionByToken(chi.URLParam(r, "token"))
+	if err != nil {
+		if !apperrors.IsNotFound(err) {
+			slog.Error("Status page lookup failed", "error", err)
+		}
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+
+	lang := r.URL.Query().Get("lang")
+	if lang == "" {
+		if form, err := a.Store.GetForm(submission.FormID); err == nil {
+			lang = form.Language
+		}
+	}
+	language := lookupEmbedLanguage(lang)
+
+	// Spam is shown as received, so senders can't tell they were caught
+	status := submission.Status
+	if status == validator.StatusSpam {
+		status = validator.StatusOpen
+	}
+	label, ok := language.Text.Statuses[status]
+	if !ok {
+		label = status
+	}
+
+	page := statusPage{
+		Prefix:    a.Cfg.EmbedClassPrefix,
+		Lang:      language.Code,
+		Title:     fmt.Sprintf("%s - %s", submission.Client, submission.Form),
+		Text:      language.Text,
+		Reference: submission.Reference,
+		Status:    label,
+		CreatedAt: submission.CreatedAt.In(a.location),
+	}
+	var buf strings.Builder
+	if err := statusPageTemplate.Execute(&buf, page); err != nil {
+		log.Printf("template error (status page): %v", err)
+		http.Error(w, "template error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	_, _ = w.Write([]byte(buf.String()))
+}
+
+// langQuery returns "?lang=..." if the request has a lang query parameter, or an empty string.
+func langQuery(r *http.Request) string {
+	lang := r.URL.Query().Get("lang")
+	if lang == "" {
+		return ""
+	}
+	return "?lang=" + url.QueryEscape(lang)
+}
+
+// wantsHTML reports whether a submission is a plain HTML form post rather than a script
+// or API call: it isn't JSON or marked as XMLHttpRequest, and the client accepts HTML.
+// Such submissions are answered with redirects and pages instead of JSON.
+func wantsHTML(r *http.Request) bool {
+	if strings.Contains(r.Header.Get("Content-Type"), "application/json") || r.Header.Get("X-Requested-With") != "" {
+		return false
+	}
+	return strings.Contains(r.Header.Get("Accept"), "text/html")
+}
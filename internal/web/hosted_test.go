@@ -0,0 +1,77 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"ticketd/internal/store"
+	"ticketd/internal/validator"
+)
+
+func TestStatusPage(t *testing.T) {
+	app := newTestApp(t, nil)
+	form := createTestForm(t, app, "example.com", store.FormTypeSupport)
+
+	body := `{"name":"Jane Doe","email":"jane@example.com","subject":"Printer on fire","message":"Secret details"}`
+	rec := serve(t, app, newSubmitRequest(form.ID, "https://example.com", "application/json", strings.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("submit: status = %d, want %d (body %q)", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var response struct {
+		StatusToken string `json:"status_token"`
+		StatusURL   string `json:"status_url"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decode submit response: %v", err)
+	}
+	if len(response.StatusToken) < 20 || !strings.HasSuffix(response.StatusURL, "/status/"+response.StatusToken) {
+		t.Fatalf("status_token = %q, status_url = %q, want a long token and its page", response.StatusToken, response.StatusURL)
+	}
+	submission := lastSubmission(t, app)
+	statuses := lookupEmbedLanguage("en").Text.Statuses
+
+	getStatus := func() *httptest.ResponseRecorder {
+		t.Helper()
+		rec := serve(t, app, httptest.NewRequest(http.MethodGet, "/status/"+response.StatusToken, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status page: status = %d, want %d", rec.Code, http.StatusOK)
+		}
+		return rec
+	}
+	rec = getStatus()
+	page := rec.Body.String()
+	if !strings.Contains(page, submission.Reference) || !strings.Contains(page, statuses[validator.StatusOpen]) {
+		t.Errorf("status page lacks the reference %q or status %q", submission.Reference, statuses[validator.StatusOpen])
+	}
+	for _, private := range []string{"Jane Doe", "jane@example.com", "Secret details", "203.0.113"} {
+		if strings.Contains(page, private) {
+			t.Errorf("status page shows %q", private)
+		}
+	}
+	if got := rec.Header().Get("Cache-Control"); got != "no-store" {
+		t.Errorf("Cache-Control = %q, want no-store", got)
+	}
+
+	if err := app.Store.UpdateSubmissionStatus(submission.ID, validator.StatusClosed, "admin"); err != nil {
+		t.Fatalf("UpdateSubmissionStatus: %v", err)
+	}
+	if page := getStatus().Body.String(); !strings.Contains(page, statuses[validator.StatusClosed]) || strings.Contains(page, statuses[validator.StatusOpen]) {
+		t.Errorf("status page doesn't show the closed status %q instead of %q", statuses[validator.StatusClosed], statuses[validator.StatusOpen])
+	}
+
+	// Spam looks like it was received, so senders can't tell they were caught
+	if err := app.Store.UpdateSubmissionStatus(submission.ID, validator.StatusSpam, "admin"); err != nil {
+		t.Fatalf("UpdateSubmissionStatus: %v", err)
+	}
+	if page := getStatus().Body.String(); !strings.Contains(page, statuses[validator.StatusOpen]) {
+		t.Errorf("status page of spam lacks the open status %q", statuses[validator.StatusOpen])
+	}
+
+	rec = serve(t, app, httptest.NewRequest(http.MethodGet, "/status/"+tamper(response.StatusToken), nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("unknown token: status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
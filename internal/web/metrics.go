@@ -0,0 +1,120 @@
+package web
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"ticketd/internal/store"
+)
+
+// Submission outcomes recorded by the submissions counter.
+const (
+	outcomeAccepted  = "accepted"  // Stored successfully
+	outcomeRejected  = "rejected"  // Invalid request or input
+	outcomeForbidden = "forbidden" // Origin not allowed for the form's client
+	outcomeError     = "error"     // Failed to store a valid submission
+	outcomeSpam      = "spam"      // Matched the spam blocklist (rejected or stored as SPAM) or was sent too fast
+	outcomeDuplicate = "duplicate" // Repeated an identical recent submission, answered as received but not stored
+)
+
+// metrics holds the Prometheus collectors exposed on /metrics.
+// Each App gets its own registry so collectors are never registered twice.
+type metrics struct {
+	registry       *prometheus.Registry
+	submissions    *prometheus.CounterVec
+	submitDuration prometheus.Histogram
+	requests       *prometheus.CounterVec
+}
+
+// newMetrics creates the collectors and registers them, along with gauges that
+// read client and form totals from the store at scrape time.
+func newMetrics(st store.Store) *metrics {
+	m := &metrics{
+		registry: prometheus.NewRegistry(),
+		submissions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "ticketd_submissions_total",
+			Help: "Form submissions received, by form type and outcome.",
+		}, []string{"form_type", "outcome"}),
+		submitDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "ticketd_submit_duration_seconds",
+			Help:    "Latency of the form submission handler.",
+			Buckets: prometheus.DefBuckets,
+		}),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "ticketd_http_requests_total",
+			Help: "HTTP requests handled, by route pattern, method, and status code.",
+		}, []string{"route", "method", "status"}),
+	}
+
+	clients := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "ticketd_clients",
+		Help: "Total number of clients.",
+	}, func() float64 {
+		_, total, err := st.ListClients(0, 1)
+		if err != nil {
+			return 0
+		}
+		return float64(total)
+	})
+	forms := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "ticketd_forms",
+		Help: "Total number of forms across all clients.",
+	}, func() float64 {
+		_, total, err := st.ListFormsByType("", 0, 1)
+		if err != nil {
+			return 0
+		}
+		return float64(total)
+	})
+
+	m.registry.MustRegister(
+		m.submissions,
+		m.submitDuration,
+		m.requests,
+		clients,
+		forms,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return m
+}
+
+// handler returns the /metrics HTTP handler.
+func (m *metrics) handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// observeSubmit records one submission attempt and how long it took.
+func (m *metrics) observeSubmit(formType, outcome string, elapsed time.Duration) {
+	if formType == "" {
+		formType = "unknown"
+	}
+	m.submissions.WithLabelValues(formType, outcome).Inc()
+	m.submitDuration.Observe(elapsed.Seconds())
+}
+
+// instrument is a middleware that counts requests by chi route pattern and status code.
+// Route patterns (not raw paths) keep label cardinality bounded.
+func (m *metrics) instrument(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r)
+
+		route := "unmatched"
+		if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+			route = rctx.RoutePattern()
+		}
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		m.requests.WithLabelValues(route, r.Method, strconv.Itoa(status)).Inc()
+	})
+}
@@ -2,12 +2,149 @@ package web
 
 import (
 	"log/slog"
+	"net"
 	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
 )
 
-// basicAuth is a middleware that protects routes with HTTP Basic Authentication.
-// It checks the provided credentials against the configured admin username and password.
-// Returns 401 Unauthorized if credentials are missing or invalid.
+// forwardedHeaders are the proxy headers only honored from TICKETD_TRUSTED_PROXIES.
+var forwardedHeaders = []string{"X-Forwarded-For", "X-Real-IP", "X-Forwarded-Proto"}
+
+// realIP sets r.RemoteAddr to the client's IP address, without a port.
+// Forwarded headers are only honored when the direct peer is one of TICKETD_TRUSTED_PROXIES;
+// the client is then the last X-Forwarded-For hop that isn't a trusted proxy, or X-Real-IP.
+// From any other peer the headers are removed, so clients can't spoof their address or,
+// through X-Forwarded-Proto, the scheme of public URLs.
+func (a *App) realIP(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		peer, ok := parseRemoteAddr(r.RemoteAddr)
+		if !ok || !a.trustedProxy(peer) {
+			for _, header := range forwardedHeaders {
+				r.Header.Del(header)
+			}
+			if ok {
+				r.RemoteAddr = peer.String()
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		client := peer
+		if forwarded, ok := a.forwardedClient(r); ok {
+			client = forwarded
+		}
+		r.RemoteAddr = client.String()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// forwardedClient returns the client address reported by a trusted proxy. X-Forwarded-For
+// is read from the right, skipping hops that are trusted proxies themselves, since only
+// the entries appended by trusted proxies are reliable.
+func (a *App) forwardedClient(r *http.Request) (netip.Addr, bool) {
+	var hops []string
+	for _, value := range r.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(value, ",")...)
+	}
+	var client netip.Addr
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			break
+		}
+		client = addr.Unmap()
+		if !a.trustedProxy(client) {
+			break
+		}
+	}
+	if client.IsValid() {
+		return client, true
+	}
+
+	if addr, err := netip.ParseAddr(strings.TrimSpace(r.Header.Get("X-Real-IP"))); err == nil {
+		return addr.Unmap(), true
+	}
+	return netip.Addr{}, false
+}
+
+// trustedProxy reports whether addr is in one of the TICKETD_TRUSTED_PROXIES networks.
+func (a *App) trustedProxy(addr netip.Addr) bool {
+	for _, prefix := range a.Cfg.TrustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseRemoteAddr extracts the IP address from a "host:port" RemoteAddr.
+func parseRemoteAddr(remoteAddr string) (netip.Addr, bool) {
+	host := remoteAddr
+	if h, _, err := net.SplitHostPort(remoteAddr); err == nil {
+		host = h
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addr.Unmap(), true
+}
+
+// requireSameOrigin rejects state-changing admin requests that a browser sent from another
+// origin, so a page elsewhere (including a sibling subdomain, which SameSite=Lax cookies
+// don't guard against) can't act with an admin's session.
+//
+// Browsers mark the request's origin in Sec-Fetch-Site; only "same-origin" and "none"
+// (typed or bookmarked by the user) are accepted. Browsers too old to send it are checked
+// by their Origin header, which must match the request host or TICKETD_PUBLIC_BASE_URL.
+// Requests with neither header don't come from a browser and are let through.
+func (a *App) requireSameOrigin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			next.ServeHTTP(w, r)
+			return
+		}
+		if !a.sameOriginRequest(r) {
+			slog.Warn("Rejected cross-origin admin request", "path", r.URL.Path,
+				"origin", r.Header.Get("Origin"), "sec_fetch_site", r.Header.Get("Sec-Fetch-Site"))
+			http.Error(w, "cross-origin request rejected", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// sameOriginRequest reports whether r was sent from the admin UI's own origin, see requireSameOrigin.
+func (a *App) sameOriginRequest(r *http.Request) bool {
+	if site := r.Header.Get("Sec-Fetch-Site"); site != "" {
+		return site == "same-origin" || site == "none"
+	}
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	parsed, err := url.Parse(origin)
+	if err != nil || parsed.Host == "" {
+		return false
+	}
+	if strings.EqualFold(parsed.Host, r.Host) {
+		return true
+	}
+	if a.Cfg.PublicBaseURL != "" {
+		if base, err := url.Parse(a.Cfg.PublicBaseURL); err == nil && strings.EqualFold(parsed.Host, base.Host) {
+			return true
+		}
+	}
+	return false
+}
+
+// requireSession is a middleware that protects admin routes with a signed session cookie.
+// Requests without a valid session are redirected to the login page, which returns
+// them to the originally requested page after a successful login. API requests get
+// a 401 JSON error instead of a redirect.
 //
 // If DisableAuth is set to true in the configuration, authentication is bypassed entirely.
 // This is useful when deploying behind external authentication proxies like oauth2-proxy,
@@ -15,7 +152,7 @@ import (
 //
 // SECURITY WARNING: Only disable authentication when using a trusted external auth proxy.
 // Never expose TicketD directly to the internet with authentication disabled.
-func (a *App) basicAuth(next http.Handler) http.Handler {
+func (a *App) requireSession(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Skip authentication if disabled (for use with external auth proxies)
 		if a.Cfg.DisableAuth {
@@ -24,13 +161,25 @@ func (a *App) basicAuth(next http.Handler) http.Handler {
 			return
 		}
 
-		// Perform standard HTTP Basic Auth
-		user, pass, ok := r.BasicAuth()
-		if !ok || user != a.Cfg.AdminUser || pass != a.Cfg.AdminPass {
-			w.Header().Set("WWW-Authenticate", `Basic realm="TicketD"`)
-			w.WriteHeader(http.StatusUnauthorized)
+		// The user must still exist, so deleted accounts lose access immediately
+		user, ok := a.sessionUser(r)
+		if ok {
+			if _, err := a.Store.GetAdminUserByUsername(user); err != nil {
+				ok = false
+			}
+		}
+		if !ok {
+			if strings.HasPrefix(r.URL.Path, "/api/") {
+				writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "authentication required"})
+				return
+			}
+			target := "/admin/login"
+			if r.Method == http.MethodGet {
+				target += "?next=" + url.QueryEscape(r.URL.RequestURI())
+			}
+			http.Redirect(w, r, target, http.StatusSeeOther)
 			return
 		}
-		next.ServeHTTP(w, r)
+		next.ServeHTTP(w, withUser(r, user))
 	})
 }
@@ -0,0 +1,218 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"ticketd/internal/config"
+)
+
+func TestRealIP(t *testing.T) {
+	app := &App{Cfg: config.Config{TrustedProxies: []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/8"),
+		netip.MustParsePrefix("fd00::/8"),
+	}}}
+
+	tests := []struct {
+		name        string
+		remoteAddr  string
+		xff         []string
+		xRealIP     string
+		want        string
+		wantHeaders bool // Whether the forwarded headers reach the handler
+	}{
+		{
+			name:       "untrusted peer",
+			remoteAddr: "198.51.100.7:51234",
+			xff:        []string{"203.0.113.9"},
+			xRealIP:    "203.0.113.10",
+			want:       "198.51.100.7",
+		},
+		{
+			name:       "untrusted peer claiming a trusted hop",
+			remoteAddr: "198.51.100.7:51234",
+			xff:        []string{"203.0.113.9, 10.0.0.2"},
+			want:       "198.51.100.7",
+		},
+		{
+			name:        "trusted peer without headers",
+			remoteAddr:  "10.0.0.1:51234",
+			want:        "10.0.0.1",
+			wantHeaders: true,
+		},
+		{
+			name:        "trusted peer",
+			remoteAddr:  "10.0.0.1:51234",
+			xff:         []string{"203.0.113.9"},
+			want:        "203.0.113.9",
+			wantHeaders: true,
+		},
+		{
+			name:        "chain through trusted hops",
+			remoteAddr:  "10.0.0.1:51234",
+			xff:         []string{"203.0.113.9, 10.0.0.3, 10.0.0.2"},
+			want:        "203.0.113.9",
+			wantHeaders: true,
+		},
+		{
+			name:        "spoofed entry left of the client",
+			remoteAddr:  "10.0.0.1:51234",
+			xff:         []string{"192.0.2.1, 203.0.113.9, 10.0.0.2"},
+			want:        "203.0.113.9",
+			wantHeaders: true,
+		},
+		{
+			name:        "hops split across header lines",
+			remoteAddr:  "10.0.0.1:51234",
+			xff:         []string{"192.0.2.1, 203.0.113.9", "10.0.0.2"},
+			want:        "203.0.113.9",
+			wantHeaders: true,
+		},
+		{
+			name:        "only trusted hops",
+			remoteAddr:  "10.0.0.1:51234",
+			xff:         []string{"10.0.0.3, 10.0.0.2"},
+			want:        "10.0.0.3",
+			wantHeaders: true,
+		},
+		{
+			name:        "garbage entry left of the client",
+			remoteAddr:  "10.0.0.1:51234",
+			xff:         []string{"garbage, 203.0.113.9"},
+			want:        "203.0.113.9",
+			wantHeaders: true,
+		},
+		{
+			name:        "garbage last entry falls back to X-Real-IP",
+			remoteAddr:  "10.0.0.1:51234",
+			xff:         []string{"203.0.113.9, garbage"},
+			xRealIP:     "203.0.113.10",
+			want:        "203.0.113.10",
+			wantHeaders: true,
+		},
+		{
+			name:        "garbage last entry falls back to the peer",
+			remoteAddr:  "10.0.0.1:51234",
+			xff:         []string{"203.0.113.9, garbage"},
+			want:        "10.0.0.1",
+			wantHeaders: true,
+		},
+		{
+			name:        "garbage X-Real-IP",
+			remoteAddr:  "10.0.0.1:51234",
+			xRealIP:     "203.0.113.10:80",
+			want:        "10.0.0.1",
+			wantHeaders: true,
+		},
+		{
+			name:        "X-Real-IP",
+			remoteAddr:  "10.0.0.1:51234",
+			xRealIP:     " 203.0.113.10 ",
+			want:        "203.0.113.10",
+			wantHeaders: true,
+		},
+		{
+			name:       "untrusted IPv6 peer",
+			remoteAddr: "[2001:db8::1]:443",
+			xff:        []string{"203.0.113.9"},
+			want:       "2001:db8::1",
+		},
+		{
+			name:        "trusted IPv6 peer",
+			remoteAddr:  "[fd00::1]:443",
+			xff:         []string{"2001:db8::5, fd00::2"},
+			want:        "2001:db8::5",
+			wantHeaders: true,
+		},
+		{
+			name:        "IPv4-mapped trusted peer",
+			remoteAddr:  "[::ffff:10.0.0.1]:443",
+			xff:         []string{"::ffff:203.0.113.9, ::ffff:10.0.0.2"},
+			want:        "203.0.113.9",
+			wantHeaders: true,
+		},
+		{
+			name:       "IPv4-mapped untrusted peer",
+			remoteAddr: "[::ffff:198.51.100.7]:443",
+			xRealIP:    "203.0.113.10",
+			want:       "198.51.100.7",
+		},
+		{
+			name:       "unparseable peer",
+			remoteAddr: "@",
+			xff:        []string{"203.0.113.9"},
+			want:       "@",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for _, value := range tt.xff {
+				req.Header.Add("X-Forwarded-For", value)
+			}
+			if tt.xRealIP != "" {
+				req.Header.Set("X-Real-IP", tt.xRealIP)
+			}
+			req.Header.Set("X-Forwarded-Proto", "https")
+
+			var got *http.Request
+			app.realIP(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { got = r })).ServeHTTP(httptest.NewRecorder(), req)
+
+			if got.RemoteAddr != tt.want {
+				t.Errorf("RemoteAddr = %q, want %q", got.RemoteAddr, tt.want)
+			}
+			for _, header := range forwardedHeaders {
+				if present := len(got.Header.Values(header)) > 0; !tt.wantHeaders && present {
+					t.Errorf("%s = %q reached the handler from an untrusted peer", header, got.Header.Values(header))
+				}
+			}
+			if tt.wantHeaders && got.Header.Get("X-Forwarded-Proto") != "https" {
+				t.Errorf("X-Forwarded-Proto from a trusted peer was removed")
+			}
+		})
+	}
+}
+
+func TestRequireSameOrigin(t *testing.T) {
+	app := &App{Cfg: config.Config{PublicBaseURL: "https://tickets.example.com/"}}
+	handler := app.requireSameOrigin(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	tests := []struct {
+		name          string
+		method        string
+		secFetchSite  string
+		origin        string
+		wantForbidden bool
+	}{
+		{name: "same origin", method: http.MethodPost, secFetchSite: "same-origin", origin: "https://evil.example"},
+		{name: "user initiated", method: http.MethodPost, secFetchSite: "none"},
+		{name: "sibling subdomain", method: http.MethodPost, secFetchSite: "same-site", origin: "https://blog.example.com", wantForbidden: true},
+		{name: "cross site", method: http.MethodPost, secFetchSite: "cross-site", wantForbidden: true},
+		{name: "origin matches host", method: http.MethodPost, origin: "http://admin.example.com:8080"},
+		{name: "origin matches public base URL", method: http.MethodPost, origin: "https://tickets.example.com"},
+		{name: "origin from another site", method: http.MethodPost, origin: "https://evil.example", wantForbidden: true},
+		{name: "origin from a sibling subdomain", method: http.MethodPost, origin: "https://blog.example.com", wantForbidden: true},
+		{name: "opaque origin", method: http.MethodPost, origin: "null", wantForbidden: true},
+		{name: "no browser headers", method: http.MethodPost},
+		{name: "cross-site GET", method: http.MethodGet, secFetchSite: "cross-site"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "http://admin.example.com:8080/admin/gdpr/erase", nil)
+			if tt.secFetchSite != "" {
+				req.Header.Set("Sec-Fetch-Site", tt.secFetchSite)
+			}
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if forbidden := rec.Code == http.StatusForbidden; forbidden != tt.wantForbidden {
+				t.Errorf("status = %d, want forbidden %t", rec.Code, tt.wantForbidden)
+			}
+		})
+	}
+}
@@ -1,18 +1,41 @@
 package web
 
-const (
-	pageSize = 20
+import (
+	"net/http"
+	"strconv"
+
+	"ticketd/internal/config"
 )
 
+// pageSize returns the number of items per page for the request: the per_page query
+// parameter capped at config.MaxPageSize, or the configured TICKETD_PAGE_SIZE if it is
+// missing or not a positive number.
+func (a *App) pageSize(r *http.Request) int {
+	perPage, err := strconv.Atoi(r.URL.Query().Get("per_page"))
+	if err != nil || perPage < 1 {
+		return a.Cfg.PageSize
+	}
+	return min(perPage, config.MaxPageSize)
+}
+
+// perPageParam returns size if it differs from the configured page size, or 0 otherwise,
+// so pagination links only carry per_page when the request overrode it.
+func (a *App) perPageParam(size int) int {
+	if size == a.Cfg.PageSize {
+		return 0
+	}
+	return size
+}
+
 // totalPages calculates the total number of pages needed for the given total count.
 // It accounts for partial pages by rounding up.
 // Returns 1 if total is 0 to avoid division by zero.
-func totalPages(total int) int {
+func totalPages(total, size int) int {
 	if total == 0 {
 		return 1
 	}
-	pages := total / pageSize
-	if total%pageSize != 0 {
+	pages := total / size
+	if total%size != 0 {
 		pages++
 	}
 	return pages
@@ -29,8 +52,8 @@ func prevPage(current int) int {
 
 // nextPage returns the next page number, or 0 if there is no next page.
 // Used in templates to determine if a "Next" link should be shown.
-func nextPage(current, total int) int {
-	if current < totalPages(total) {
+func nextPage(current, total, size int) int {
+	if current < totalPages(total, size) {
 		return current + 1
 	}
 	return 0
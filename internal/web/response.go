@@ -11,6 +11,11 @@ import (
 // It executes the template with the "layout" base template and writes the result to the response.
 // Returns a 500 error if the template is not found or fails to execute.
 func (a *App) renderTemplate(w http.ResponseWriter, r *http.Request, page string, data any) {
+	a.renderTemplateStatus(w, r, http.StatusOK, page, data)
+}
+
+// renderTemplateStatus renders a template page like renderTemplate but with a custom status code.
+func (a *App) renderTemplateStatus(w http.ResponseWriter, r *http.Request, status int, page string, data any) {
 	tmpl, ok := a.Templates.pages[page]
 	if !ok {
 		http.Error(w, "template not found", http.StatusInternalServerError)
@@ -23,6 +28,7 @@ func (a *App) renderTemplate(w http.ResponseWriter, r *http.Request, page string
 		return
 	}
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
 	_, _ = w.Write(buf.Bytes())
 }
 
@@ -0,0 +1,201 @@
+package web
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// sessionCookieName is the name of the cookie holding the signed admin session.
+	sessionCookieName = "ticketd_session"
+)
+
+// contextKey is an unexported type for request context keys set by this package.
+type contextKey string
+
+// userContextKey holds the authenticated admin username in the request context.
+const userContextKey contextKey = "user"
+
+// newSessionKey returns the key used to sign session cookies.
+// It uses the configured secret if present, otherwise a random key
+// that is only valid for the lifetime of the process.
+func newSessionKey(secret string) ([]byte, error) {
+	if secret != "" {
+		return []byte(secret), nil
+	}
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// signSession returns the HMAC-SHA256 signature of a session payload.
+func (a *App) signSession(payload string) string {
+	mac := hmac.New(sha256.New, a.sessionKey)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// embedQuery returns the query string ("?exp=...&sig=...") that authorizes loading
+// the embed script for a form until the configured embed token TTL elapses.
+// It returns an empty string when embed signing is disabled.
+func (a *App) embedQuery(formID int64) string {
+	if !a.Cfg.SignEmbeds {
+		return ""
+	}
+	expiry := strconv.FormatInt(time.Now().Add(a.Cfg.EmbedTokenTTL).Unix(), 10)
+	return "?exp=" + expiry + "&sig=" + a.signSession(embedPayload(formID, expiry))
+}
+
+// validEmbedSignature reports whether exp and sig form a valid, unexpired
+// signature for the form's embed script.
+func (a *App) validEmbedSignature(formID int64, exp, sig string) bool {
+	if exp == "" || sig == "" {
+		return false
+	}
+	if !hmac.Equal([]byte(sig), []byte(a.signSession(embedPayload(formID, exp)))) {
+		return false
+	}
+	expiry, err := strconv.ParseInt(exp, 10, 64)
+	return err == nil && time.Now().Unix() < expiry
+}
+
+// embedPayload is the signed message for an embed URL.
+// The "embed:" prefix keeps it distinct from session cookie payloads.
+func embedPayload(formID int64, exp string) string {
+	return "embed:" + strconv.FormatInt(formID, 10) + ":" + exp
+}
+
+// formStartMaxAge bounds how long a form start token stays valid, so one token can't be
+// fetched once and then reused by a bot indefinitely.
+const formStartMaxAge = 24 * time.Hour
+
+// newFormStartToken returns a signed token recording that the form was shown now.
+// It has the form unixMilliseconds.signature, so sub-second thresholds work.
+func (a *App) newFormStartToken(formID int64) string {
+	started := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	return started + "." + a.signSession(formStartPayload(formID, started))
+}
+
+// formStartedAt returns when the form was shown according to a token from newFormStartToken.
+// It returns false if the token is missing, malformed, tampered with, for another form,
+// or older than formStartMaxAge.
+func (a *App) formStartedAt(formID int64, token string) (time.Time, bool) {
+	started, sig, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(sig), []byte(a.signSession(formStartPayload(formID, started)))) {
+		return time.Time{}, false
+	}
+	millis, err := strconv.ParseInt(started, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	at := time.UnixMilli(millis)
+	if time.Since(at) > formStartMaxAge {
+		return time.Time{}, false
+	}
+	return at, true
+}
+
+// formStartPayload is the signed message for a form start token.
+// The "start:" prefix keeps it distinct from embed URL and session cookie payloads.
+func formStartPayload(formID int64, started string) string {
+	return "start:" + strconv.FormatInt(formID, 10) + ":" + started
+}
+
+// newSessionCookie creates a signed session cookie for the given username.
+// The cookie value has the form base64(username).expiry.signature.
+func (a *App) newSessionCookie(r *http.Request, username string) *http.Cookie {
+	expires := time.Now().Add(a.Cfg.SessionTTL)
+	payload := base64.RawURLEncoding.EncodeToString([]byte(username)) + "." + strconv.FormatInt(expires.Unix(), 10)
+	return &http.Cookie{
+		Name:     sessionCookieName,
+		Value:    payload + "." + a.signSession(payload),
+		Path:     "/",
+		Expires:  expires,
+		MaxAge:   int(a.Cfg.SessionTTL.Seconds()),
+		HttpOnly: true,
+		Secure:   strings.HasPrefix(a.publicBaseURL(r), "https://"),
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+// clearSessionCookie returns a cookie that removes the session from the browser.
+func clearSessionCookie() *http.Cookie {
+	return &http.Cookie{
+		Name:     sessionCookieName,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+// sessionUser validates the session cookie on the request and returns the username.
+// Returns false if the cookie is missing, malformed, tampered with, or expired.
+func (a *App) sessionUser(r *http.Request) (string, bool) {
+	cookie, err := r.Cookie(sessionCookieName)
+	if err != nil {
+		return "", false
+	}
+	idx := strings.LastIndex(cookie.Value, ".")
+	if idx < 0 {
+		return "", false
+	}
+	payload, signature := cookie.Value[:idx], cookie.Value[idx+1:]
+	if !hmac.Equal([]byte(signature), []byte(a.signSession(payload))) {
+		return "", false
+	}
+
+	encodedUser, expiryValue, ok := strings.Cut(payload, ".")
+	if !ok {
+		return "", false
+	}
+	expiry, err := strconv.ParseInt(expiryValue, 10, 64)
+	if err != nil || time.Now().Unix() >= expiry {
+		return "", false
+	}
+	username, err := base64.RawURLEncoding.DecodeString(encodedUser)
+	if err != nil || len(username) == 0 {
+		return "", false
+	}
+	return string(username), true
+}
+
+// currentUser returns the authenticated admin username for the request,
+// or an empty string if there is none (e.g. when authentication is disabled).
+func currentUser(r *http.Request) string {
+	user, _ := r.Context().Value(userContextKey).(string)
+	return user
+}
+
+// currentActor returns the name to attribute admin actions to: the signed-in user,
+// or "admin" when authentication is handled by an external proxy.
+func currentActor(r *http.Request) string {
+	if user := currentUser(r); user != "" {
+		return user
+	}
+	return "admin"
+}
+
+// withUser returns a copy of the request carrying the authenticated username.
+func withUser(r *http.Request, username string) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), userContextKey, username))
+}
+
+// safeRedirectTarget returns next if it is a local absolute path, otherwise the fallback.
+// This prevents the login form from being abused as an open redirect.
+func safeRedirectTarget(next, fallback string) string {
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") || strings.HasPrefix(next, "/\\") {
+		return fallback
+	}
+	return next
+}
@@ -0,0 +1,118 @@
+package web
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"ticketd/internal/config"
+)
+
+// signedEmbedParams returns the exp and sig parameters of a fresh embed URL for formID.
+func signedEmbedParams(t *testing.T, app *App, formID int64) (exp, sig string) {
+	t.Helper()
+	query, err := url.ParseQuery(strings.TrimPrefix(app.embedQuery(formID), "?"))
+	if err != nil {
+		t.Fatalf("parse embed query: %v", err)
+	}
+	return query.Get("exp"), query.Get("sig")
+}
+
+// tamper returns s with its first character changed.
+func tamper(s string) string {
+	if strings.HasPrefix(s, "A") {
+		return "B" + s[1:]
+	}
+	return "A" + s[1:]
+}
+
+func TestValidEmbedSignature(t *testing.T) {
+	app := newTestApp(t, func(cfg *config.Config) {
+		cfg.SessionSecret = strings.Repeat("s", 32)
+		cfg.SignEmbeds = true
+		cfg.EmbedTokenTTL = time.Hour
+	})
+	exp, sig := signedEmbedParams(t, app, 1)
+	past := strconv.FormatInt(time.Now().Add(-time.Minute).Unix(), 10)
+	later := strconv.FormatInt(time.Now().Add(2*time.Hour).Unix(), 10)
+
+	tests := []struct {
+		name   string
+		formID int64
+		exp    string
+		sig    string
+		want   bool
+	}{
+		{name: "valid", formID: 1, exp: exp, sig: sig, want: true},
+		{name: "tampered signature", formID: 1, exp: exp, sig: tamper(sig), want: false},
+		{name: "tampered expiry", formID: 1, exp: later, sig: sig, want: false},
+		{name: "past expiry", formID: 1, exp: past, sig: app.signSession(embedPayload(1, past)), want: false},
+		{name: "signature for another form", formID: 2, exp: exp, sig: sig, want: false},
+		{name: "missing expiry", formID: 1, sig: sig, want: false},
+		{name: "missing signature", formID: 1, exp: exp, want: false},
+		{name: "non-numeric expiry", formID: 1, exp: "soon", sig: app.signSession(embedPayload(1, "soon")), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := app.validEmbedSignature(tt.formID, tt.exp, tt.sig); got != tt.want {
+				t.Errorf("validEmbedSignature(%d, %q, %q) = %t, want %t", tt.formID, tt.exp, tt.sig, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEmbedQueryDisabled(t *testing.T) {
+	app := newTestApp(t, nil)
+	if query := app.embedQuery(1); query != "" {
+		t.Errorf("embedQuery with signing disabled = %q, want empty", query)
+	}
+}
+
+// formStartTokenAt returns a form start token for formID claiming the form was shown at at.
+func formStartTokenAt(app *App, formID int64, at time.Time) string {
+	started := strconv.FormatInt(at.UnixMilli(), 10)
+	return started + "." + app.signSession(formStartPayload(formID, started))
+}
+
+func TestFormStartToken(t *testing.T) {
+	app := newTestApp(t, func(cfg *config.Config) { cfg.SessionSecret = strings.Repeat("s", 32) })
+
+	before := time.Now()
+	token := app.newFormStartToken(1)
+	at, ok := app.formStartedAt(1, token)
+	if !ok {
+		t.Fatalf("formStartedAt(%q) rejected a fresh token", token)
+	}
+	// Millisecond precision, so sub-second thresholds can be told apart
+	if at.Before(before.Truncate(time.Millisecond)) || at.After(time.Now()) {
+		t.Errorf("formStartedAt = %v, want between %v and now", at, before)
+	}
+
+	started := time.Now().Add(-1500 * time.Millisecond)
+	if at, ok := app.formStartedAt(1, formStartTokenAt(app, 1, started)); !ok || !at.Equal(started.Truncate(time.Millisecond)) {
+		t.Errorf("formStartedAt = %v, %t, want %v", at, ok, started.Truncate(time.Millisecond))
+	}
+
+	seconds := strconv.FormatInt(time.Now().Unix(), 10)
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "empty", token: ""},
+		{name: "no signature", token: strings.Split(token, ".")[0]},
+		{name: "tampered signature", token: strings.Split(token, ".")[0] + "." + tamper(strings.Split(token, ".")[1])},
+		{name: "other form", token: app.newFormStartToken(2)},
+		{name: "too old", token: formStartTokenAt(app, 1, time.Now().Add(-formStartMaxAge-time.Minute))},
+		{name: "not a number", token: "soon." + app.signSession(formStartPayload(1, "soon"))},
+		{name: "seconds instead of milliseconds", token: seconds + "." + app.signSession(formStartPayload(1, seconds))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if at, ok := app.formStartedAt(1, tt.token); ok {
+				t.Errorf("formStartedAt(%q) = %v, want rejected", tt.token, at)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package web
+
+import (
+	"bufio"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// spamBlocklist matches submission text against phrases loaded from a file.
+// The file is re-read whenever its modification time changes, so edits take
+// effect without a restart.
+type spamBlocklist struct {
+	path string
+
+	mu      sync.Mutex
+	modTime time.Time
+	phrases []string
+}
+
+// newSpamBlocklist returns a blocklist backed by path, or nil if path is empty.
+func newSpamBlocklist(path string) *spamBlocklist {
+	if path == "" {
+		return nil
+	}
+	return &spamBlocklist{path: path}
+}
+
+// match returns the first blocked phrase contained in any of texts (case-insensitive),
+// or an empty string if none match. A nil blocklist never matches.
+func (b *spamBlocklist) match(texts ...string) string {
+	if b == nil {
+		return ""
+	}
+	phrases := b.current()
+	for _, text := range texts {
+		text = strings.ToLower(text)
+		for _, phrase := range phrases {
+			if strings.Contains(text, phrase) {
+				return phrase
+			}
+		}
+	}
+	return ""
+}
+
+// current returns the phrase list, reloading it if the file changed.
+// If the file cannot be read the previously loaded phrases are kept.
+func (b *spamBlocklist) current() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	info, err := os.Stat(b.path)
+	if err != nil {
+		slog.Warn("Failed to stat spam blocklist, keeping previous list", "path", b.path, "error", err)
+		return b.phrases
+	}
+	if info.ModTime().Equal(b.modTime) {
+		return b.phrases
+	}
+
+	phrases, err := readBlocklist(b.path)
+	if err != nil {
+		slog.Warn("Failed to read spam blocklist, keeping previous list", "path", b.path, "error", err)
+		return b.phrases
+	}
+	b.phrases = phrases
+	b.modTime = info.ModTime()
+	slog.Info("Loaded spam blocklist", "path", b.path, "phrases", len(phrases))
+	return b.phrases
+}
+
+// readBlocklist reads one lower-cased phrase per line, skipping blank lines and # comments.
+func readBlocklist(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var phrases []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		phrases = append(phrases, strings.ToLower(line))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return phrases, nil
+}
@@ -0,0 +1,122 @@
+package web
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"ticketd/internal/config"
+	"ticketd/internal/store"
+	"ticketd/internal/validator"
+)
+
+// writeTestFile writes content to a file in a temporary directory and returns its path.
+func writeTestFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+	return path
+}
+
+func TestSpamBlocklistMatch(t *testing.T) {
+	blocklist := newSpamBlocklist(writeTestFile(t, "spam.txt", "# casino spam\nOnline Casino\n\n  cheap seo  \n"))
+
+	tests := []struct {
+		name  string
+		texts []string
+		want  string
+	}{
+		{name: "exact phrase", texts: []string{"online casino"}, want: "online casino"},
+		{name: "different case", texts: []string{"Best ONLINE CASINO bonus"}, want: "online casino"},
+		{name: "trimmed phrase", texts: []string{"Buy cheap SEO today"}, want: "cheap seo"},
+		{name: "in a later text", texts: []string{"Hello", "visit our online casino"}, want: "online casino"},
+		{name: "no match", texts: []string{"My printer is broken", "It won't turn on"}},
+		{name: "comment lines are not phrases", texts: []string{"casino spam"}},
+		{name: "partial phrase", texts: []string{"online casin"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := blocklist.match(tt.texts...); got != tt.want {
+				t.Errorf("match(%q) = %q, want %q", tt.texts, got, tt.want)
+			}
+		})
+	}
+
+	var disabled *spamBlocklist
+	if got := disabled.match("online casino"); got != "" {
+		t.Errorf("nil blocklist matched %q", got)
+	}
+}
+
+func TestSpamBlocklistReload(t *testing.T) {
+	path := writeTestFile(t, "spam.txt", "online casino\n")
+	blocklist := newSpamBlocklist(path)
+	if got := blocklist.match("cheap seo"); got != "" {
+		t.Fatalf("match before reload = %q, want no match", got)
+	}
+
+	if err := os.WriteFile(path, []byte("cheap seo\n"), 0o600); err != nil {
+		t.Fatalf("rewrite blocklist: %v", err)
+	}
+	// Make sure the modification time changes even on coarse-grained file systems
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatalf("Chtimes: %v", err)
+	}
+	if got := blocklist.match("cheap seo"); got != "cheap seo" {
+		t.Errorf("match after reload = %q, want %q", got, "cheap seo")
+	}
+	if got := blocklist.match("online casino"); got != "" {
+		t.Errorf("removed phrase still matched: %q", got)
+	}
+
+	// A file that disappears keeps the last loaded phrases
+	if err := os.Remove(path); err != nil {
+		t.Fatalf("remove blocklist: %v", err)
+	}
+	if got := blocklist.match("cheap seo"); got != "cheap seo" {
+		t.Errorf("match after removal = %q, want %q", got, "cheap seo")
+	}
+}
+
+func TestSubmitSpam(t *testing.T) {
+	path := writeTestFile(t, "spam.txt", "online casino\n")
+	spam := `{"name":"Jane Doe","email":"jane@example.com","subject":"Hello","message":"Try our Online Casino!"}`
+
+	t.Run("reject", func(t *testing.T) {
+		app := newTestApp(t, func(cfg *config.Config) { cfg.SpamBlocklistPath = path })
+		form := createTestForm(t, app, "example.com", store.FormTypeSupport)
+
+		rec := serve(t, app, newSubmitRequest(form.ID, "https://example.com", "application/json", strings.NewReader(spam)))
+		assertJSONError(t, rec, http.StatusBadRequest, "submission rejected")
+		if _, total, _ := app.Store.ListSubmissions(0, 10); total != 0 {
+			t.Errorf("%d submissions stored, want 0", total)
+		}
+
+		rec = serve(t, app, newSubmitRequest(form.ID, "https://example.com", "application/json", strings.NewReader(jsonSubmission)))
+		if rec.Code != http.StatusOK {
+			t.Errorf("clean submission: status = %d, want %d (body %q)", rec.Code, http.StatusOK, rec.Body.String())
+		}
+	})
+
+	t.Run("flag", func(t *testing.T) {
+		app := newTestApp(t, func(cfg *config.Config) {
+			cfg.SpamBlocklistPath = path
+			cfg.SpamAction = config.SpamActionFlag
+		})
+		form := createTestForm(t, app, "example.com", store.FormTypeSupport)
+
+		rec := serve(t, app, newSubmitRequest(form.ID, "https://example.com", "application/json", strings.NewReader(spam)))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want %d (body %q)", rec.Code, http.StatusOK, rec.Body.String())
+		}
+		if status := lastSubmission(t, app).Status; status != validator.StatusSpam {
+			t.Errorf("status = %q, want %q", status, validator.StatusSpam)
+		}
+	})
+}
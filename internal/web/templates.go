@@ -19,7 +19,10 @@ type templateCache struct {
 	pages map[string]*template.Template
 }
 
-func parseTemplates() (*templateCache, error) {
+// parseTemplates parses every page template together with the shared layout.
+// Extra template functions (e.g. ones that depend on configuration) are merged
+// into the default function map.
+func parseTemplates(extra template.FuncMap) (*templateCache, error) {
 	funcs := template.FuncMap{
 		"formatTime": func(t time.Time) string {
 			if t.IsZero() {
@@ -28,6 +31,9 @@ func parseTemplates() (*templateCache, error) {
 			return t.Format("2006-01-02 15:04")
 		},
 	}
+	for name, fn := range extra {
+		funcs[name] = fn
+	}
 
 	files, err := templateFS.ReadDir("templates")
 	if err != nil {
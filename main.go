@@ -1,13 +1,21 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
+	_ "time/tzdata" // Embed the timezone database so TICKETD_TIMEZONE works in minimal images
 
 	"github.com/joho/godotenv"
+	"golang.org/x/crypto/bcrypt"
 
 	"ticketd/internal/config"
+	"ticketd/internal/store"
 	"ticketd/internal/store/sqlite"
 	"ticketd/internal/web"
 )
@@ -38,8 +46,13 @@ func main() {
 	}
 	slog.Info("Configuration loaded successfully", "config", cfg.String())
 
-	// Initialize database
-	store, err := sqlite.New(cfg.DBPath)
+	// Initialize database, retrying while it isn't reachable yet
+	var store *sqlite.Store
+	err := retryWithBackoff("open database", cfg.DBConnectAttempts, cfg.DBConnectDelay, func() error {
+		var err error
+		store, err = sqlite.New(cfg.DBPath, cfg.DBBusyTimeout)
+		return err
+	})
 	if err != nil {
 		slog.Error("Failed to initialize database", "error", err, "db_path", cfg.DBPath)
 		os.Exit(1)
@@ -47,8 +60,16 @@ func main() {
 	defer func() {
 		if err := store.Close(); err != nil {
 			slog.Error("Failed to close database", "error", err)
+			return
 		}
+		slog.Info("Database closed")
 	}()
+	store.SetPageSize(cfg.PageSize)
+	store.SetPhoneRegion(cfg.PhoneRegion)
+	store.SetAnonymizeIP(cfg.AnonymizeIP)
+	store.SetSubmissionLimits(cfg.SubmissionLimits)
+	store.SetFormCreateLimit(cfg.FormCreateLimit, cfg.FormCreateWindow)
+	store.SetReferenceFormat(cfg.ReferencePrefix, cfg.ReferenceDigits)
 	slog.Info("Database initialized", "db_path", cfg.DBPath)
 
 	// Run database migrations
@@ -58,6 +79,14 @@ func main() {
 	}
 	slog.Info("Database migrations completed")
 
+	// Seed the first admin user from the environment credentials
+	if !cfg.DisableAuth {
+		if err := seedAdminUser(store, cfg); err != nil {
+			slog.Error("Failed to seed admin user", "error", err)
+			os.Exit(1)
+		}
+	}
+
 	// Initialize web application
 	app, err := web.NewApp(cfg, store)
 	if err != nil {
@@ -65,11 +94,124 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Stop on SIGINT/SIGTERM so in-flight requests can finish before the store is closed
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Delete submissions past the retention period in the background
+	if cfg.RetentionDays > 0 {
+		go runRetention(ctx, store, cfg.RetentionDays, cfg.RetentionInterval)
+		slog.Info("Submission retention enabled", "days", cfg.RetentionDays, "interval", cfg.RetentionInterval.String())
+	}
+
 	// Start HTTP server
 	addr := ":" + cfg.Port
-	slog.Info("Starting HTTP server", "address", addr)
-	if err := http.ListenAndServe(addr, app.Router()); err != nil {
-		slog.Error("HTTP server failed", "error", err, "address", addr)
-		os.Exit(1)
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           app.Router(),
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		ReadTimeout:       cfg.ReadTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+	}
+	server.RegisterOnShutdown(app.CloseStreams)
+	serverErr := make(chan error, 1)
+	go func() {
+		if cfg.TLSEnabled() {
+			slog.Info("Starting HTTPS server", "address", addr, "cert", cfg.TLSCert)
+			serverErr <- server.ListenAndServeTLS(cfg.TLSCert, cfg.TLSKey)
+			return
+		}
+		slog.Info("Starting HTTP server", "address", addr)
+		serverErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serverErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("HTTP server failed", "error", err, "address", addr)
+			os.Exit(1)
+		}
+	case <-ctx.Done():
+		// Restore default signal handling so a second signal kills the process
+		stop()
+		slog.Info("Shutdown signal received, draining connections", "timeout", cfg.ShutdownTimeout.String())
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			slog.Error("HTTP server did not shut down cleanly", "error", err)
+		} else {
+			slog.Info("HTTP server stopped")
+		}
+	}
+}
+
+// retryWithBackoff calls fn up to attempts times until it succeeds, waiting delay after
+// the first failure and doubling the wait after each further one. Every failed attempt
+// is logged. Returns the last error if all attempts fail.
+func retryWithBackoff(action string, attempts int, delay time.Duration, fn func() error) error {
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if err = fn(); err == nil {
+			return nil
+		}
+		if attempt == attempts {
+			break
+		}
+		slog.Warn("Startup step failed, retrying", "action", action, "attempt", attempt, "max_attempts", attempts, "retry_in", delay.String(), "error", err)
+		time.Sleep(delay)
+		delay *= 2
+	}
+	return err
+}
+
+// seedAdminUser creates the initial admin user from TICKETD_ADMIN_USER and
+// TICKETD_ADMIN_PASS (or TICKETD_ADMIN_PASS_HASH) when no admin users exist yet.
+// Once the table has users, the environment credentials are no longer consulted.
+func seedAdminUser(st store.Store, cfg config.Config) error {
+	count, err := st.CountAdminUsers()
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	hash := cfg.AdminPassHash
+	if hash == "" {
+		generated, err := bcrypt.GenerateFromPassword([]byte(cfg.AdminPass), bcrypt.DefaultCost)
+		if err != nil {
+			return err
+		}
+		hash = string(generated)
+	}
+	if _, err := st.CreateAdminUser(cfg.AdminUser, hash); err != nil {
+		return err
+	}
+	slog.Info("Created initial admin user from environment", "username", cfg.AdminUser)
+	return nil
+}
+
+// runRetention deletes submissions older than days once right away and then every interval,
+// until ctx is cancelled.
+func runRetention(ctx context.Context, st store.Store, days int, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		cutoff := time.Now().AddDate(0, 0, -days)
+		deleted, err := st.DeleteSubmissionsOlderThan(cutoff)
+		if err != nil {
+			slog.Error("Failed to delete expired submissions", "error", err)
+		} else {
+			slog.Info("Deleted expired submissions", "count", deleted, "cutoff", cutoff.UTC().Format(time.RFC3339))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
 	}
 }